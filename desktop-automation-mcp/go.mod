@@ -3,8 +3,9 @@ module github.com/pgbytes/desktop-automation-mcp
 go 1.24
 
 require (
-	github.com/go-vgo/robotgo v0.110.8
 	github.com/mark3labs/mcp-go v0.32.0
+	github.com/pgbytes/gophercon25/desktop-automation v0.0.0
+	github.com/spf13/pflag v1.0.5
 )
 
 require (
@@ -12,6 +13,7 @@ require (
 	github.com/ebitengine/purego v0.8.3 // indirect
 	github.com/gen2brain/shm v0.1.1 // indirect
 	github.com/go-ole/go-ole v1.3.0 // indirect
+	github.com/go-vgo/robotgo v0.110.8 // indirect
 	github.com/godbus/dbus/v5 v5.1.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/jezek/xgb v1.1.1 // indirect
@@ -37,4 +39,7 @@ require (
 	golang.org/x/image v0.27.0 // indirect
 	golang.org/x/net v0.41.0 // indirect
 	golang.org/x/sys v0.33.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/pgbytes/gophercon25/desktop-automation => ../desktop-automation
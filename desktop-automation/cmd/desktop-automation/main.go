@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/pgbytes/gophercon25/desktop-automation/internal/commands"
 )
@@ -19,8 +22,14 @@ func main() {
 	rootCmd.Version = version
 	rootCmd.SetVersionTemplate("desktop-automation {{.Version}}\n")
 
+	// Cancel the command on Ctrl+C or SIGTERM, so that it can release held
+	// keys and close its logs; a second signal terminates the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+
 	// Execute the root command and handle errors gracefully
-	if err := rootCmd.Execute(); err != nil {
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -1,3 +0,0 @@
-// Package automation provides wrappers for the robotgo library
-// to simplify desktop automation tasks
-package automation
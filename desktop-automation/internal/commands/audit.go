@@ -0,0 +1,254 @@
+// Package commands implements the CLI commands for desktop automation
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/audit"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/script"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// auditFlags are the root flags that configure the audit log
+type auditFlags struct {
+	audit.Flags
+}
+
+// auditKey is the context key of the commandAudit of a running command
+type auditKey struct{}
+
+// commandAudit is the audit log of a running command and who runs it
+type commandAudit struct {
+	log     *audit.Logger
+	session string
+	client  string
+}
+
+// register adds the audit flags to the root command and makes every command
+// below it record itself
+func (f *auditFlags) register(rootCmd *cobra.Command) {
+	f.Register(rootCmd.PersistentFlags())
+	f.wrap(rootCmd)
+}
+
+// wrap makes cmd and its subcommands record themselves when --audit-log is
+// set. Like the result of an MCP tool, the result of a command is what it
+// printed, or its error. The log is available to the command through
+// auditLogger.
+func (f *auditFlags) wrap(cmd *cobra.Command) {
+	for _, sub := range cmd.Commands() {
+		f.wrap(sub)
+	}
+
+	run := cmd.RunE
+	if run == nil {
+		return
+	}
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if !f.Enabled() {
+			return run(cmd, args)
+		}
+
+		log, err := f.Open()
+		if err != nil {
+			return err
+		}
+		ca := &commandAudit{log: log, session: audit.NewID(), client: currentUser()}
+		cmd.SetContext(context.WithValue(cmd.Context(), auditKey{}, ca))
+
+		var out bytes.Buffer
+		cmd.SetOut(io.MultiWriter(cmd.OutOrStdout(), &out))
+
+		start := time.Now()
+		runErr := run(cmd, args)
+		entry := audit.Entry{
+			Time:      start,
+			Source:    audit.SourceCLI,
+			Session:   ca.session,
+			Client:    ca.client,
+			Action:    commandAction(cmd),
+			Arguments: commandArguments(cmd, args),
+			OK:        runErr == nil,
+			Result:    strings.TrimSpace(out.String()),
+			Duration:  audit.Duration(time.Since(start)),
+		}
+		if runErr != nil {
+			entry.Result = runErr.Error()
+		}
+		log.Log(entry)
+
+		return errors.Join(runErr, log.Close())
+	}
+}
+
+// auditLogger returns the audit log of the running command, or nil
+func auditLogger(ctx context.Context) *audit.Logger {
+	if ca, ok := ctx.Value(auditKey{}).(*commandAudit); ok {
+		return ca.log
+	}
+	return nil
+}
+
+// auditStep records a script step that the running command ran, as an
+// action like "run type" in the session of the command
+func auditStep(cmd *cobra.Command, result script.StepResult) {
+	ca, ok := cmd.Context().Value(auditKey{}).(*commandAudit)
+	if !ok {
+		return
+	}
+
+	entry := audit.Entry{
+		Time:      time.Now().Add(-result.Elapsed),
+		Source:    audit.SourceCLI,
+		Session:   ca.session,
+		Client:    ca.client,
+		Action:    commandAction(cmd) + " " + string(result.Step.Action),
+		Arguments: stepArguments(result),
+		OK:        result.Err == nil,
+		Result:    result.Detail,
+		Duration:  audit.Duration(result.Elapsed),
+	}
+	if result.Err != nil {
+		entry.Result = result.Err.Error()
+	}
+	ca.log.Log(entry)
+}
+
+// commandAction returns the path of cmd below the root, e.g. "windows move"
+func commandAction(cmd *cobra.Command) string {
+	return strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+}
+
+// stepArguments returns the fields of a step that are set, named as in
+// script files, with its 1-based position as "step"
+func stepArguments(result script.StepResult) map[string]any {
+	arguments := make(map[string]any)
+	if data, err := yaml.Marshal(result.Step); err == nil {
+		yaml.Unmarshal(data, &arguments)
+	}
+	delete(arguments, "action")
+	arguments["step"] = result.Index + 1
+	return arguments
+}
+
+// currentUser returns the name of the user running the CLI
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// commandArguments names the positional arguments of cmd after its usage
+// line, e.g. x and y for "click x y", and adds the flags that were set.
+// Arguments beyond the named ones are collected under the last name. The
+// script variables of --var are logged by name, so that the log redacts
+// their values like typed text.
+func commandArguments(cmd *cobra.Command, args []string) map[string]any {
+	arguments := make(map[string]any)
+
+	var names []string
+	for _, field := range strings.Fields(cmd.Use)[1:] {
+		name := strings.Trim(field, `[]"`)
+		name = strings.TrimSuffix(name, "...")
+		if len(names) == 0 || names[len(names)-1] != name {
+			names = append(names, name)
+		}
+	}
+	for i, arg := range args {
+		if len(names) == 0 {
+			arguments["args"] = args
+			break
+		}
+		name := names[min(i, len(names)-1)]
+		switch v := arguments[name].(type) {
+		case nil:
+			arguments[name] = arg
+		case string:
+			arguments[name] = []string{v, arg}
+		case []string:
+			arguments[name] = append(v, arg)
+		}
+	}
+
+	// The root flags, like the audit flags, describe no action
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if cmd.Root().PersistentFlags().Lookup(flag.Name) != nil {
+			return
+		}
+		slice, ok := flag.Value.(pflag.SliceValue)
+		switch {
+		case !ok:
+			arguments[flag.Name] = flag.Value.String()
+		case flag.Name == "var":
+			if vars, err := parseVars(slice.GetSlice()); err == nil {
+				arguments[flag.Name] = vars
+			} else {
+				arguments[flag.Name] = slice.GetSlice()
+			}
+		default:
+			arguments[flag.Name] = slice.GetSlice()
+		}
+	})
+
+	if len(arguments) == 0 {
+		return nil
+	}
+	return arguments
+}
+
+// newAuditCmd creates the audit command and its subcommands
+func newAuditCmd() *cobra.Command {
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Inspect audit logs",
+		Long:  `Inspect the audit logs written with --audit-log.`,
+	}
+
+	auditCmd.AddCommand(&cobra.Command{
+		Use:   "verify log [log...]",
+		Short: "Check the hash chain of audit logs",
+		Long: `Check that no entry of an audit log written with --audit-hash-chain was
+modified, removed or inserted. Given a single log, its rotated files are checked
+first, oldest first. Given several, they are checked in the order given.
+
+The chain only protects the entries between its first and its last line. Entries
+removed from the start of the oldest file or the end of the newest one leave a
+valid, shorter chain. The hashes are not keyed either, so anyone who can write
+the log can replace its entries and compute a new, valid chain. Keep the hash of
+the last line somewhere the log's writers cannot change to detect that.`,
+		Example: `  # Check a log and its rotated files
+  desktop-automation audit verify audit.jsonl`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths := args
+			if len(args) == 1 {
+				backups, err := audit.Backups(args[0])
+				if err != nil {
+					return err
+				}
+				paths = append(backups, args[0])
+			}
+
+			n, err := audit.Verify(paths...)
+			if err != nil {
+				return fmt.Errorf("audit log verification failed after %d entries: %w", n, err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Verified %d entries in %d files\n", n, len(paths))
+			return nil
+		},
+	})
+
+	return auditCmd
+}
@@ -5,18 +5,31 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/pgbytes/gophercon25/desktop-automation/internal/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
 	"github.com/spf13/cobra"
 )
 
 // newClickCmd creates the click command
-func newClickCmd() *cobra.Command {
+func newClickCmd(backend automation.Backend) *cobra.Command {
+	var buttonName string
+	var double bool
+	var frame frameFlags
+
 	clickCmd := &cobra.Command{
 		Use:   "click x y",
 		Short: "Click at a specific screen coordinate",
 		Long:  `Click mouse at the specified X and Y coordinates on your screen.`,
 		Example: `  # Click at position (500, 300)
-  desktop-automation click 500 300`,
+  desktop-automation click 500 300
+
+  # Open a context menu
+  desktop-automation click --button right 500 300
+
+  # Double click a file to open it
+  desktop-automation click --double 500 300
+
+  # Click 40 pixels right of and 60 below the top left corner of the editor window
+  desktop-automation click --window-title 'Editor$' 40 60`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Parse and validate x coordinate
@@ -24,33 +37,53 @@ func newClickCmd() *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("invalid x coordinate: %s (must be an integer)", args[0])
 			}
-			if x < 0 {
-				return fmt.Errorf("x coordinate must be non-negative, got: %d", x)
-			}
 
 			// Parse and validate y coordinate
 			y, err := strconv.Atoi(args[1])
 			if err != nil {
 				return fmt.Errorf("invalid y coordinate: %s (must be an integer)", args[1])
 			}
-			if y < 0 {
-				return fmt.Errorf("y coordinate must be non-negative, got: %d", y)
+
+			// Parse and validate the button
+			button, err := automation.ParseButton(buttonName)
+			if err != nil {
+				return err
+			}
+
+			count := 1
+			if double {
+				count = 2
+			}
+
+			f, err := frame.frame()
+			if err != nil {
+				return err
 			}
 
 			// Get current mouse position before clicking
-			mouse := automation.NewMouse()
+			mouse := automation.NewMouseWithBackend(backend)
 			currentX, currentY := mouse.GetPosition()
-			fmt.Printf("Current mouse position: (%d, %d)\n", currentX, currentY)
+			fmt.Fprintf(cmd.OutOrStdout(), "Current mouse position: (%d, %d)\n", currentX, currentY)
+
+			// Resolve the target in screen coordinates
+			sx, sy, err := mouse.Resolve(f, x, y)
+			if err != nil {
+				return err
+			}
 
 			// Perform the click
-			if err := mouse.Click(x, y); err != nil {
+			if err := mouse.ClickButton(sx, sy, button, count); err != nil {
 				return fmt.Errorf("failed to click: %w", err)
 			}
 
-			fmt.Printf("Successfully clicked at coordinates (%d, %d)\n", x, y)
+			fmt.Fprintf(cmd.OutOrStdout(), "Successfully clicked at coordinates %s\n", f.FormatPoint(x, y, sx, sy))
 			return nil
 		},
 	}
 
+	clickCmd.Flags().StringVar(&buttonName, "button", "left", "Mouse button to click (left, right or middle)")
+	clickCmd.Flags().BoolVar(&double, "double", false, "Double click instead of a single click")
+	frame.register(clickCmd)
+
 	return clickCmd
 }
@@ -2,11 +2,17 @@
 package commands
 
 import (
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
 	"github.com/spf13/cobra"
 )
 
 // NewRootCmd creates the root command for the desktop-automation CLI
 func NewRootCmd() *cobra.Command {
+	return NewRootCmdWithBackend(automation.NewRobotgoBackend())
+}
+
+// NewRootCmdWithBackend creates the root command using the given input backend
+func NewRootCmdWithBackend(backend automation.Backend) *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:   "desktop-automation",
 		Short: "Beautiful Desktop Automation CLI",
@@ -14,9 +20,26 @@ func NewRootCmd() *cobra.Command {
 	}
 
 	// Add subcommands
-	rootCmd.AddCommand(newClickCmd())
-	rootCmd.AddCommand(newTypeCmd())
-	rootCmd.AddCommand(newMoveCmd())
+	rootCmd.AddCommand(newClickCmd(backend))
+	rootCmd.AddCommand(newTypeCmd(backend))
+	rootCmd.AddCommand(newMoveCmd(backend))
+	rootCmd.AddCommand(newDragCmd(backend))
+	rootCmd.AddCommand(newScrollCmd(backend))
+	rootCmd.AddCommand(newScreenshotCmd(backend))
+	rootCmd.AddCommand(newDisplaysCmd(backend))
+	rootCmd.AddCommand(newWaitCmd(backend))
+	rootCmd.AddCommand(newFindImageCmd(backend))
+	rootCmd.AddCommand(newWindowsCmd(backend))
+	rootCmd.AddCommand(newKeyCmd(backend))
+	rootCmd.AddCommand(newRunCmd(backend))
+	rootCmd.AddCommand(newRecordCmd(backend))
+	rootCmd.AddCommand(newReplayCmd(backend))
+	rootCmd.AddCommand(newServeCmd(backend))
+	rootCmd.AddCommand(newAuditCmd())
+
+	// Record every command in the audit log
+	var auditing auditFlags
+	auditing.register(rootCmd)
 
 	return rootCmd
 }
@@ -0,0 +1,668 @@
+package commands
+
+import (
+	"encoding/json"
+	"image/jpeg"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/audit"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/display"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/input"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/window"
+)
+
+func TestCommandsDriveBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []automation.Event
+		wantErr bool
+	}{
+		{
+			name: "click",
+			args: []string{"click", "500", "300"},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 500, Y: 300},
+				{Kind: automation.EventClick, X: 500, Y: 300, Button: automation.ButtonLeft, Count: 1},
+			},
+		},
+		{
+			name: "right double click",
+			args: []string{"click", "--button", "right", "--double", "500", "300"},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 500, Y: 300},
+				{Kind: automation.EventClick, X: 500, Y: 300, Button: automation.ButtonRight, Count: 2},
+			},
+		},
+		{
+			name:    "unknown button",
+			args:    []string{"click", "--button", "fourth", "500", "300"},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+		{
+			name: "move",
+			args: []string{"move", "100", "200"},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 100, Y: 200},
+			},
+		},
+		{
+			name: "smooth move",
+			args: []string{"move", "--smooth", "--duration", "0.5", "100", "200"},
+			want: []automation.Event{
+				{Kind: automation.EventMoveSmooth, X: 100, Y: 200, Duration: 0.5},
+			},
+		},
+		{
+			name: "smooth drag",
+			args: []string{"drag", "--smooth", "--duration", "0.01", "--steps", "4", "0", "0", "100", "200"},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 0, Y: 0},
+				{Kind: automation.EventMouseDown, X: 0, Y: 0, Button: automation.ButtonLeft},
+				{Kind: automation.EventMove, X: 25, Y: 50},
+				{Kind: automation.EventMove, X: 50, Y: 100},
+				{Kind: automation.EventMove, X: 75, Y: 150},
+				{Kind: automation.EventMove, X: 100, Y: 200},
+				{Kind: automation.EventMouseUp, X: 100, Y: 200, Button: automation.ButtonLeft},
+			},
+		},
+		{
+			name:    "drag outside every display",
+			args:    []string{"drag", "--", "0", "0", "100", "-1"},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+		{
+			name: "scroll",
+			args: []string{"scroll", "down", "5"},
+			want: []automation.Event{
+				{Kind: automation.EventScroll, DY: 5},
+			},
+		},
+		{
+			name: "smooth scroll at position",
+			args: []string{"scroll", "--at", "10,20", "--smooth", "--duration", "0.01", "left", "3"},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 10, Y: 20},
+				{Kind: automation.EventScroll, X: 10, Y: 20, DX: -1},
+				{Kind: automation.EventScroll, X: 10, Y: 20, DX: -1},
+				{Kind: automation.EventScroll, X: 10, Y: 20, DX: -1},
+			},
+		},
+		{
+			name:    "unknown scroll direction",
+			args:    []string{"scroll", "sideways", "5"},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+		{
+			name: "wait pixel",
+			args: []string{"wait", "pixel", "10", "20", "#0a1480"},
+			want: []automation.Event{
+				{Kind: automation.EventCapture, X: 10, Y: 20, Width: 1, Height: 1},
+			},
+		},
+		{
+			name: "type",
+			args: []string{"type", "Hello World!"},
+			want: []automation.Event{
+				{Kind: automation.EventType, Text: "Hello World!"},
+			},
+		},
+		{
+			name: "paste",
+			args: []string{"type", "--paste", "--restore-clipboard", "naïve café"},
+			want: []automation.Event{
+				{Kind: automation.EventClipboard, Text: "naïve café"},
+				{Kind: automation.EventKeyTap, Key: "v", Modifiers: automation.PasteChord().Modifiers},
+				{Kind: automation.EventClipboard, Text: ""},
+			},
+		},
+		{
+			name:    "paste with delay",
+			args:    []string{"type", "--paste", "--delay", "10", "text"},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+		{
+			name: "key",
+			args: []string{"key", "ctrl+shift+T", "alt+F4"},
+			want: []automation.Event{
+				{Kind: automation.EventKeyTap, Key: "t", Modifiers: []string{"ctrl", "shift"}},
+				{Kind: automation.EventKeyTap, Key: "f4", Modifiers: []string{"alt"}},
+			},
+		},
+		{
+			name:    "unknown key",
+			args:    []string{"key", "ctrl+c", "ctrl+nope"},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+		{
+			name:    "invalid coordinate",
+			args:    []string{"click", "x", "300"},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := automation.NewFakeBackend()
+			cmd := NewRootCmdWithBackend(backend)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			if err := cmd.Execute(); (err != nil) != tt.wantErr {
+				t.Fatalf("Execute(%q) error = %v, want error %v", tt.args, err, tt.wantErr)
+			}
+
+			if got := backend.Events(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Execute(%q) events = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreenshot(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "region.jpg")
+
+	backend := automation.NewFakeBackend()
+	cmd := NewRootCmdWithBackend(backend)
+	cmd.SetArgs([]string{"screenshot", "-o", output, "--region", "10,20,400,300", "--max-width", "200"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+
+	want := []automation.Event{{Kind: automation.EventCapture, X: 10, Y: 20, Width: 400, Height: 300}}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+
+	f, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := jpeg.Decode(f)
+	if err != nil {
+		t.Fatalf("jpeg.Decode() failed: %v", err)
+	}
+	if got := img.Bounds().Size(); got.X != 200 || got.Y != 150 {
+		t.Errorf("screenshot size = %v, want 200x150", got)
+	}
+}
+
+func TestWindows(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []automation.Event
+		wantErr bool
+	}{
+		{
+			name: "by title",
+			args: []string{"windows", "activate", "--title", "^Term"},
+			want: []automation.Event{{Kind: automation.EventActivate, Window: 0x200}},
+		},
+		{
+			name: "by pid",
+			args: []string{"windows", "activate", "--pid", "7"},
+			want: []automation.Event{{Kind: automation.EventActivate, Window: 0x300}},
+		},
+		{
+			name:    "no match",
+			args:    []string{"windows", "activate", "--title", "Browser", "--pid", "7"},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+		{
+			name:    "no selector",
+			args:    []string{"windows", "activate"},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+		{
+			name: "move",
+			args: []string{"window", "move", "--title", "Terminal", "--", "-20", "40"},
+			want: []automation.Event{{Kind: automation.EventSetBounds, Window: 0x200, X: -20, Y: 40, Width: 640, Height: 480}},
+		},
+		{
+			name: "resize",
+			args: []string{"window", "resize", "--title", "Terminal", "800", "600"},
+			want: []automation.Event{{Kind: automation.EventSetBounds, Window: 0x200, X: 10, Y: 10, Width: 800, Height: 600}},
+		},
+		{
+			name:    "resize to nothing",
+			args:    []string{"window", "resize", "--title", "Terminal", "0", "600"},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+		{
+			name: "minimize",
+			args: []string{"window", "set-state", "--pid", "5", "minimize"},
+			want: []automation.Event{{Kind: automation.EventSetState, Window: 0x100, State: window.StateMinimized}},
+		},
+		{
+			name:    "unknown state",
+			args:    []string{"window", "set-state", "--pid", "5", "fullscreen"},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+		{
+			name: "close",
+			args: []string{"window", "close", "--title", "^Term"},
+			want: []automation.Event{{Kind: automation.EventClose, Window: 0x200}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := automation.NewFakeBackend()
+			backend.SetWindows(
+				window.Window{ID: 0x100, Title: "Editor", PID: 5, Focused: true},
+				window.Window{ID: 0x200, Title: "Terminal", PID: 6, X: 10, Y: 10, Width: 640, Height: 480},
+				window.Window{ID: 0x300, Title: "Editor", PID: 7},
+			)
+			cmd := NewRootCmdWithBackend(backend)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			if err := cmd.Execute(); (err != nil) != tt.wantErr {
+				t.Fatalf("Execute(%q) error = %v, want error %v", tt.args, err, tt.wantErr)
+			}
+
+			if got := backend.Events(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Execute(%q) events = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoordinateFrames(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []automation.Event
+		wantErr bool
+	}{
+		{
+			name: "click in window",
+			args: []string{"click", "--window-title", "Editor", "10", "20"},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 110, Y: 220},
+				{Kind: automation.EventClick, X: 110, Y: 220, Button: automation.ButtonLeft, Count: 1},
+			},
+		},
+		{
+			name: "click left of window",
+			args: []string{"click", "--window-title", "Editor", "--", "-10", "20"},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 90, Y: 220},
+				{Kind: automation.EventClick, X: 90, Y: 220, Button: automation.ButtonLeft, Count: 1},
+			},
+		},
+		{
+			name: "move on display",
+			args: []string{"move", "--display", "1", "5", "5"},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 1925, Y: 5},
+			},
+		},
+		{
+			name: "move on primary display",
+			args: []string{"move", "--frame", "display", "5", "5"},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 5, Y: 5},
+			},
+		},
+		{
+			name: "drag in window of process",
+			args: []string{"drag", "--window-pid", "42", "0", "0", "50", "0"},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 100, Y: 200},
+				{Kind: automation.EventMouseDown, X: 100, Y: 200, Button: automation.ButtonLeft},
+				{Kind: automation.EventMove, X: 150, Y: 200},
+				{Kind: automation.EventMouseUp, X: 150, Y: 200, Button: automation.ButtonLeft},
+			},
+		},
+		{
+			name: "scroll in window",
+			args: []string{"scroll", "--window-title", "Editor", "--at", "1,2", "down", "1"},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 101, Y: 202},
+				{Kind: automation.EventScroll, X: 101, Y: 202, DY: 1},
+			},
+		},
+		{
+			name:    "scroll in window without position",
+			args:    []string{"scroll", "--window-title", "Editor", "down", "1"},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+		{
+			name:    "missing window",
+			args:    []string{"click", "--window-title", "Browser", "10", "20"},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+		{
+			name:    "missing display",
+			args:    []string{"click", "--display", "7", "10", "20"},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+		{
+			name:    "window and display",
+			args:    []string{"click", "--window-title", "Editor", "--display", "1", "10", "20"},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := automation.NewFakeBackend()
+			backend.SetDisplays(display.Layout{
+				{ID: 0, Width: 1920, Height: 1080, Primary: true, ScaleFactor: 1},
+				{ID: 1, X: 1920, Width: 1280, Height: 720, ScaleFactor: 1},
+			})
+			backend.SetWindows(window.Window{ID: 0x100, Title: "Editor", PID: 42, X: 100, Y: 200, Width: 640, Height: 480})
+			cmd := NewRootCmdWithBackend(backend)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			if err := cmd.Execute(); (err != nil) != tt.wantErr {
+				t.Fatalf("Execute(%q) error = %v, want error %v", tt.args, err, tt.wantErr)
+			}
+
+			if got := backend.Events(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Execute(%q) events = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	flow := filepath.Join(t.TempDir(), "flow.yaml")
+	data := `name: greet
+vars:
+  GREETING: hello
+steps:
+  - action: click
+    x: 5
+    y: 6
+  - name: Greet
+    action: type
+    text: ${GREETING} ${WHO}
+  - action: hotkey
+    keys: enter
+`
+	if err := os.WriteFile(flow, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []automation.Event
+		wantErr bool
+	}{
+		{
+			name: "run",
+			args: []string{"run", "--var", "WHO=world", flow},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 5, Y: 6},
+				{Kind: automation.EventClick, X: 5, Y: 6, Button: automation.ButtonLeft, Count: 1},
+				{Kind: automation.EventType, Text: "hello world"},
+				{Kind: automation.EventKeyTap, Key: "enter"},
+			},
+		},
+		{
+			name: "dry run",
+			args: []string{"run", "--dry-run", "--var", "WHO=world", flow},
+			want: []automation.Event{},
+		},
+		{
+			name:    "undefined variable",
+			args:    []string{"run", flow},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+		{
+			name:    "invalid variable",
+			args:    []string{"run", "--var", "WHO", flow},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := automation.NewFakeBackend()
+			cmd := NewRootCmdWithBackend(backend)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			if err := cmd.Execute(); (err != nil) != tt.wantErr {
+				t.Fatalf("Execute(%q) error = %v, want error %v", tt.args, err, tt.wantErr)
+			}
+
+			if got := backend.Events(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Execute(%q) events = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "flow.yaml")
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(ms int, e input.Event) input.Event {
+		e.Time = start.Add(time.Duration(ms) * time.Millisecond)
+		return e
+	}
+
+	backend := automation.NewFakeBackend()
+	backend.SetInputEvents(
+		at(0, input.Event{Kind: input.KindButtonDown, Button: "left", X: 40, Y: 50}),
+		at(50, input.Event{Kind: input.KindButtonUp, Button: "left", X: 40, Y: 50}),
+		at(100, input.Event{Kind: input.KindKeyDown, Key: "o", Text: "o"}),
+		at(120, input.Event{Kind: input.KindKeyDown, Key: "k", Text: "k"}),
+		at(200, input.Event{Kind: input.KindKeyDown, Key: "ctrl"}),
+		at(210, input.Event{Kind: input.KindKeyDown, Key: "alt"}),
+		at(220, input.Event{Kind: input.KindKeyDown, Key: "s", Text: "s"}),
+	)
+
+	cmd := NewRootCmdWithBackend(backend)
+	cmd.SetArgs([]string{"record", "-o", output, "--no-pauses"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute(record) failed: %v", err)
+	}
+
+	// Replay the recording
+	backend.Reset()
+	cmd = NewRootCmdWithBackend(backend)
+	cmd.SetArgs([]string{"run", output})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute(run) failed: %v", err)
+	}
+
+	want := []automation.Event{
+		{Kind: automation.EventMove, X: 40, Y: 50},
+		{Kind: automation.EventClick, X: 40, Y: 50, Button: automation.ButtonLeft, Count: 1},
+		// The text is typed a key at a time, at the recorded pace
+		{Kind: automation.EventType, Text: "o"},
+		{Kind: automation.EventType, Text: "k"},
+	}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("replayed events = %+v, want %+v", got, want)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	flow := filepath.Join(t.TempDir(), "flow.yaml")
+	data := `steps:
+  - action: move
+    x: 10
+    y: 20
+    smooth: true
+    duration: 1s
+    pause: 1h
+  - action: type
+    text: done
+`
+	if err := os.WriteFile(flow, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []automation.Event
+		wantErr bool
+	}{
+		{
+			name: "fast without delays",
+			args: []string{"replay", "--speed", "2", "--no-delays", flow},
+			want: []automation.Event{
+				{Kind: automation.EventMoveSmooth, X: 10, Y: 20, Duration: 0.5},
+				{Kind: automation.EventType, Text: "done"},
+			},
+		},
+		{
+			name:    "zero speed",
+			args:    []string{"replay", "--speed", "0", flow},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+		{
+			name:    "too much jitter",
+			args:    []string{"replay", "--jitter", "1.5", flow},
+			want:    []automation.Event{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := automation.NewFakeBackend()
+			cmd := NewRootCmdWithBackend(backend)
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			if err := cmd.Execute(); (err != nil) != tt.wantErr {
+				t.Fatalf("Execute(%q) error = %v, want error %v", tt.args, err, tt.wantErr)
+			}
+
+			if got := backend.Events(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Execute(%q) events = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "audit.jsonl")
+	execute := func(args ...string) error {
+		cmd := NewRootCmdWithBackend(automation.NewFakeBackend())
+		cmd.SetArgs(append(args, "--audit-log", log, "--audit-hash-chain"))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		return cmd.Execute()
+	}
+
+	if err := execute("type", "--delay", "5", "secret"); err != nil {
+		t.Fatalf("Execute(type) failed: %v", err)
+	}
+	if err := execute("key", "ctrl+a", "delete"); err != nil {
+		t.Fatalf("Execute(key) failed: %v", err)
+	}
+	if err := execute("windows", "move", "--title", "Editor", "10", "x"); err == nil {
+		t.Fatal("Execute(windows move) succeeded, want an error")
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []audit.Entry
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var e audit.Entry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("entry %q is not JSON: %v", line, err)
+		}
+		e.Time, e.Session, e.Client, e.Duration = time.Time{}, "", "", 0
+		entries = append(entries, e)
+	}
+	want := []audit.Entry{
+		{Source: audit.SourceCLI, Action: "type", Arguments: map[string]any{"text": "[redacted 6 characters]", "delay": "5"}, OK: true, Result: "Successfully typed 6 characters"},
+		{Source: audit.SourceCLI, Action: "key", Arguments: map[string]any{"chord": []any{"ctrl+a", "delete"}}, OK: true, Result: "Pressed ctrl+a\nPressed delete"},
+		{Source: audit.SourceCLI, Action: "windows move", Arguments: map[string]any{"x": "10", "y": "x", "title": "Editor"}, Result: "invalid y coordinate: x (must be an integer)"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v, want %+v", entries, want)
+	}
+
+	if err := execute("audit", "verify", log); err != nil {
+		t.Errorf("Execute(audit verify) failed: %v", err)
+	}
+
+	// Script variables are typed, so their values are redacted too
+	flow := filepath.Join(t.TempDir(), "flow.yaml")
+	if err := os.WriteFile(flow, []byte("steps:\n  - action: type\n    text: ${PASSWORD}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := execute("run", "--var", "PASSWORD=secret", flow); err != nil {
+		t.Fatalf("Execute(run) failed: %v", err)
+	}
+	data, err = os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	step, run := lines[len(lines)-2], lines[len(lines)-1]
+	if strings.Contains(run, "secret") || !strings.Contains(run, `"var":{"PASSWORD":"[redacted 6 characters]"}`) {
+		t.Errorf("run entry = %s, want the variable redacted", run)
+	}
+	// Every step is logged in the session of the command
+	if strings.Contains(step, "secret") || !strings.Contains(step, `"action":"run type","arguments":{"step":1,"text":"[redacted 6 characters]"}`) {
+		t.Errorf("step entry = %s, want the typed text redacted", step)
+	}
+	var stepEntry, runEntry audit.Entry
+	if err := json.Unmarshal([]byte(step), &stepEntry); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(run), &runEntry); err != nil {
+		t.Fatal(err)
+	}
+	if stepEntry.Session == "" || stepEntry.Session != runEntry.Session {
+		t.Errorf("step session = %q, want the session %q of the command", stepEntry.Session, runEntry.Session)
+	}
+	tampered := strings.Replace(string(data), "delete", "insert", 1)
+	if err := os.WriteFile(log, []byte(tampered), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := execute("audit", "verify", log); err == nil {
+		t.Error("Execute(audit verify) of a tampered log succeeded, want an error")
+	}
+}
@@ -0,0 +1,42 @@
+// Package commands implements the CLI commands for desktop automation
+package commands
+
+import (
+	"fmt"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/spf13/cobra"
+)
+
+// newDisplaysCmd creates the displays command
+func newDisplaysCmd(backend automation.Backend) *cobra.Command {
+	displaysCmd := &cobra.Command{
+		Use:   "displays",
+		Short: "List the connected displays",
+		Long:  `List every display with its bounds in desktop coordinates, whether it is the primary display and its scale factor.`,
+		Example: `  # Show the monitor layout
+  desktop-automation displays`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			screen := automation.NewScreenWithBackend(backend)
+			layout, err := screen.Displays()
+			if err != nil {
+				return err
+			}
+
+			for _, d := range layout {
+				primary := ""
+				if d.Primary {
+					primary = " (primary)"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Display %d: %dx%d at (%d, %d), scale %.2g%s\n", d.ID, d.Width, d.Height, d.X, d.Y, d.ScaleFactor, primary)
+			}
+
+			bounds := layout.Bounds()
+			fmt.Fprintf(cmd.OutOrStdout(), "Desktop bounds: %dx%d at (%d, %d)\n", bounds.Dx(), bounds.Dy(), bounds.Min.X, bounds.Min.Y)
+			return nil
+		},
+	}
+
+	return displaysCmd
+}
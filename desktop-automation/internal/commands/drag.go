@@ -0,0 +1,86 @@
+// Package commands implements the CLI commands for desktop automation
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/spf13/cobra"
+)
+
+// newDragCmd creates the drag command
+func newDragCmd(backend automation.Backend) *cobra.Command {
+	var (
+		buttonName string
+		smooth     bool
+		duration   float64
+		steps      int
+		frame      frameFlags
+	)
+
+	dragCmd := &cobra.Command{
+		Use:   "drag x1 y1 x2 y2",
+		Short: "Drag the mouse from one screen coordinate to another",
+		Long:  `Press the mouse button at (x1, y1), move to (x2, y2) and release it there, either instantly or smoothly.`,
+		Example: `  # Drag from (100, 100) to (400, 300)
+  desktop-automation drag 100 100 400 300
+
+  # Drag smoothly over 2 seconds
+  desktop-automation drag --smooth --duration 2.0 100 100 400 300
+
+  # Drag a slider inside the settings window
+  desktop-automation drag --window-title Settings 120 80 300 80`,
+		Args: cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Parse and validate the coordinates
+			names := []string{"x1", "y1", "x2", "y2"}
+			coords := make([]int, len(args))
+			for i, arg := range args {
+				v, err := strconv.Atoi(arg)
+				if err != nil {
+					return fmt.Errorf("invalid %s coordinate: %s (must be an integer)", names[i], arg)
+				}
+				coords[i] = v
+			}
+
+			button, err := automation.ParseButton(buttonName)
+			if err != nil {
+				return err
+			}
+
+			f, err := frame.frame()
+			if err != nil {
+				return err
+			}
+
+			// Resolve both end points in screen coordinates
+			mouse := automation.NewMouseWithBackend(backend)
+			fromX, fromY, err := mouse.Resolve(f, coords[0], coords[1])
+			if err != nil {
+				return err
+			}
+			toX, toY, err := mouse.Resolve(f, coords[2], coords[3])
+			if err != nil {
+				return err
+			}
+
+			opts := automation.DragOptions{Button: button, Smooth: smooth, Duration: duration, Steps: steps}
+			if err := mouse.Drag(cmd.Context(), fromX, fromY, toX, toY, opts); err != nil {
+				return fmt.Errorf("failed to drag: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Dragged from %s to %s\n", f.FormatPoint(coords[0], coords[1], fromX, fromY), f.FormatPoint(coords[2], coords[3], toX, toY))
+			return nil
+		},
+	}
+
+	// Add flags for the button and smooth movement
+	dragCmd.Flags().StringVar(&buttonName, "button", "left", "Mouse button to hold (left, right or middle)")
+	dragCmd.Flags().BoolVar(&smooth, "smooth", false, "Drag smoothly through intermediate positions")
+	dragCmd.Flags().Float64Var(&duration, "duration", 1.0, "Duration in seconds for a smooth drag (only applied with --smooth)")
+	dragCmd.Flags().IntVar(&steps, "steps", automation.DefaultDragSteps, "Number of intermediate moves of a smooth drag (only applied with --smooth)")
+	frame.register(dragCmd)
+
+	return dragCmd
+}
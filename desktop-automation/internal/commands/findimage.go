@@ -0,0 +1,70 @@
+// Package commands implements the CLI commands for desktop automation
+package commands
+
+import (
+	"fmt"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/imagematch"
+	"github.com/spf13/cobra"
+)
+
+// newFindImageCmd creates the find-image command
+func newFindImageCmd(backend automation.Backend) *cobra.Command {
+	var (
+		region     string
+		threshold  float64
+		maxResults int
+	)
+
+	findImageCmd := &cobra.Command{
+		Use:   "find-image template",
+		Short: "Find a template image on the screen",
+		Long: `Search the screen for a PNG or JPEG template, such as a screenshot of a button,
+and print every match above the threshold with its center and confidence, best first.`,
+		Example: `  # Find a button anywhere on the screen
+  desktop-automation find-image ok-button.png
+
+  # Search only the top left quarter of a 1920x1080 screen, accepting weaker matches
+  desktop-automation find-image --region 0,0,960,540 --threshold 0.8 icon.png`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tmpl, err := imagematch.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			var r *automation.Rect
+			if region != "" {
+				rect, err := automation.ParseRect(region)
+				if err != nil {
+					return err
+				}
+				r = &rect
+			}
+
+			screen := automation.NewScreenWithBackend(backend)
+			result, err := screen.FindImage(tmpl, r, imagematch.Options{Threshold: threshold, MaxResults: maxResults})
+			if err != nil {
+				return err
+			}
+
+			if len(result.Matches) == 0 {
+				return fmt.Errorf("%s not found on screen", args[0])
+			}
+			for _, m := range result.Matches {
+				x, y := m.Center()
+				fmt.Fprintf(cmd.OutOrStdout(), "Found %dx%d match at (%d, %d), center (%d, %d), confidence %.3f\n", m.Width, m.Height, m.X, m.Y, x, y, m.Confidence)
+			}
+
+			return nil
+		},
+	}
+
+	// Add flags for the search region and matching
+	findImageCmd.Flags().StringVar(&region, "region", "", "Search only the region x,y,w,h instead of the full screen")
+	findImageCmd.Flags().Float64Var(&threshold, "threshold", imagematch.DefaultThreshold, "Confidence from 0 to 1 a match needs")
+	findImageCmd.Flags().IntVar(&maxResults, "max-results", 10, "Largest number of matches to print (0 for no limit)")
+
+	return findImageCmd
+}
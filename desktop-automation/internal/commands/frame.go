@@ -0,0 +1,28 @@
+// Package commands implements the CLI commands for desktop automation
+package commands
+
+import (
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/spf13/cobra"
+)
+
+// frameFlags are the flags choosing the coordinate frame of a mouse command
+type frameFlags struct {
+	kind    string
+	title   string
+	pid     int
+	display int
+}
+
+// register adds the frame flags to cmd
+func (f *frameFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.kind, "frame", "", "What the coordinates are relative to (absolute, window or display); inferred from the other frame flags if empty")
+	cmd.Flags().StringVar(&f.title, "window-title", "", "Make coordinates relative to the first window whose title matches this regular expression")
+	cmd.Flags().IntVar(&f.pid, "window-pid", 0, "Make coordinates relative to the first window of this process")
+	cmd.Flags().IntVar(&f.display, "display", -1, "Make coordinates relative to the display with this ID (the primary display with --frame display)")
+}
+
+// frame returns the coordinate frame chosen by the flags
+func (f *frameFlags) frame() (automation.Frame, error) {
+	return automation.ParseFrame(f.kind, f.title, f.pid, f.display)
+}
@@ -0,0 +1,52 @@
+// Package commands implements the CLI commands for desktop automation
+package commands
+
+import (
+	"fmt"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/spf13/cobra"
+)
+
+// newKeyCmd creates the key command
+func newKeyCmd(backend automation.Backend) *cobra.Command {
+	keyCmd := &cobra.Command{
+		Use:   "key chord [chord...]",
+		Short: "Press a keyboard shortcut",
+		Long: `Press one or more key chords in order. A chord is a list of modifiers
+(ctrl, shift, alt, cmd) followed by a single key, joined with '+'. The plus key
+itself is written as plus or as a trailing '+'.`,
+		Example: `  # Copy the current selection
+  desktop-automation key ctrl+c
+
+  # Reopen the last closed tab, then close the window
+  desktop-automation key ctrl+shift+t alt+F4
+
+  # Zoom in
+  desktop-automation key ctrl++`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Validate every chord before pressing anything
+			chords := make([]automation.Chord, 0, len(args))
+			for _, arg := range args {
+				chord, err := automation.ParseChord(arg)
+				if err != nil {
+					return err
+				}
+				chords = append(chords, chord)
+			}
+
+			keyboard := automation.NewKeyboardWithBackend(backend)
+			for _, chord := range chords {
+				if err := keyboard.PressChord(chord); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Pressed %s\n", chord)
+			}
+
+			return nil
+		},
+	}
+
+	return keyCmd
+}
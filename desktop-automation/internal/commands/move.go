@@ -5,15 +5,16 @@ import (
 	"fmt"
 	"strconv"
 
-	"github.com/pgbytes/gophercon25/desktop-automation/internal/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
 	"github.com/spf13/cobra"
 )
 
 // newMoveCmd creates the move command
-func newMoveCmd() *cobra.Command {
+func newMoveCmd(backend automation.Backend) *cobra.Command {
 	var (
 		smooth   bool
 		duration float64
+		frame    frameFlags
 	)
 
 	moveCmd := &cobra.Command{
@@ -24,7 +25,13 @@ func newMoveCmd() *cobra.Command {
   desktop-automation move 800 600
   
   # Move smoothly to position (800, 600) over 5 seconds
-  desktop-automation move --smooth --duration 5.0 800 600`,
+  desktop-automation move --smooth --duration 5.0 800 600
+
+  # Move to the center of a 1920x1080 display 1
+  desktop-automation move --display 1 960 540
+
+  # Move onto a display left of the primary one; -- ends the flags
+  desktop-automation move -- -960 540`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Parse and validate x coordinate
@@ -32,40 +39,45 @@ func newMoveCmd() *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("invalid x coordinate: %s (must be an integer)", args[0])
 			}
-			if x < 0 {
-				return fmt.Errorf("x coordinate must be non-negative, got: %d", x)
-			}
 
 			// Parse and validate y coordinate
 			y, err := strconv.Atoi(args[1])
 			if err != nil {
 				return fmt.Errorf("invalid y coordinate: %s (must be an integer)", args[1])
 			}
-			if y < 0 {
-				return fmt.Errorf("y coordinate must be non-negative, got: %d", y)
+
+			f, err := frame.frame()
+			if err != nil {
+				return err
 			}
 
 			// Get current mouse position before moving
-			mouse := automation.NewMouse()
+			mouse := automation.NewMouseWithBackend(backend)
 			currentX, currentY := mouse.GetPosition()
-			fmt.Printf("Current mouse position: (%d, %d)\n", currentX, currentY)
-			fmt.Printf("Target position: (%d, %d)\n", x, y)
-			fmt.Println("Moving...")
+			fmt.Fprintf(cmd.OutOrStdout(), "Current mouse position: (%d, %d)\n", currentX, currentY)
+
+			// Resolve the target in screen coordinates
+			sx, sy, err := mouse.Resolve(f, x, y)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Target position: %s\n", f.FormatPoint(x, y, sx, sy))
+			fmt.Fprintln(cmd.OutOrStdout(), "Moving...")
 
 			// Perform the movement based on the smooth flag
 			if smooth {
-				if err := mouse.SmoothMove(x, y, duration); err != nil {
+				if err := mouse.SmoothMove(sx, sy, duration); err != nil {
 					return fmt.Errorf("failed to move smoothly: %w", err)
 				}
 			} else {
-				if err := mouse.Move(x, y); err != nil {
+				if err := mouse.Move(sx, sy); err != nil {
 					return fmt.Errorf("failed to move: %w", err)
 				}
 			}
 
 			// Get final position to confirm
 			finalX, finalY := mouse.GetPosition()
-			fmt.Printf("Final position: (%d, %d)\n", finalX, finalY)
+			fmt.Fprintf(cmd.OutOrStdout(), "Final position: (%d, %d)\n", finalX, finalY)
 
 			return nil
 		},
@@ -74,6 +86,7 @@ func newMoveCmd() *cobra.Command {
 	// Add flags for smooth movement
 	moveCmd.Flags().BoolVar(&smooth, "smooth", false, "Move the mouse smoothly (animated)")
 	moveCmd.Flags().Float64Var(&duration, "duration", 1.0, "Duration in seconds for smooth movement (only applied with --smooth)")
+	frame.register(moveCmd)
 
 	return moveCmd
 }
@@ -0,0 +1,113 @@
+// Package commands implements the CLI commands for desktop automation
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/input"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/script"
+	"github.com/spf13/cobra"
+)
+
+// defaultStopChord is the hotkey that ends a recording
+const defaultStopChord = "ctrl+alt+s"
+
+// newRecordCmd creates the record command
+func newRecordCmd(backend automation.Backend) *cobra.Command {
+	var (
+		output   string
+		name     string
+		stop     string
+		timeout  time.Duration
+		minPause time.Duration
+		noPauses bool
+	)
+
+	recordCmd := &cobra.Command{
+		Use:   "record",
+		Short: "Record mouse and keyboard input as a script",
+		Long: `Capture the global mouse and keyboard input until the stop hotkey is pressed,
+and save it as a script for the replay and run commands. Clicks, double clicks,
+drags, scrolls, typed text and hotkeys become steps, and idle time between
+them becomes pauses. Plain mouse moves are not recorded.
+
+On X11 the input events are read with the RECORD extension. If the X server
+lacks it, the input is sampled every 5ms instead, with a warning: wheel
+scrolling and presses shorter than 5ms are then missed, and keys pressed
+within the same 5ms are recorded in keycode order. Review the saved script
+before replaying such a recording.`,
+		Example: `  # Record until ctrl+alt+s is pressed
+  desktop-automation record -o flow.yaml
+
+  # Record for at most a minute, stopping with ctrl+shift+f12, without pauses
+  desktop-automation record -o flow.yaml --stop ctrl+shift+f12 --timeout 1m --no-pauses`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stopChord, err := automation.ParseChord(stop)
+			if err != nil {
+				return fmt.Errorf("invalid stop hotkey: %w", err)
+			}
+			if minPause < 0 || timeout < 0 {
+				return fmt.Errorf("--min-pause and --timeout must be non-negative")
+			}
+
+			source, err := backend.RecordInput()
+			if err != nil {
+				return fmt.Errorf("failed to start recording: %w", err)
+			}
+			defer source.Close()
+			if degraded, ok := source.(input.Degraded); ok {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", degraded.Warning())
+			}
+
+			ctx := cmd.Context()
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Recording, press %s to stop\n", stopChord)
+			events, err := script.Capture(ctx, source, stopChord)
+			if err != nil {
+				return fmt.Errorf("recording failed: %w", err)
+			}
+
+			steps := script.Coalesce(events, script.RecordOptions{MinPause: minPause, NoPauses: noPauses})
+			if len(steps) == 0 {
+				return fmt.Errorf("nothing was recorded")
+			}
+
+			if name == "" {
+				name = strings.TrimSuffix(filepath.Base(output), filepath.Ext(output))
+			}
+			s := &script.Script{Name: name, Steps: steps}
+			data, err := s.Marshal()
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(output, data, 0o644); err != nil {
+				return fmt.Errorf("failed to write script: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Recorded %d events as %d steps to %s\n", len(events), len(steps), output)
+			return nil
+		},
+	}
+
+	// Add flags for the output, stopping and pauses
+	recordCmd.Flags().StringVarP(&output, "output", "o", "recording.yaml", "File to save the script to")
+	recordCmd.Flags().StringVar(&name, "name", "", "Name of the script (the output file name by default)")
+	recordCmd.Flags().StringVar(&stop, "stop", defaultStopChord, "Hotkey that stops the recording")
+	recordCmd.Flags().DurationVar(&timeout, "timeout", 0, "Stop recording after this long (0 for no limit)")
+	recordCmd.Flags().DurationVar(&minPause, "min-pause", script.DefaultMinPause, "Shortest idle time between steps that is kept as a pause")
+	recordCmd.Flags().BoolVar(&noPauses, "no-pauses", false, "Drop all pauses so that the script replays as fast as possible")
+
+	return recordCmd
+}
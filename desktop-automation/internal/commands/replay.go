@@ -0,0 +1,57 @@
+// Package commands implements the CLI commands for desktop automation
+package commands
+
+import (
+	"fmt"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/script"
+	"github.com/spf13/cobra"
+)
+
+// newReplayCmd creates the replay command
+func newReplayCmd(backend automation.Backend) *cobra.Command {
+	var (
+		vars   []string
+		timing script.Timing
+	)
+
+	replayCmd := &cobra.Command{
+		Use:   "replay script",
+		Short: "Replay a recorded script with control over its timing",
+		Long: `Replay a script, usually one saved by the record command, at its original pace,
+faster or slower with --speed, or as fast as possible with --no-delays, which
+skips the pauses and fixed waits between steps and types text at once. --jitter varies every delay
+and smooth motion randomly so that the replay looks less mechanical.`,
+		Example: `  # Replay at the recorded speed
+  desktop-automation replay flow.yaml
+
+  # Replay twice as fast
+  desktop-automation replay --speed 2.0 flow.yaml
+
+  # Replay without any pauses
+  desktop-automation replay --no-delays flow.yaml
+
+  # Replay at the recorded speed with delays varying by up to 20%
+  desktop-automation replay --jitter 0.2 flow.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !(timing.Speed > 0) {
+				return fmt.Errorf("speed must be positive, got: %g", timing.Speed)
+			}
+			if err := timing.Validate(); err != nil {
+				return err
+			}
+
+			return runScript(cmd, backend, args[0], vars, false, timing)
+		},
+	}
+
+	// Add flags for variables and timing
+	replayCmd.Flags().StringArrayVar(&vars, "var", nil, "Set a script variable as NAME=value (repeatable)")
+	replayCmd.Flags().Float64Var(&timing.Speed, "speed", 1.0, "Replay speed; 2.0 is twice as fast, 0.5 half as fast")
+	replayCmd.Flags().BoolVar(&timing.NoDelays, "no-delays", false, "Skip the pauses and fixed waits between steps")
+	replayCmd.Flags().Float64Var(&timing.Jitter, "jitter", 0, "Vary every delay randomly by up to this fraction, like 0.2 for ±20%")
+
+	return replayCmd
+}
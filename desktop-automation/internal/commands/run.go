@@ -0,0 +1,139 @@
+// Package commands implements the CLI commands for desktop automation
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/script"
+	"github.com/spf13/cobra"
+)
+
+// newRunCmd creates the run command
+func newRunCmd(backend automation.Backend) *cobra.Command {
+	var (
+		vars   []string
+		dryRun bool
+	)
+
+	runCmd := &cobra.Command{
+		Use:   "run script",
+		Short: "Run an automation script",
+		Long: `Run the steps of a YAML or JSON automation script in order: move, click, drag,
+scroll, type, hotkey, wait and screenshot. Every step is checked before the
+first one runs, and the run stops at the first failing step.
+
+Text fields may reference variables as ${NAME}. They are looked up in --var,
+then in the environment, then in the vars of the script.
+
+  name: Log in
+  vars:
+    USERNAME: demo
+  steps:
+    - name: Focus the user field
+      action: click
+      x: 400
+      y: 300
+      window_title: Login
+    - action: type
+      text: ${USERNAME}
+    - action: hotkey
+      keys: tab
+    - action: type
+      text: ${PASSWORD}
+      paste: true
+    - action: hotkey
+      keys: enter
+    - action: wait
+      duration: 2s
+    - action: screenshot
+      output: after-login.png`,
+		Example: `  # Run a flow
+  desktop-automation run login.yaml
+
+  # Run it as another user
+  desktop-automation run --var USERNAME=alice --var PASSWORD="$SECRET" login.yaml
+
+  # Only check the script
+  desktop-automation run --dry-run login.yaml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScript(cmd, backend, args[0], vars, dryRun, script.Timing{})
+		},
+	}
+
+	// Add flags for variables and validation
+	runCmd.Flags().StringArrayVar(&vars, "var", nil, "Set a script variable as NAME=value (repeatable)")
+	runCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Check the script and list its steps without running them")
+
+	return runCmd
+}
+
+// runScript loads the script at path, expands its variables and runs it at
+// the given timing, reporting every step. With dryRun it only checks the
+// script and lists its steps.
+func runScript(cmd *cobra.Command, backend automation.Backend, path string, vars []string, dryRun bool, timing script.Timing) error {
+	overrides, err := parseVars(vars)
+	if err != nil {
+		return err
+	}
+
+	s, err := script.Load(path)
+	if err != nil {
+		return err
+	}
+	s, err = s.Expand(s.Variables(overrides))
+	if err != nil {
+		return err
+	}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Fprintf(cmd.OutOrStdout(), "Script %q is valid:\n", s.Name)
+		for i, st := range s.Steps {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", st.Label(i))
+		}
+		return nil
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Running %q (%d steps) at %s\n", s.Name, len(s.Steps), timing)
+	runner := script.NewRunnerWithBackend(backend)
+	runner.Timing = timing
+	runner.Progress = func(result script.StepResult) {
+		auditStep(cmd, result)
+		if result.Err != nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "  FAIL %s after %s: %v\n", result.Step.Label(result.Index), result.Elapsed.Round(timeRounding), result.Err)
+			return
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "  ok   %s: %s (%s)\n", result.Step.Label(result.Index), result.Detail, result.Elapsed.Round(timeRounding))
+	}
+
+	report, err := runner.Run(cmd.Context(), s)
+	if err != nil {
+		return fmt.Errorf("script %q stopped after %d of %d steps: %w", s.Name, len(report.Results), report.Total, err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Completed %d steps in %s\n", report.Total, report.Elapsed.Round(timeRounding))
+	return nil
+}
+
+// timeRounding is the precision of durations in the run report
+const timeRounding = time.Millisecond
+
+// parseVars parses NAME=value pairs
+func parseVars(pairs []string) (map[string]string, error) {
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable: %s (must be NAME=value)", pair)
+		}
+		vars[name] = value
+	}
+
+	return vars, nil
+}
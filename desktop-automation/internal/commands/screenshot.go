@@ -0,0 +1,78 @@
+// Package commands implements the CLI commands for desktop automation
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/spf13/cobra"
+)
+
+// newScreenshotCmd creates the screenshot command
+func newScreenshotCmd(backend automation.Backend) *cobra.Command {
+	var (
+		output    string
+		region    string
+		maxWidth  int
+		maxHeight int
+		quality   int
+	)
+
+	screenshotCmd := &cobra.Command{
+		Use:   "screenshot",
+		Short: "Capture the screen to an image file",
+		Long: `Capture the full screen, or a region of it, and save it as a PNG or JPEG file.
+The format follows the extension of the output file.`,
+		Example: `  # Capture the full screen
+  desktop-automation screenshot -o screen.png
+
+  # Capture a 400x300 region at (100, 50) as JPEG
+  desktop-automation screenshot -o region.jpg --region 100,50,400,300`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := automation.ParseImageFormat(strings.TrimPrefix(filepath.Ext(output), "."))
+			if err != nil {
+				return fmt.Errorf("invalid output file %s: %w", output, err)
+			}
+
+			opts := automation.CaptureOptions{
+				MaxWidth:  maxWidth,
+				MaxHeight: maxHeight,
+				Format:    format,
+				Quality:   quality,
+			}
+			if region != "" {
+				r, err := automation.ParseRect(region)
+				if err != nil {
+					return err
+				}
+				opts.Region = &r
+			}
+
+			screen := automation.NewScreenWithBackend(backend)
+			shot, err := screen.CaptureEncoded(opts)
+			if err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(output, shot.Data, 0o644); err != nil {
+				return fmt.Errorf("failed to write screenshot: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved %dx%d screenshot of region %s to %s\n", shot.Width, shot.Height, shot.Region, output)
+			return nil
+		},
+	}
+
+	// Add flags for the output file, region and encoding
+	screenshotCmd.Flags().StringVarP(&output, "output", "o", "screenshot.png", "File to save the screenshot to (.png, .jpg or .jpeg)")
+	screenshotCmd.Flags().StringVar(&region, "region", "", "Capture only the region x,y,w,h instead of the full screen")
+	screenshotCmd.Flags().IntVar(&maxWidth, "max-width", 0, "Downscale the screenshot to at most this width (0 for no limit)")
+	screenshotCmd.Flags().IntVar(&maxHeight, "max-height", 0, "Downscale the screenshot to at most this height (0 for no limit)")
+	screenshotCmd.Flags().IntVar(&quality, "quality", automation.DefaultJPEGQuality, "JPEG quality from 1 to 100")
+
+	return screenshotCmd
+}
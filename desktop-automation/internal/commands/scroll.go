@@ -0,0 +1,124 @@
+// Package commands implements the CLI commands for desktop automation
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/spf13/cobra"
+)
+
+// newScrollCmd creates the scroll command
+func newScrollCmd(backend automation.Backend) *cobra.Command {
+	var (
+		at       string
+		pixels   bool
+		smooth   bool
+		duration float64
+		frame    frameFlags
+	)
+
+	scrollCmd := &cobra.Command{
+		Use:   "scroll direction amount",
+		Short: "Scroll the mouse wheel",
+		Long: `Scroll the mouse wheel up, down, left or right by a number of notches, or
+by roughly the given number of pixels with --pixels.`,
+		Example: `  # Scroll down 5 notches at the current position
+  desktop-automation scroll down 5
+
+  # Scroll right by about 200 pixels at position (500, 300)
+  desktop-automation scroll --pixels --at 500,300 right 200
+
+  # Scroll up 10 notches over 2 seconds
+  desktop-automation scroll --smooth --duration 2.0 up 10
+
+  # Scroll down 3 notches over the list at (200, 150) in the mail window
+  desktop-automation scroll --window-title Mail --at 200,150 down 3`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			direction, err := automation.ParseScrollDirection(args[0])
+			if err != nil {
+				return err
+			}
+
+			// Parse and validate the amount
+			amount, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid amount: %s (must be an integer)", args[1])
+			}
+			if amount <= 0 {
+				return fmt.Errorf("amount must be positive, got: %d", amount)
+			}
+
+			opts := automation.ScrollOptions{Unit: automation.ScrollNotches, Smooth: smooth, Duration: duration}
+			if pixels {
+				opts.Unit = automation.ScrollPixels
+			}
+
+			f, err := frame.frame()
+			if err != nil {
+				return err
+			}
+
+			mouse := automation.NewMouseWithBackend(backend)
+			dx, dy := direction.Delta(amount)
+			if at == "" {
+				if !f.IsAbsolute() {
+					return fmt.Errorf("a window or display frame requires --at")
+				}
+				if err := mouse.Scroll(cmd.Context(), dx, dy, opts); err != nil {
+					return fmt.Errorf("failed to scroll: %w", err)
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Scrolled %s by %d %s\n", direction, amount, opts.Unit)
+				return nil
+			}
+
+			x, y, err := parsePoint(at)
+			if err != nil {
+				return err
+			}
+			sx, sy, err := mouse.Resolve(f, x, y)
+			if err != nil {
+				return err
+			}
+			if err := mouse.ScrollAt(cmd.Context(), sx, sy, dx, dy, opts); err != nil {
+				return fmt.Errorf("failed to scroll: %w", err)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Scrolled %s by %d %s at %s\n", direction, amount, opts.Unit, f.FormatPoint(x, y, sx, sy))
+			return nil
+		},
+	}
+
+	// Add flags for the position, unit and smooth scrolling
+	scrollCmd.Flags().StringVar(&at, "at", "", "Move to position x,y before scrolling")
+	scrollCmd.Flags().BoolVar(&pixels, "pixels", false, "Treat the amount as pixels instead of wheel notches")
+	scrollCmd.Flags().BoolVar(&smooth, "smooth", false, "Scroll one notch at a time spread over --duration")
+	scrollCmd.Flags().Float64Var(&duration, "duration", 1.0, "Duration in seconds for smooth scrolling (only applied with --smooth)")
+	frame.register(scrollCmd)
+
+	return scrollCmd
+}
+
+// parsePoint parses a screen coordinate written as x,y
+func parsePoint(s string) (int, int, error) {
+	xs, ys, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid position: %s (must be x,y)", s)
+	}
+
+	x, err := strconv.Atoi(strings.TrimSpace(xs))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid x coordinate: %s (must be an integer)", xs)
+	}
+
+	y, err := strconv.Atoi(strings.TrimSpace(ys))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid y coordinate: %s (must be an integer)", ys)
+	}
+
+	return x, y, nil
+}
@@ -0,0 +1,139 @@
+// Package commands implements the CLI commands for desktop automation
+package commands
+
+import (
+	"errors"
+	"os"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/mcpserver"
+	"github.com/spf13/cobra"
+)
+
+// newServeCmd creates the serve command and its subcommands
+func newServeCmd(backend automation.Backend) *cobra.Command {
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run desktop automation as a server",
+		Long:  `Expose desktop automation capabilities to other programs over a server protocol.`,
+	}
+
+	serveCmd.AddCommand(newServeMCPCmd(backend))
+
+	return serveCmd
+}
+
+// newServeMCPCmd creates the serve mcp command
+func newServeMCPCmd(backend automation.Backend) *cobra.Command {
+	var flags mcpserver.Flags
+
+	mcpCmd := &cobra.Command{
+		Use:   "mcp",
+		Short: "Run the MCP server over STDIO, SSE or streamable HTTP",
+		Long: `Run a Model Context Protocol server exposing the desktop automation tools.
+
+By default the server talks to a single client over STDIO, so the client has to
+start it on the same machine. With --transport sse or --transport http it listens
+on --addr instead, so that agents on other machines can drive this desktop and
+several clients can share one long-lived server. The SSE transport serves events
+on /sse and messages on /message; the streamable HTTP transport serves /mcp.
+
+Anyone who can reach a network transport controls the mouse and keyboard, so
+authenticate clients when listening beyond localhost:
+
+  --token-file   YAML file of bearer tokens, each with an optional allowlist
+                 of tools (names or patterns like "mouse_*")
+  ` + mcpserver.TokenEnv + `
+                 environment variable of comma-separated tokens that may
+                 call every tool
+  --tls-cert, --tls-key
+                 serve over HTTPS
+  --client-ca    additionally require client certificates signed by these
+                 CAs (mutual TLS)
+
+Requests without valid credentials get 401 Unauthorized; calls to tools outside
+a token's allowlist get 403 Forbidden, and such tools are not listed.
+
+A policy file given with --policy limits what every client may do, over any
+transport: which tools are enabled, the screen regions and windows that clicks
+and moves may land in, the length of typed text, text patterns that may not be
+typed, and the window that must be focused before keys are pressed. Calls that
+break it fail with a JSON tool error naming the rule.
+
+Calls of the tools named by --approve wait until a human approves them on the
+terminal (--approval-terminal) or through a local HTTP endpoint (--approval-addr),
+whichever answers first. GET /approvals lists the pending calls; POST
+/approvals/{id}/approve or /approvals/{id}/deny decides one. Calls nobody
+approves within --approval-timeout are denied, and every decision is written as
+a line of JSON, with typed text masked, to --approval-log or standard error.
+
+The approval endpoint only listens on loopback addresses, since anyone who can
+reach it can approve calls. Every request must carry the random token printed
+on standard error at startup as "Authorization: Bearer <token>", which changes
+on every run. Requests with an Origin header are refused, so that web pages
+open in a local browser cannot approve calls either.
+
+A token file looks like:
+
+  tokens:
+    - name: ci
+      token: 2f1c...
+      tools: [screen_capture, "mouse_*"]
+    - name: admin
+      token: 9a7e...
+
+A policy file looks like:
+
+  tools:
+    deny: [window_close, "clipboard_*"]
+  pointer:
+    regions: ["0,0,1920,1080"]
+    windows: ["^Editor"]
+  keyboard:
+    max_text_length: 200
+    deny_text: ['rm\s+-rf', '\bsudo\b']
+    require_focus: "^Editor"`,
+		Example: `  # Run the MCP server (usually launched by an MCP client)
+  desktop-automation serve mcp
+
+  # Serve streamable HTTP clients on http://localhost:8080/mcp
+  desktop-automation serve mcp --transport http
+
+  # Serve SSE clients from other machines on port 9000
+  desktop-automation serve mcp --transport sse --addr :9000
+
+  # Run the MCP server limited by a policy
+  desktop-automation serve mcp --policy policy.yaml
+
+  # Let the agent move the mouse freely but ask before it types or clicks
+  desktop-automation serve mcp --transport http --approve "keyboard_*,mouse_click*" \
+    --approval-terminal --approval-log approvals.jsonl
+
+  # Approve from another program with the token printed at startup: list
+  # pending calls, then approve one
+  desktop-automation serve mcp --approve window_close --approval-addr localhost:8090
+  curl -H "Authorization: Bearer $TOKEN" localhost:8090/approvals
+  curl -H "Authorization: Bearer $TOKEN" -X POST localhost:8090/approvals/1a2b3c4d/approve
+
+  # Serve HTTPS clients that present a bearer token and a client certificate
+  desktop-automation serve mcp --transport http --addr :8443 --token-file tokens.yaml \
+    --tls-cert server.pem --tls-key server-key.pem --client-ca clients.pem`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			config, closeConfig, err := flags.Config()
+			if err != nil {
+				return err
+			}
+			defer func() { err = errors.Join(err, closeConfig()) }()
+
+			// Standard output carries the protocol of the stdio transport
+			config.Announce(os.Stderr)
+			config.Audit = auditLogger(cmd.Context())
+			return config.Run(cmd.Context(), backend)
+		},
+	}
+
+	flags.Register(mcpCmd.Flags())
+
+	return mcpCmd
+}
@@ -3,30 +3,55 @@ package commands
 
 import (
 	"fmt"
+	"unicode/utf8"
 
-	"github.com/pgbytes/gophercon25/desktop-automation/internal/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
 	"github.com/spf13/cobra"
 )
 
 // newTypeCmd creates the type command
-func newTypeCmd() *cobra.Command {
-	var delayMs int
+func newTypeCmd(backend automation.Backend) *cobra.Command {
+	var (
+		delayMs int
+		paste   bool
+		restore bool
+	)
 
 	typeCmd := &cobra.Command{
 		Use:   "type [text]",
 		Short: "Type text on the keyboard",
-		Long:  `Simulate keyboard typing of the specified text.`,
+		Long: `Simulate keyboard typing of the specified text. With --paste the text is put on
+the clipboard and pasted instead, which is faster for long text and works for
+characters the keyboard layout cannot produce.`,
 		Example: `  # Type "Hello World!"
   desktop-automation type 'Hello World!'
   
   # Type with delay between keystrokes
-  desktop-automation type --delay=50 'Slow typing!'`,
+  desktop-automation type --delay=50 'Slow typing!'
+
+  # Paste a long text through the clipboard, keeping what was copied before
+  desktop-automation type --paste --restore-clipboard "$(cat notes.txt)"`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			text := args[0]
 
 			// Create keyboard automation instance
-			keyboard := automation.NewKeyboard()
+			keyboard := automation.NewKeyboardWithBackend(backend)
+
+			if paste {
+				if delayMs > 0 {
+					return fmt.Errorf("--delay cannot be combined with --paste")
+				}
+				if err := keyboard.Paste(text, restore); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "Successfully pasted %d characters\n", utf8.RuneCountInString(text))
+				return nil
+			}
+			if restore {
+				return fmt.Errorf("--restore-clipboard requires --paste")
+			}
 
 			var err error
 			if delayMs > 0 {
@@ -40,13 +65,15 @@ func newTypeCmd() *cobra.Command {
 			}
 
 			// Show success message with character count
-			fmt.Printf("Successfully typed %d characters\n", len(text))
+			fmt.Fprintf(cmd.OutOrStdout(), "Successfully typed %d characters\n", utf8.RuneCountInString(text))
 			return nil
 		},
 	}
 
-	// Add delay flag
+	// Add flags for the delay and paste mode
 	typeCmd.Flags().IntVar(&delayMs, "delay", 0, "Delay between keystrokes in milliseconds")
+	typeCmd.Flags().BoolVar(&paste, "paste", false, "Paste the text through the clipboard instead of typing it")
+	typeCmd.Flags().BoolVar(&restore, "restore-clipboard", false, "Put the previous clipboard contents back after pasting (requires --paste)")
 
 	return typeCmd
 }
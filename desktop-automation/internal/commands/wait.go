@@ -0,0 +1,98 @@
+// Package commands implements the CLI commands for desktop automation
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/spf13/cobra"
+)
+
+// newWaitCmd creates the wait command and its subcommands
+func newWaitCmd(backend automation.Backend) *cobra.Command {
+	waitCmd := &cobra.Command{
+		Use:   "wait",
+		Short: "Wait for a condition on the screen",
+		Long:  `Block until something appears on the screen, e.g. before clicking a UI that is still rendering.`,
+	}
+
+	waitCmd.AddCommand(newWaitPixelCmd(backend))
+
+	return waitCmd
+}
+
+// newWaitPixelCmd creates the wait pixel command
+func newWaitPixelCmd(backend automation.Backend) *cobra.Command {
+	var (
+		width     int
+		height    int
+		tolerance int
+		mismatch  bool
+		timeout   time.Duration
+		interval  time.Duration
+	)
+
+	pixelCmd := &cobra.Command{
+		Use:   "pixel x y color",
+		Short: "Wait until a pixel has a color",
+		Long: `Poll the pixel at (x, y), or the average of the region starting there, until it
+matches the hex color within the tolerance, or until it stops matching with --mismatch.`,
+		Example: `  # Wait for the pixel at (500, 300) to turn white
+  desktop-automation wait pixel 500 300 '#ffffff'
+
+  # Wait up to 10 seconds for a 20x20 spinner to disappear from a grey background
+  desktop-automation wait pixel --width 20 --height 20 --tolerance 8 --timeout 10s 500 300 '#808080'
+
+  # Wait until the pixel is no longer black
+  desktop-automation wait pixel --mismatch 500 300 000000`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Parse and validate x coordinate
+			x, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid x coordinate: %s (must be an integer)", args[0])
+			}
+
+			// Parse and validate y coordinate
+			y, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid y coordinate: %s (must be an integer)", args[1])
+			}
+
+			color, err := automation.ParseColor(args[2])
+			if err != nil {
+				return err
+			}
+
+			cond := automation.ColorCondition{
+				Region:    automation.Rect{X: x, Y: y, Width: width, Height: height},
+				Color:     color,
+				Tolerance: tolerance,
+				Mismatch:  mismatch,
+				Timeout:   timeout,
+				Interval:  interval,
+			}
+
+			screen := automation.NewScreenWithBackend(backend)
+			got, err := screen.WaitForColor(cmd.Context(), cond)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Region %s is %s\n", cond.Region, got)
+			return nil
+		},
+	}
+
+	// Add flags for the region, matching and timing
+	pixelCmd.Flags().IntVar(&width, "width", 1, "Width of the region to average")
+	pixelCmd.Flags().IntVar(&height, "height", 1, "Height of the region to average")
+	pixelCmd.Flags().IntVar(&tolerance, "tolerance", 0, "Largest difference per RGB channel that still matches")
+	pixelCmd.Flags().BoolVar(&mismatch, "mismatch", false, "Wait until the color stops matching instead")
+	pixelCmd.Flags().DurationVar(&timeout, "timeout", automation.DefaultWaitTimeout, "How long to wait")
+	pixelCmd.Flags().DurationVar(&interval, "interval", automation.DefaultPollInterval, "Time between checks")
+
+	return pixelCmd
+}
@@ -0,0 +1,327 @@
+// Package commands implements the CLI commands for desktop automation
+package commands
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/window"
+	"github.com/spf13/cobra"
+)
+
+// newWindowsCmd creates the windows command and its subcommands
+func newWindowsCmd(backend automation.Backend) *cobra.Command {
+	windowsCmd := &cobra.Command{
+		Use:     "windows",
+		Aliases: []string{"window"},
+		Short:   "List, arrange and focus application windows",
+		Long:    `Inspect the top-level windows of the desktop, bring one of them to the front, move, resize, minimize, maximize or close it.`,
+	}
+
+	windowsCmd.AddCommand(newWindowsListCmd(backend))
+	windowsCmd.AddCommand(newWindowsActiveCmd(backend))
+	windowsCmd.AddCommand(newWindowsActivateCmd(backend))
+	windowsCmd.AddCommand(newWindowsMoveCmd(backend))
+	windowsCmd.AddCommand(newWindowsResizeCmd(backend))
+	windowsCmd.AddCommand(newWindowsSetStateCmd(backend))
+	windowsCmd.AddCommand(newWindowsCloseCmd(backend))
+
+	return windowsCmd
+}
+
+// newWindowsListCmd creates the windows list command
+func newWindowsListCmd(backend automation.Backend) *cobra.Command {
+	var target selectorFlags
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the top-level windows",
+		Long:  `List every top-level window with its ID, title, owning process, bounds and whether it has the focus.`,
+		Example: `  # List all windows
+  desktop-automation windows list
+
+  # List the windows whose title mentions Firefox
+  desktop-automation windows list --title Firefox`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sel, err := target.selector(false)
+			if err != nil {
+				return err
+			}
+
+			windows := automation.NewWindowsWithBackend(backend)
+			list, err := windows.List(sel)
+			if err != nil {
+				return err
+			}
+
+			if len(list) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "No windows match %s\n", sel)
+				return nil
+			}
+			for _, w := range list {
+				fmt.Fprintln(cmd.OutOrStdout(), w)
+			}
+			return nil
+		},
+	}
+
+	// Add flags for filtering
+	target.register(listCmd)
+
+	return listCmd
+}
+
+// newWindowsActiveCmd creates the windows active command
+func newWindowsActiveCmd(backend automation.Backend) *cobra.Command {
+	activeCmd := &cobra.Command{
+		Use:   "active",
+		Short: "Show the focused window",
+		Long:  `Show the window that currently holds the keyboard focus.`,
+		Example: `  # Show the focused window
+  desktop-automation windows active`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			windows := automation.NewWindowsWithBackend(backend)
+			active, err := windows.Active()
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), active)
+			return nil
+		},
+	}
+
+	return activeCmd
+}
+
+// newWindowsActivateCmd creates the windows activate command
+func newWindowsActivateCmd(backend automation.Backend) *cobra.Command {
+	var target selectorFlags
+
+	activateCmd := &cobra.Command{
+		Use:   "activate",
+		Short: "Bring a window to the front and focus it",
+		Long: `Raise the first window matching --title and --pid and give it the keyboard focus.
+At least one of them is required.`,
+		Example: `  # Focus the terminal
+  desktop-automation windows activate --title 'Terminal$'
+
+  # Focus the window of process 4242
+  desktop-automation windows activate --pid 4242`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sel, err := target.selector(true)
+			if err != nil {
+				return err
+			}
+
+			windows := automation.NewWindowsWithBackend(backend)
+			w, err := windows.Activate(sel)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Activated %s\n", w)
+			return nil
+		},
+	}
+
+	// Add flags for choosing the window
+	target.register(activateCmd)
+
+	return activateCmd
+}
+
+// newWindowsMoveCmd creates the windows move command
+func newWindowsMoveCmd(backend automation.Backend) *cobra.Command {
+	var target selectorFlags
+
+	moveCmd := &cobra.Command{
+		Use:   "move x y",
+		Short: "Move a window",
+		Long:  `Move the first window matching --title and --pid so that its top left corner is at (x, y), keeping its size.`,
+		Example: `  # Put the terminal in the top left corner
+  desktop-automation windows move --title Terminal 0 0
+
+  # Move it onto a display left of the primary one
+  desktop-automation windows move --title Terminal -- -1280 0`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			x, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid x coordinate: %s (must be an integer)", args[0])
+			}
+
+			y, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid y coordinate: %s (must be an integer)", args[1])
+			}
+
+			sel, err := target.selector(true)
+			if err != nil {
+				return err
+			}
+
+			windows := automation.NewWindowsWithBackend(backend)
+			w, err := windows.Move(sel, x, y)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Moved %s\n", w)
+			return nil
+		},
+	}
+
+	// Add flags for choosing the window
+	target.register(moveCmd)
+
+	return moveCmd
+}
+
+// newWindowsResizeCmd creates the windows resize command
+func newWindowsResizeCmd(backend automation.Backend) *cobra.Command {
+	var target selectorFlags
+
+	resizeCmd := &cobra.Command{
+		Use:   "resize width height",
+		Short: "Resize a window",
+		Long:  `Resize the first window matching --title and --pid, keeping its top left corner in place.`,
+		Example: `  # Make the editor 1280x800
+  desktop-automation windows resize --title Editor 1280 800`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			width, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid width: %s (must be an integer)", args[0])
+			}
+
+			height, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid height: %s (must be an integer)", args[1])
+			}
+
+			sel, err := target.selector(true)
+			if err != nil {
+				return err
+			}
+
+			windows := automation.NewWindowsWithBackend(backend)
+			w, err := windows.Resize(sel, width, height)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Resized %s\n", w)
+			return nil
+		},
+	}
+
+	// Add flags for choosing the window
+	target.register(resizeCmd)
+
+	return resizeCmd
+}
+
+// newWindowsSetStateCmd creates the windows set-state command
+func newWindowsSetStateCmd(backend automation.Backend) *cobra.Command {
+	var target selectorFlags
+
+	stateCmd := &cobra.Command{
+		Use:   "set-state state",
+		Short: "Minimize, maximize or restore a window",
+		Long: `Put the first window matching --title and --pid in the given state: minimized,
+maximized or normal. The verbs minimize, maximize and restore are accepted too.`,
+		Example: `  # Maximize the browser
+  desktop-automation windows set-state --title Firefox maximized
+
+  # Restore it
+  desktop-automation windows set-state --title Firefox restore`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			state, err := window.ParseState(args[0])
+			if err != nil {
+				return err
+			}
+
+			sel, err := target.selector(true)
+			if err != nil {
+				return err
+			}
+
+			windows := automation.NewWindowsWithBackend(backend)
+			w, err := windows.SetState(sel, state)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Window %#x is now %s\n", w.ID, state)
+			return nil
+		},
+	}
+
+	// Add flags for choosing the window
+	target.register(stateCmd)
+
+	return stateCmd
+}
+
+// newWindowsCloseCmd creates the windows close command
+func newWindowsCloseCmd(backend automation.Backend) *cobra.Command {
+	var target selectorFlags
+
+	closeCmd := &cobra.Command{
+		Use:   "close",
+		Short: "Close a window gracefully",
+		Long: `Ask the first window matching --title and --pid to close, as if its close button
+was clicked. The application may ask to save changes first.`,
+		Example: `  # Close the calculator
+  desktop-automation windows close --title Calculator`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sel, err := target.selector(true)
+			if err != nil {
+				return err
+			}
+
+			windows := automation.NewWindowsWithBackend(backend)
+			w, err := windows.Close(sel)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Asked %s to close\n", w)
+			return nil
+		},
+	}
+
+	// Add flags for choosing the window
+	target.register(closeCmd)
+
+	return closeCmd
+}
+
+// selectorFlags are the flags picking a window by title and process
+type selectorFlags struct {
+	title string
+	pid   int
+}
+
+// register adds the selector flags to cmd
+func (f *selectorFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.title, "title", "", "Regular expression matching the window title")
+	cmd.Flags().IntVar(&f.pid, "pid", 0, "ID of the process owning the window")
+}
+
+// selector returns the window selector chosen by the flags. If required is
+// set, at least one of the flags must be given.
+func (f *selectorFlags) selector(required bool) (window.Selector, error) {
+	if required && f.title == "" && f.pid == 0 {
+		return window.Selector{}, fmt.Errorf("either --title or --pid is required")
+	}
+
+	return window.NewSelector(f.title, f.pid)
+}
@@ -0,0 +1,47 @@
+//go:build e2e
+
+package harness
+
+import (
+	"context"
+	"flag"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var eventTimeout = flag.Duration("event-timeout", 5*time.Second, "How long to wait for each expected event")
+
+// TestE2E builds the CLI and the MCP server and runs every scenario against
+// a fresh Xvfb display. Run it with go test -tags e2e ./internal/harness/
+// on Linux with Xvfb installed.
+func TestE2E(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// The package lives in desktop-automation/internal/harness
+	root, err := filepath.Abs(filepath.Join("..", "..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, mcp, err := BuildBinaries(ctx, root, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := New(ctx, Options{CLIBinary: cli, MCPBinary: mcp, Timeout: *eventTimeout})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	t.Logf("Running scenarios on display %s", h.Display())
+
+	for _, s := range Scenarios() {
+		t.Run(s.Name, func(t *testing.T) {
+			h.window.Reset()
+			if err := s.Run(ctx, h); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Package harness provides an Xvfb-backed end to end harness that drives the
+// desktop-automation CLI and the MCP server against a virtual X display
+package harness
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// RunCLI runs the desktop-automation binary against the virtual display and
+// returns its combined output
+func (h *Harness) RunCLI(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, h.opts.CLIBinary, args...)
+	cmd.Env = h.Env()
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	if err := cmd.Run(); err != nil {
+		return out.String(), fmt.Errorf("desktop-automation %v failed: %w\n%s", args, err, out.String())
+	}
+
+	return out.String(), nil
+}
+
+// StartCLI starts the desktop-automation binary against the virtual display
+// without waiting for it to exit. Its combined output is written to out.
+func (h *Harness) StartCLI(ctx context.Context, out *bytes.Buffer, args ...string) (*exec.Cmd, error) {
+	cmd := exec.CommandContext(ctx, h.opts.CLIBinary, args...)
+	cmd.Env = h.Env()
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start desktop-automation %v: %w", args, err)
+	}
+
+	return cmd, nil
+}
+
+// ConnectMCP returns an initialized streamable HTTP client connected to the
+// MCP server at url, retrying until the server accepts it or timeout passes
+func ConnectMCP(ctx context.Context, url string, timeout time.Duration) (*client.Client, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		c, err := connectMCP(ctx, url)
+		if err == nil {
+			return c, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, err
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// connectMCP makes a single attempt of ConnectMCP
+func connectMCP(ctx context.Context, url string) (*client.Client, error) {
+	c, err := client.NewStreamableHttpClient(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MCP client: %w", err)
+	}
+	if err := c.Start(ctx); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to start MCP client: %w", err)
+	}
+
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initReq.Params.ClientInfo = mcp.Implementation{Name: "harness", Version: "1.0.0"}
+	if _, err := c.Initialize(ctx, initReq); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to initialize MCP session at %s: %w", url, err)
+	}
+
+	return c, nil
+}
+
+// StartMCP launches the MCP server against the virtual display and returns
+// an initialized STDIO client connected to it
+func (h *Harness) StartMCP(ctx context.Context) (*client.Client, error) {
+	c, err := client.NewStdioMCPClient(h.opts.MCPBinary, []string{"DISPLAY=" + h.display.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start MCP server: %w", err)
+	}
+
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initReq.Params.ClientInfo = mcp.Implementation{Name: "harness", Version: "1.0.0"}
+	if _, err := c.Initialize(ctx, initReq); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to initialize MCP session: %w", err)
+	}
+
+	return c, nil
+}
+
+// CallTool invokes an MCP tool and fails if the result is marked as an error
+func CallTool(ctx context.Context, c *client.Client, name string, args map[string]any) (*mcp.CallToolResult, error) {
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = args
+
+	result, err := c.CallTool(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("tool %s failed: %w", name, err)
+	}
+	if result.IsError {
+		return result, fmt.Errorf("tool %s returned error: %s", name, resultText(result))
+	}
+
+	return result, nil
+}
+
+// resultText joins the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	var buf bytes.Buffer
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			buf.WriteString(text.Text)
+		}
+	}
+
+	return buf.String()
+}
+
+// goBuild compiles pkg inside the module at dir into out
+func goBuild(ctx context.Context, dir, pkg, out string) error {
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", out, pkg)
+	cmd.Dir = dir
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w\n%s", err, output)
+	}
+
+	return nil
+}
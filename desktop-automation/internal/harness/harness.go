@@ -0,0 +1,97 @@
+// Package harness provides an Xvfb-backed end to end harness that drives the
+// desktop-automation CLI and the MCP server against a virtual X display
+package harness
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Options configures a Harness
+type Options struct {
+	// Width and Height of the virtual screen in pixels
+	Width  int
+	Height int
+
+	// CLIBinary is the path to a built desktop-automation binary
+	CLIBinary string
+
+	// MCPBinary is the path to a built desktop-automation-mcp binary
+	MCPBinary string
+
+	// Timeout bounds how long to wait for events to arrive
+	Timeout time.Duration
+}
+
+// Harness owns a running Xvfb display and an event logging window on it
+type Harness struct {
+	opts    Options
+	display *Display
+	window  *EventWindow
+}
+
+// New starts Xvfb and the event logging window described by opts
+func New(ctx context.Context, opts Options) (*Harness, error) {
+	if opts.Width <= 0 {
+		opts.Width = 1280
+	}
+	if opts.Height <= 0 {
+		opts.Height = 800
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+
+	display, err := StartXvfb(ctx, opts.Width, opts.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	window, err := OpenEventWindow(display.Name, opts.Width, opts.Height)
+	if err != nil {
+		display.Close()
+		return nil, err
+	}
+
+	return &Harness{opts: opts, display: display, window: window}, nil
+}
+
+// Display returns the X display name, e.g. ":99"
+func (h *Harness) Display() string {
+	return h.display.Name
+}
+
+// Window returns the event logging window
+func (h *Harness) Window() *EventWindow {
+	return h.window
+}
+
+// Env returns the process environment pointing robotgo at the virtual display
+func (h *Harness) Env() []string {
+	return append(os.Environ(), "DISPLAY="+h.display.Name)
+}
+
+// Close stops the event window and the Xvfb server
+func (h *Harness) Close() error {
+	h.window.Close()
+	return h.display.Close()
+}
+
+// BuildBinaries compiles the CLI and MCP server into dir and returns their paths.
+// root is the repository root containing both Go modules.
+func BuildBinaries(ctx context.Context, root, dir string) (cli, mcp string, err error) {
+	cli = filepath.Join(dir, "desktop-automation")
+	if err := goBuild(ctx, filepath.Join(root, "desktop-automation"), "./cmd/desktop-automation", cli); err != nil {
+		return "", "", fmt.Errorf("failed to build CLI: %w", err)
+	}
+
+	mcp = filepath.Join(dir, "desktop-automation-mcp")
+	if err := goBuild(ctx, filepath.Join(root, "desktop-automation-mcp"), "./cmd/mcp-server", mcp); err != nil {
+		return "", "", fmt.Errorf("failed to build MCP server: %w", err)
+	}
+
+	return cli, mcp, nil
+}
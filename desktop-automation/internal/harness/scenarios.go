@@ -0,0 +1,276 @@
+// Package harness provides an Xvfb-backed end to end harness that drives the
+// desktop-automation CLI and the MCP server against a virtual X display
+package harness
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Scenario is a single end to end check run against a Harness
+type Scenario struct {
+	Name string
+	Run  func(ctx context.Context, h *Harness) error
+}
+
+// Scenarios returns the end to end checks for the CLI and the MCP server
+func Scenarios() []Scenario {
+	return []Scenario{
+		{Name: "cli/move", Run: cliMove},
+		{Name: "cli/click", Run: cliClick},
+		{Name: "cli/right_click", Run: cliRightClick},
+		{Name: "cli/scroll", Run: cliScroll},
+		{Name: "cli/type", Run: cliType},
+		{Name: "cli/windows", Run: cliWindows},
+		{Name: "cli/window_bounds", Run: cliWindowBounds},
+		{Name: "cli/serve_sigterm", Run: cliServeSigterm},
+		{Name: "mcp/mouse_move", Run: mcpMouseMove},
+		{Name: "mcp/mouse_click", Run: mcpMouseClick},
+		{Name: "mcp/mouse_get_position", Run: mcpMouseGetPosition},
+		{Name: "mcp/keyboard_type", Run: mcpKeyboardType},
+		{Name: "mcp/window_get_active", Run: mcpWindowGetActive},
+	}
+}
+
+func cliMove(ctx context.Context, h *Harness) error {
+	if _, err := h.RunCLI(ctx, "move", "200", "150"); err != nil {
+		return err
+	}
+
+	return h.window.WaitForPointer(200, 150, h.opts.Timeout)
+}
+
+func cliClick(ctx context.Context, h *Harness) error {
+	if _, err := h.RunCLI(ctx, "click", "300", "250"); err != nil {
+		return err
+	}
+
+	return h.window.WaitForButton(1, 300, 250, h.opts.Timeout)
+}
+
+func cliRightClick(ctx context.Context, h *Harness) error {
+	if _, err := h.RunCLI(ctx, "click", "--button", "right", "320", "260"); err != nil {
+		return err
+	}
+
+	// X11 numbers the right button 3
+	return h.window.WaitForButton(3, 320, 260, h.opts.Timeout)
+}
+
+func cliScroll(ctx context.Context, h *Harness) error {
+	if _, err := h.RunCLI(ctx, "scroll", "--at", "240,180", "down", "1"); err != nil {
+		return err
+	}
+
+	// X11 reports scrolling down as presses of button 5
+	return h.window.WaitForButton(5, 240, 180, h.opts.Timeout)
+}
+
+func cliType(ctx context.Context, h *Harness) error {
+	text := "Hello World!"
+	if _, err := h.RunCLI(ctx, "type", text); err != nil {
+		return err
+	}
+
+	return h.window.WaitForText(text, h.opts.Timeout)
+}
+
+func cliWindows(ctx context.Context, h *Harness) error {
+	out, err := h.RunCLI(ctx, "windows", "list")
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(out, fmt.Sprintf("%q", EventWindowTitle)) {
+		return fmt.Errorf("expected the event window in the window list, got:\n%s", out)
+	}
+
+	if _, err := h.RunCLI(ctx, "windows", "activate", "--title", "^"+EventWindowTitle+"$"); err != nil {
+		return err
+	}
+
+	out, err = h.RunCLI(ctx, "windows", "active")
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(out, EventWindowTitle) {
+		return fmt.Errorf("expected the event window to be active, got:\n%s", out)
+	}
+
+	return nil
+}
+
+func cliWindowBounds(ctx context.Context, h *Harness) error {
+	title := "^" + EventWindowTitle + "$"
+	if _, err := h.RunCLI(ctx, "windows", "move", "--title", title, "10", "20"); err != nil {
+		return err
+	}
+	if _, err := h.RunCLI(ctx, "windows", "resize", "--title", title, "300", "200"); err != nil {
+		return err
+	}
+
+	out, err := h.RunCLI(ctx, "windows", "list", "--title", title)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(out, "300x200 at (10, 20)") {
+		return fmt.Errorf("expected the event window at 300x200 at (10, 20), got:\n%s", out)
+	}
+
+	// Cover the screen again for the other scenarios
+	if _, err := h.RunCLI(ctx, "windows", "set-state", "--title", title, "maximized"); err != nil {
+		return err
+	}
+	_, err = h.RunCLI(ctx, "windows", "activate", "--title", title)
+	return err
+}
+
+func cliServeSigterm(ctx context.Context, h *Harness) error {
+	// Find a free port for the server
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to find a free port: %w", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	var out bytes.Buffer
+	cmd, err := h.StartCLI(ctx, &out, "serve", "mcp", "--transport", "http", "--addr", addr)
+	if err != nil {
+		return err
+	}
+	defer cmd.Process.Kill()
+
+	c, err := ConnectMCP(ctx, "http://"+addr+"/mcp", h.opts.Timeout)
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, out.String())
+	}
+	defer c.Close()
+
+	if _, err := CallTool(ctx, c, "keyboard_key_down", map[string]any{"key": "shift"}); err != nil {
+		return err
+	}
+	if err := h.window.WaitForKeysDown(h.opts.Timeout); err != nil {
+		return err
+	}
+
+	if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to send SIGTERM: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	select {
+	case err := <-exited:
+		if err != nil {
+			return fmt.Errorf("serve mcp failed on SIGTERM: %w\n%s", err, out.String())
+		}
+	case <-time.After(h.opts.Timeout):
+		return fmt.Errorf("serve mcp did not exit on SIGTERM\n%s", out.String())
+	}
+
+	return h.window.WaitForKeysReleased(h.opts.Timeout)
+}
+
+func mcpMouseMove(ctx context.Context, h *Harness) error {
+	c, err := h.StartMCP(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if _, err := CallTool(ctx, c, "mouse_move", map[string]any{"x": 420, "y": 310}); err != nil {
+		return err
+	}
+
+	return h.window.WaitForPointer(420, 310, h.opts.Timeout)
+}
+
+func mcpMouseClick(ctx context.Context, h *Harness) error {
+	c, err := h.StartMCP(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if _, err := CallTool(ctx, c, "mouse_click", map[string]any{"x": 510, "y": 120}); err != nil {
+		return err
+	}
+
+	return h.window.WaitForButton(1, 510, 120, h.opts.Timeout)
+}
+
+func mcpMouseGetPosition(ctx context.Context, h *Harness) error {
+	c, err := h.StartMCP(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if _, err := CallTool(ctx, c, "mouse_move", map[string]any{"x": 64, "y": 48}); err != nil {
+		return err
+	}
+
+	result, err := CallTool(ctx, c, "mouse_get_position", nil)
+	if err != nil {
+		return err
+	}
+
+	var pos struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &pos); err != nil {
+		return fmt.Errorf("failed to parse position: %w", err)
+	}
+	if pos.X != 64 || pos.Y != 48 {
+		return fmt.Errorf("expected position (64, 48), got (%d, %d)", pos.X, pos.Y)
+	}
+
+	return h.window.WaitForPointer(64, 48, h.opts.Timeout)
+}
+
+func mcpKeyboardType(ctx context.Context, h *Harness) error {
+	c, err := h.StartMCP(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	text := "typed via mcp"
+	if _, err := CallTool(ctx, c, "keyboard_type", map[string]any{"text": text}); err != nil {
+		return err
+	}
+
+	return h.window.WaitForText(text, h.opts.Timeout)
+}
+
+func mcpWindowGetActive(ctx context.Context, h *Harness) error {
+	c, err := h.StartMCP(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	result, err := CallTool(ctx, c, "window_get_active", nil)
+	if err != nil {
+		return err
+	}
+
+	var active struct {
+		Title   string `json:"title"`
+		Focused bool   `json:"focused"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &active); err != nil {
+		return fmt.Errorf("failed to parse window: %w", err)
+	}
+	if active.Title != EventWindowTitle || !active.Focused {
+		return fmt.Errorf("expected %q to be active, got %+v", EventWindowTitle, active)
+	}
+
+	return nil
+}
@@ -0,0 +1,322 @@
+// Package harness provides an Xvfb-backed end to end harness that drives the
+// desktop-automation CLI and the MCP server against a virtual X display
+package harness
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// XEventKind identifies the type of an event received by EventWindow
+type XEventKind string
+
+// Event kinds logged by EventWindow
+const (
+	XEventKeyPress    XEventKind = "key_press"
+	XEventButtonPress XEventKind = "button_press"
+	XEventMotion      XEventKind = "motion"
+)
+
+// XEvent is a single X event received by EventWindow
+type XEvent struct {
+	Kind   XEventKind
+	X      int
+	Y      int
+	Button int
+	Rune   rune
+}
+
+// EventWindowTitle is the title of the event logging window
+const EventWindowTitle = "desktop-automation e2e"
+
+// EventWindow is a tiny X client that covers the screen with a focused
+// window and logs every key, button and motion event it receives
+type EventWindow struct {
+	conn   *xgb.Conn
+	root   xproto.Window
+	window xproto.Window
+
+	minKeycode xproto.Keycode
+	perKeycode int
+	keysyms    []xproto.Keysym
+
+	mu     sync.Mutex
+	events []XEvent
+	done   chan struct{}
+}
+
+// OpenEventWindow connects to display and maps a focused full-screen window
+func OpenEventWindow(display string, width, height int) (*EventWindow, error) {
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", display, err)
+	}
+
+	setup := xproto.Setup(conn)
+	screen := setup.DefaultScreen(conn)
+
+	wid, err := xproto.NewWindowId(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to allocate window id: %w", err)
+	}
+
+	mask := uint32(xproto.EventMaskKeyPress | xproto.EventMaskButtonPress | xproto.EventMaskPointerMotion)
+	err = xproto.CreateWindowChecked(conn, screen.RootDepth, wid, screen.Root,
+		0, 0, uint16(width), uint16(height), 0,
+		xproto.WindowClassInputOutput, screen.RootVisual,
+		xproto.CwBackPixel|xproto.CwEventMask, []uint32{screen.WhitePixel, mask},
+	).Check()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create window: %w", err)
+	}
+
+	title := []byte(EventWindowTitle)
+	err = xproto.ChangePropertyChecked(conn, xproto.PropModeReplace, wid,
+		xproto.AtomWmName, xproto.AtomString, 8, uint32(len(title)), title,
+	).Check()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set window title: %w", err)
+	}
+
+	if err := xproto.MapWindowChecked(conn, wid).Check(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to map window: %w", err)
+	}
+
+	if err := xproto.SetInputFocusChecked(conn, xproto.InputFocusParent, wid, xproto.TimeCurrentTime).Check(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to focus window: %w", err)
+	}
+
+	count := byte(setup.MaxKeycode - setup.MinKeycode + 1)
+	mapping, err := xproto.GetKeyboardMapping(conn, setup.MinKeycode, count).Reply()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read keyboard mapping: %w", err)
+	}
+
+	w := &EventWindow{
+		conn:       conn,
+		root:       screen.Root,
+		window:     wid,
+		minKeycode: setup.MinKeycode,
+		perKeycode: int(mapping.KeysymsPerKeycode),
+		keysyms:    mapping.Keysyms,
+		done:       make(chan struct{}),
+	}
+	go w.loop()
+
+	return w, nil
+}
+
+// Close disconnects from the X server
+func (w *EventWindow) Close() {
+	w.conn.Close()
+	<-w.done
+}
+
+// Events returns a copy of all events logged so far
+func (w *EventWindow) Events() []XEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	events := make([]XEvent, len(w.events))
+	copy(events, w.events)
+	return events
+}
+
+// Reset clears the logged events
+func (w *EventWindow) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.events = nil
+}
+
+// Text returns the characters typed into the window so far
+func (w *EventWindow) Text() string {
+	var b strings.Builder
+	for _, ev := range w.Events() {
+		if ev.Kind == XEventKeyPress && ev.Rune != 0 {
+			b.WriteRune(ev.Rune)
+		}
+	}
+
+	return b.String()
+}
+
+// Pointer returns the pointer position as reported by the X server
+func (w *EventWindow) Pointer() (int, int, error) {
+	reply, err := xproto.QueryPointer(w.conn, w.root).Reply()
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to query pointer: %w", err)
+	}
+
+	return int(reply.RootX), int(reply.RootY), nil
+}
+
+// WaitForText waits until the typed text equals want
+func (w *EventWindow) WaitForText(want string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if w.Text() == want {
+			return nil
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	return fmt.Errorf("expected typed text %q, got %q", want, w.Text())
+}
+
+// WaitForButton waits until a button press is logged at (x, y)
+func (w *EventWindow) WaitForButton(button, x, y int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		for _, ev := range w.Events() {
+			if ev.Kind == XEventButtonPress && ev.Button == button && ev.X == x && ev.Y == y {
+				return nil
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	return fmt.Errorf("no press of button %d received at (%d, %d)", button, x, y)
+}
+
+// WaitForPointer waits until the pointer is at (x, y)
+func (w *EventWindow) WaitForPointer(x, y int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		px, py, err := w.Pointer()
+		if err != nil {
+			return err
+		}
+		if px == x && py == y {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("expected pointer at (%d, %d), got (%d, %d)", x, y, px, py)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// KeysDown returns the keycodes the X server reports as held
+func (w *EventWindow) KeysDown() ([]int, error) {
+	reply, err := xproto.QueryKeymap(w.conn).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query keymap: %w", err)
+	}
+
+	var codes []int
+	for i, bits := range reply.Keys {
+		for bit := 0; bit < 8; bit++ {
+			if bits&(1<<bit) != 0 {
+				codes = append(codes, i*8+bit)
+			}
+		}
+	}
+
+	return codes, nil
+}
+
+// WaitForKeysDown waits until the X server reports a held key
+func (w *EventWindow) WaitForKeysDown(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		codes, err := w.KeysDown()
+		if err != nil {
+			return err
+		}
+		if len(codes) > 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("expected a held key, got none")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// WaitForKeysReleased waits until the X server reports no held keys
+func (w *EventWindow) WaitForKeysReleased(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		codes, err := w.KeysDown()
+		if err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("expected no held keys, got keycodes %v", codes)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// loop reads X events until the connection is closed
+func (w *EventWindow) loop() {
+	defer close(w.done)
+
+	for {
+		ev, xerr := w.conn.WaitForEvent()
+		if ev == nil && xerr == nil {
+			return
+		}
+		if ev == nil {
+			continue
+		}
+
+		switch e := ev.(type) {
+		case xproto.KeyPressEvent:
+			w.log(XEvent{Kind: XEventKeyPress, X: int(e.RootX), Y: int(e.RootY), Rune: w.lookupRune(e.Detail, e.State)})
+		case xproto.ButtonPressEvent:
+			w.log(XEvent{Kind: XEventButtonPress, X: int(e.RootX), Y: int(e.RootY), Button: int(e.Detail)})
+		case xproto.MotionNotifyEvent:
+			w.log(XEvent{Kind: XEventMotion, X: int(e.RootX), Y: int(e.RootY)})
+		}
+	}
+}
+
+// log appends an event to the log
+func (w *EventWindow) log(ev XEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.events = append(w.events, ev)
+}
+
+// lookupRune translates a keycode and modifier state into the typed rune,
+// or 0 for keys that do not produce a printable character
+func (w *EventWindow) lookupRune(code xproto.Keycode, state uint16) rune {
+	index := int(code-w.minKeycode) * w.perKeycode
+	if index < 0 || index+1 >= len(w.keysyms) {
+		return 0
+	}
+
+	sym := w.keysyms[index]
+	if state&xproto.ModMaskShift != 0 && w.keysyms[index+1] != 0 {
+		sym = w.keysyms[index+1]
+	}
+
+	switch {
+	case sym >= 0x20 && sym <= 0x7e:
+		return rune(sym)
+	case sym == 0xff0d:
+		return '\n'
+	case sym == 0xff09:
+		return '\t'
+	}
+
+	return 0
+}
@@ -0,0 +1,96 @@
+// Package harness provides an Xvfb-backed end to end harness that drives the
+// desktop-automation CLI and the MCP server against a virtual X display
+package harness
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Display is a running Xvfb server
+type Display struct {
+	Name string
+	cmd  *exec.Cmd
+}
+
+// StartXvfb launches Xvfb on a display number of its own choosing and waits
+// until it accepts connections. Xvfb reports the number through -displayfd
+// once it listens, so concurrent harnesses cannot pick the same display.
+func StartXvfb(ctx context.Context, width, height int) (*Display, error) {
+	path, err := exec.LookPath("Xvfb")
+	if err != nil {
+		return nil, fmt.Errorf("Xvfb not found in PATH: %w", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create display pipe: %w", err)
+	}
+	defer r.Close()
+
+	// The write end is the first extra file, descriptor 3 of Xvfb
+	cmd := exec.CommandContext(ctx, path,
+		"-displayfd", "3",
+		"-screen", "0", fmt.Sprintf("%dx%dx24", width, height),
+		"-nolisten", "tcp",
+	)
+	cmd.ExtraFiles = []*os.File{w}
+	err = cmd.Start()
+	w.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start Xvfb: %w", err)
+	}
+
+	d := &Display{cmd: cmd}
+	num, err := readDisplayNumber(r, 5*time.Second)
+	if err != nil {
+		d.Close()
+		return nil, err
+	}
+	d.Name = fmt.Sprintf(":%d", num)
+
+	return d, nil
+}
+
+// Close terminates the Xvfb server
+func (d *Display) Close() error {
+	if d.cmd.Process == nil {
+		return nil
+	}
+
+	if err := d.cmd.Process.Kill(); err != nil {
+		return fmt.Errorf("failed to stop Xvfb: %w", err)
+	}
+	_ = d.cmd.Wait()
+	return nil
+}
+
+// readDisplayNumber reads the display number Xvfb writes to its -displayfd
+// when it is ready, failing if it exits or the timeout elapses first
+func readDisplayNumber(r *os.File, timeout time.Duration) (int, error) {
+	if err := r.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return 0, fmt.Errorf("failed to read display number: %w", err)
+	}
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil {
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return 0, fmt.Errorf("Xvfb did not become ready within %s", timeout)
+		}
+		return 0, fmt.Errorf("Xvfb exited before it became ready: %w", err)
+	}
+
+	num, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		return 0, fmt.Errorf("invalid display number %q from Xvfb", strings.TrimSpace(line))
+	}
+
+	return num, nil
+}
@@ -0,0 +1,433 @@
+// Package audit writes an append-only JSON lines log of automation actions,
+// with redacted typed text, size based file rotation and an optional hash
+// chain that makes edits of the log detectable
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Sources of entries
+const (
+	SourceMCP = "mcp"
+	SourceCLI = "cli"
+)
+
+// maxValueLength bounds the characters of results and string arguments, such
+// as base64 images, written to the log
+const maxValueLength = 1000
+
+// Entry is a recorded action
+type Entry struct {
+	// Time is when the action started
+	Time time.Time `json:"time"`
+	// Source is SourceMCP for tool calls and SourceCLI for commands
+	Source string `json:"source"`
+	// Session identifies the MCP session or the CLI invocation
+	Session string `json:"session,omitempty"`
+	// Client names who acted: the bearer token of an MCP client or the user
+	// running the CLI
+	Client string `json:"client,omitempty"`
+	// Action is the tool name or command path, e.g. "keyboard_type"
+	Action string `json:"action"`
+	// Arguments are the arguments of the action
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// OK is whether the action succeeded
+	OK bool `json:"ok"`
+	// Result is the text the action returned, or its error
+	Result string `json:"result,omitempty"`
+	// RedactResult redacts all of Result like typed text, for actions that
+	// return text the user typed or copied, such as reading the clipboard
+	RedactResult bool `json:"-"`
+	// Duration is how long the action took
+	Duration Duration `json:"duration_ms"`
+}
+
+// Duration is a time.Duration written to JSON as milliseconds
+type Duration time.Duration
+
+// MarshalJSON writes d as fractional milliseconds
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return strconv.AppendFloat(nil, float64(d)/float64(time.Millisecond), 'f', -1, 64), nil
+}
+
+// UnmarshalJSON reads fractional milliseconds
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	ms, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("invalid duration: %s", data)
+	}
+	*d = Duration(ms * float64(time.Millisecond))
+	return nil
+}
+
+// Options configure a Logger
+type Options struct {
+	// Redact is how typed text is written; RedactMask if empty
+	Redact Redaction
+	// RedactArguments are the arguments holding typed text;
+	// DefaultRedactedArguments if nil
+	RedactArguments []string
+	// MaxSize rotates the log file before it grows beyond this many bytes;
+	// zero never rotates
+	MaxSize int64
+	// MaxFiles is how many rotated files are kept; zero keeps all of them
+	MaxFiles int
+	// HashChain adds to every line the SHA-256 hash of the line and the hash
+	// of the line before it, so edits break the chain. The hashes are not
+	// keyed, see Verify for what the chain does not detect.
+	HashChain bool
+}
+
+// Logger appends entries to a log. It is safe for concurrent use.
+type Logger struct {
+	opts Options
+	path string
+
+	mu   sync.Mutex
+	w    io.Writer
+	file *os.File
+	size int64
+	prev string
+	err  error
+}
+
+// Open opens the log file at path for appending, creating it if needed, and
+// continues its hash chain
+func Open(path string, opts Options) (*Logger, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	l := &Logger{opts: opts, path: path}
+	if opts.HashChain {
+		prev, err := lastHash(path)
+		if err != nil {
+			return nil, err
+		}
+		l.prev = prev
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// New creates a logger writing to w, which is never rotated
+func New(w io.Writer, opts Options) (*Logger, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	return &Logger{opts: opts, w: w}, nil
+}
+
+// validate checks the options
+func (o Options) validate() error {
+	if _, err := ParseRedaction(string(o.Redact)); err != nil {
+		return err
+	}
+	if o.MaxSize < 0 {
+		return fmt.Errorf("invalid max size: %d (must be non-negative)", o.MaxSize)
+	}
+	if o.MaxFiles < 0 {
+		return fmt.Errorf("invalid max files: %d (must be non-negative)", o.MaxFiles)
+	}
+	return nil
+}
+
+// open opens the log file for appending
+func (l *Logger) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	l.file, l.w, l.size = f, f, info.Size()
+	return nil
+}
+
+// Log redacts and appends e. Once writing fails, Log and Close keep
+// returning the error.
+func (l *Logger) Log(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e = l.redact(e)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return l.err
+	}
+
+	line, hash, err := l.encode(e)
+	if err != nil {
+		return err
+	}
+	if l.file != nil && l.opts.MaxSize > 0 && l.size > 0 && l.size+int64(len(line)) > l.opts.MaxSize {
+		if err := l.rotate(e.Time); err != nil {
+			l.err = err
+			return err
+		}
+	}
+	n, err := l.w.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		l.err = fmt.Errorf("failed to write audit log: %w", err)
+		return l.err
+	}
+	l.prev = hash
+	return nil
+}
+
+// Close closes the log file and returns the first write error, if any
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file != nil {
+		if err := l.file.Close(); err != nil && l.err == nil {
+			l.err = fmt.Errorf("failed to close audit log: %w", err)
+		}
+		l.file = nil
+	}
+	return l.err
+}
+
+// chained is a line of a hash chained log
+type chained struct {
+	Entry
+	// Prev is the hash of the line before, empty on the first line
+	Prev string `json:"prev"`
+}
+
+// encode returns e as a line of JSON and its hash. A chained line ends with
+// ,"hash":"<hex>"} where the hash is the SHA-256 of the line without it.
+func (l *Logger) encode(e Entry) ([]byte, string, error) {
+	if !l.opts.HashChain {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode audit entry: %w", err)
+		}
+		return append(data, '\n'), "", nil
+	}
+
+	data, err := json.Marshal(chained{Entry: e, Prev: l.prev})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	line := append(data[:len(data)-1], `,"hash":"`+hash+"\"}\n"...)
+	return line, hash, nil
+}
+
+// rotate renames the log file to a backup named after now and starts a new
+// one, then removes the oldest backups beyond MaxFiles
+func (l *Logger) rotate(now time.Time) error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	l.file = nil
+	if err := os.Rename(l.path, backupName(l.path, now)); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	if err := l.open(); err != nil {
+		return err
+	}
+
+	if l.opts.MaxFiles == 0 {
+		return nil
+	}
+	backups, err := Backups(l.path)
+	if err != nil {
+		return err
+	}
+	for len(backups) > l.opts.MaxFiles {
+		if err := os.Remove(backups[0]); err != nil {
+			return fmt.Errorf("failed to remove old audit log: %w", err)
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// backupTimeFormat names rotated files so that they sort by age
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// backupName returns the name a log file is rotated to at t, e.g.
+// audit-2025-07-01T12-00-00.000.jsonl for audit.jsonl
+func backupName(path string, t time.Time) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + t.UTC().Format(backupTimeFormat) + ext
+}
+
+// Backups returns the rotated files of the log at path, oldest first
+func Backups(path string) ([]string, error) {
+	ext := filepath.Ext(path)
+	matches, err := filepath.Glob(escapeGlob(strings.TrimSuffix(path, ext)) + "-*" + escapeGlob(ext))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+
+	var backups []string
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(m, strings.TrimSuffix(path, ext)+"-"), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, m)
+		}
+	}
+	slices.Sort(backups)
+	return backups, nil
+}
+
+// escapeGlob quotes the pattern characters of a file name
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// lastHash returns the hash of the last line of the log at path, looking in
+// the newest rotated file if the log is empty
+func lastHash(path string) (string, error) {
+	files, err := Backups(path)
+	if err != nil {
+		return "", err
+	}
+	files = append(files, path)
+
+	for i := len(files) - 1; i >= 0; i-- {
+		data, err := os.ReadFile(files[i])
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read audit log: %w", err)
+		}
+		data = bytes.TrimRight(data, "\n")
+		if len(data) == 0 {
+			continue
+		}
+		line := data[bytes.LastIndexByte(data, '\n')+1:]
+		_, hash, ok := splitHash(line)
+		if !ok {
+			// The log was written without a chain, which starts here
+			return "", nil
+		}
+		return hash, nil
+	}
+	return "", nil
+}
+
+// hashSuffixLength is the length of ,"hash":"<64 hex digits>"}
+const hashSuffixLength = len(`,"hash":""}`) + sha256.Size*2
+
+// splitHash splits a chained line into the JSON it hashes and its hash
+func splitHash(line []byte) ([]byte, string, bool) {
+	if len(line) < hashSuffixLength || !bytes.HasPrefix(line[len(line)-hashSuffixLength:], []byte(`,"hash":"`)) || !bytes.HasSuffix(line, []byte(`"}`)) {
+		return nil, "", false
+	}
+	hash := string(line[len(line)-hashSuffixLength+len(`,"hash":"`) : len(line)-2])
+	if _, err := hex.DecodeString(hash); err != nil {
+		return nil, "", false
+	}
+	body := append(slices.Clone(line[:len(line)-hashSuffixLength]), '}')
+	return body, hash, true
+}
+
+// Verify checks the hash chain of the log files, given oldest first, and
+// returns the number of lines checked. The first line may continue a chain
+// whose older files were removed; every other line must hash to what the
+// next line names as its predecessor.
+//
+// The chain only detects lines modified, inserted, removed or reordered
+// between the first and the last line. Nothing anchors its ends: lines
+// removed from the start or the end leave a valid, shorter chain, and since
+// the hashes are not keyed, anyone who can write the log can rewrite it with
+// a new chain from the first changed line on. To detect that, keep the hash
+// of the last line somewhere the writers of the log cannot change.
+func Verify(paths ...string) (int, error) {
+	n := 0
+	var prev *string
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return n, fmt.Errorf("failed to open audit log: %w", err)
+		}
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(nil, 1<<24)
+		for lineNo := 1; scanner.Scan(); lineNo++ {
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+			body, hash, ok := splitHash(line)
+			if !ok {
+				f.Close()
+				return n, fmt.Errorf("%s:%d: line is not hash chained", path, lineNo)
+			}
+			var c chained
+			if err := json.Unmarshal(body, &c); err != nil {
+				f.Close()
+				return n, fmt.Errorf("%s:%d: invalid entry: %w", path, lineNo, err)
+			}
+			if prev != nil && c.Prev != *prev {
+				f.Close()
+				return n, fmt.Errorf("%s:%d: chain broken: previous hash is %.12s, want %.12s", path, lineNo, c.Prev, *prev)
+			}
+			if sum := sha256.Sum256(body); hex.EncodeToString(sum[:]) != hash {
+				f.Close()
+				return n, fmt.Errorf("%s:%d: entry was modified: hash mismatch", path, lineNo)
+			}
+			prev = &hash
+			n++
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return n, fmt.Errorf("failed to read audit log: %w", err)
+		}
+	}
+	return n, nil
+}
+
+// NewID returns a short random ID for sessions without one
+func NewID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// shorten cuts s to maxValueLength characters
+func shorten(s string) string {
+	if n := utf8.RuneCountInString(s); n > maxValueLength {
+		return fmt.Sprintf("%s… (%d characters)", string([]rune(s)[:maxValueLength]), n)
+	}
+	return s
+}
@@ -0,0 +1,229 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedaction(t *testing.T) {
+	entry := Entry{
+		Source:    SourceMCP,
+		Action:    "keyboard_type",
+		Arguments: map[string]any{"text": "hunter2", "mode": "type", "template": strings.Repeat("A", 2000)},
+		OK:        true,
+		Result:    "Typed: hunter2",
+	}
+
+	tests := []struct {
+		name       string
+		redact     Redaction
+		wantText   any
+		wantResult string
+	}{
+		{name: "mask by default", wantText: "[redacted 7 characters]", wantResult: "Typed: [redacted 7 characters]"},
+		{
+			name:       "hash",
+			redact:     RedactHash,
+			wantText:   "sha256:f52fbd32b2b3b86ff88ef6c490628285f482af15ddcb29541f94bcf526a3f6c7",
+			wantResult: "Typed: sha256:f52fbd32b2b3b86ff88ef6c490628285f482af15ddcb29541f94bcf526a3f6c7",
+		},
+		{name: "none", redact: RedactNone, wantText: "hunter2", wantResult: "Typed: hunter2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l, err := New(&buf, Options{Redact: tt.redact})
+			if err != nil {
+				t.Fatalf("New() failed: %v", err)
+			}
+			if err := l.Log(entry); err != nil {
+				t.Fatalf("Log() failed: %v", err)
+			}
+
+			var got Entry
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("entry %q is not JSON: %v", buf.String(), err)
+			}
+			if got.Arguments["text"] != tt.wantText || got.Result != tt.wantResult {
+				t.Errorf("text = %v and result = %q, want %v and %q", got.Arguments["text"], got.Result, tt.wantText, tt.wantResult)
+			}
+			if got.Arguments["mode"] != "type" {
+				t.Errorf("mode = %v, want it unredacted", got.Arguments["mode"])
+			}
+			if template := got.Arguments["template"].(string); !strings.HasSuffix(template, "(2000 characters)") {
+				t.Errorf("template was not shortened: %.40s…", template)
+			}
+			if got.Time.IsZero() {
+				t.Error("entry has no time")
+			}
+		})
+	}
+}
+
+func TestRedactedArguments(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, Options{RedactArguments: []string{"chord"}})
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	l.Log(Entry{Source: SourceCLI, Action: "key", Arguments: map[string]any{"chord": []string{"ctrl+a", "x"}, "text": "kept"}})
+
+	var got Entry
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("entry %q is not JSON: %v", buf.String(), err)
+	}
+	want := map[string]any{"chord": []any{"[redacted 6 characters]", "[redacted 1 characters]"}, "text": "kept"}
+	if !reflect.DeepEqual(got.Arguments, want) {
+		t.Errorf("arguments = %v, want %v", got.Arguments, want)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	data, err := json.Marshal(Entry{Duration: Duration(1500 * time.Microsecond)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(data, []byte(`"duration_ms":1.5`)) {
+		t.Errorf("entry = %s, want a duration of 1.5ms", data)
+	}
+
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Duration != Duration(1500*time.Microsecond) {
+		t.Errorf("duration = %v, want 1.5ms", time.Duration(e.Duration))
+	}
+}
+
+// logEntries writes n entries to the log at path and closes it
+func logEntries(t *testing.T, path string, opts Options, n int) {
+	t.Helper()
+
+	l, err := Open(path, opts)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	for i := range n {
+		if err := l.Log(Entry{Source: SourceCLI, Action: "move", Arguments: map[string]any{"x": i, "y": i}, OK: true}); err != nil {
+			t.Fatalf("Log() failed: %v", err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+}
+
+func TestHashChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	logEntries(t, path, Options{HashChain: true}, 3)
+	// Reopening continues the chain
+	logEntries(t, path, Options{HashChain: true}, 2)
+
+	if n, err := Verify(path); err != nil || n != 5 {
+		t.Fatalf("Verify() = %d, %v, want 5 entries", n, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(string(data), "\n"), "\n")
+
+	tests := []struct {
+		name    string
+		lines   []string
+		wantErr string
+	}{
+		{name: "modified", lines: []string{lines[0], strings.Replace(lines[1], `"x":1`, `"x":9`, 1), lines[2]}, wantErr: "hash mismatch"},
+		{name: "removed", lines: []string{lines[0], lines[2], lines[3]}, wantErr: "chain broken"},
+		{name: "reordered", lines: []string{lines[0], lines[2], lines[1]}, wantErr: "chain broken"},
+		{name: "inserted", lines: []string{lines[0], `{"source":"cli","action":"click"}` + "\n", lines[1]}, wantErr: "not hash chained"},
+		{name: "first removed", lines: lines[1:]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := filepath.Join(t.TempDir(), "audit.jsonl")
+			if err := os.WriteFile(tampered, []byte(strings.Join(tt.lines, "")), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Verify(tampered)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Verify() failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	opts := Options{HashChain: true, MaxSize: 600, MaxFiles: 2}
+
+	l, err := Open(path, opts)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	start := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 12 {
+		// Entries a second apart give every rotated file its own name
+		e := Entry{Time: start.Add(time.Duration(i) * time.Second), Source: SourceCLI, Action: "move", Arguments: map[string]any{"x": i}}
+		if err := l.Log(e); err != nil {
+			t.Fatalf("Log() failed: %v", err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	backups, err := Backups(path)
+	if err != nil {
+		t.Fatalf("Backups() failed: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("backups = %v, want 2", backups)
+	}
+	for _, f := range append(backups, path) {
+		info, err := os.Stat(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > opts.MaxSize {
+			t.Errorf("%s has %d bytes, want at most %d", f, info.Size(), opts.MaxSize)
+		}
+	}
+
+	// The chain runs on through the rotated files
+	n, err := Verify(append(backups, path)...)
+	if err != nil {
+		t.Fatalf("Verify() failed: %v", err)
+	}
+	if n == 0 || n >= 12 {
+		t.Errorf("Verify() checked %d entries, want the ones of the kept files", n)
+	}
+	if _, err := Verify(path, backups[0]); err == nil {
+		t.Error("Verify() of files out of order succeeded, want an error")
+	}
+}
+
+func TestOptionErrors(t *testing.T) {
+	for _, opts := range []Options{{Redact: "blur"}, {MaxSize: -1}, {MaxFiles: -1}} {
+		if _, err := New(&bytes.Buffer{}, opts); err == nil {
+			t.Errorf("New(%+v) succeeded, want an error", opts)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Package audit writes an append-only JSON lines log of automation actions,
+// with redacted typed text, size based file rotation and an optional hash
+// chain that makes edits of the log detectable
+package audit
+
+import (
+	"github.com/spf13/pflag"
+)
+
+// Flags are the command line flags that configure an audit log, shared by
+// the desktop-automation CLI and the standalone mcp-server binary
+type Flags struct {
+	path      string
+	redact    string
+	maxSizeMB int
+	maxFiles  int
+	hashChain bool
+}
+
+// Register adds the flags to fs
+func (f *Flags) Register(fs *pflag.FlagSet) {
+	fs.StringVar(&f.path, "audit-log", "", "Append a JSON line for every command and MCP tool call to this file")
+	fs.StringVar(&f.redact, "audit-redact", string(RedactMask), "How typed text is written to the audit log: mask, hash or none")
+	fs.IntVar(&f.maxSizeMB, "audit-max-size", 0, "Rotate the audit log when it would grow beyond this many megabytes (0 never rotates)")
+	fs.IntVar(&f.maxFiles, "audit-max-files", 0, "Number of rotated audit logs to keep (0 keeps all)")
+	fs.BoolVar(&f.hashChain, "audit-hash-chain", false, "Chain the audit log entries by their hashes so edits can be detected")
+}
+
+// Enabled reports whether --audit-log was given
+func (f *Flags) Enabled() bool {
+	return f.path != ""
+}
+
+// Open opens the audit log the flags describe
+func (f *Flags) Open() (*Logger, error) {
+	redact, err := ParseRedaction(f.redact)
+	if err != nil {
+		return nil, err
+	}
+	return Open(f.path, Options{
+		Redact:    redact,
+		MaxSize:   int64(f.maxSizeMB) << 20,
+		MaxFiles:  f.maxFiles,
+		HashChain: f.hashChain,
+	})
+}
@@ -0,0 +1,143 @@
+// Package audit writes an append-only JSON lines log of automation actions,
+// with redacted typed text, size based file rotation and an optional hash
+// chain that makes edits of the log detectable
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Redaction is how typed text is written to the log
+type Redaction string
+
+// Redactions
+const (
+	// RedactMask replaces text by its length, e.g. "[redacted 5 characters]"
+	RedactMask Redaction = "mask"
+	// RedactHash replaces text by its SHA-256 hash, so a known text can be
+	// checked against the log without the log revealing it
+	RedactHash Redaction = "hash"
+	// RedactNone writes text as it was typed
+	RedactNone Redaction = "none"
+)
+
+// DefaultRedactedArguments are the arguments of the MCP tools and CLI
+// commands that hold typed text: the text itself, and the script variables
+// set with --var, which type steps may type
+var DefaultRedactedArguments = []string{"text", "var"}
+
+// ParseRedaction parses mask, hash or none; an empty string is mask
+func ParseRedaction(s string) (Redaction, error) {
+	switch r := Redaction(strings.ToLower(s)); r {
+	case "":
+		return RedactMask, nil
+	case RedactMask, RedactHash, RedactNone:
+		return r, nil
+	}
+	return "", fmt.Errorf("invalid redaction: %s (must be mask, hash or none)", s)
+}
+
+// RedactArguments returns a copy of args as a log with the options writes
+// them: the text of the redacted arguments replaced and long values
+// shortened. Other logs of tool calls use it to keep typed text out.
+func RedactArguments(args map[string]any, opts Options) map[string]any {
+	redacted, _ := redactArguments(args, opts)
+	return redacted
+}
+
+// redact returns e with the text of the redacted arguments replaced,
+// including where the result echoes it, and long values shortened
+func (l *Logger) redact(e Entry) Entry {
+	mode, _ := ParseRedaction(string(l.opts.Redact))
+	var secrets []string
+	e.Arguments, secrets = redactArguments(e.Arguments, l.opts)
+
+	// Replace longer secrets first so that one contained in another does not
+	// leave part of the longer one behind
+	slices.SortFunc(secrets, func(a, b string) int { return len(b) - len(a) })
+	for _, s := range secrets {
+		e.Result = strings.ReplaceAll(e.Result, s, redactText(s, mode))
+	}
+	if e.RedactResult && mode != RedactNone && e.Result != "" {
+		e.Result = redactText(e.Result, mode)
+	}
+	e.Result = shorten(e.Result)
+	return e
+}
+
+// redactArguments copies args with the redacted arguments replaced and long
+// values shortened, and returns the original texts it replaced
+func redactArguments(args map[string]any, opts Options) (map[string]any, []string) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	mode, _ := ParseRedaction(string(opts.Redact))
+	names := opts.RedactArguments
+	if names == nil {
+		names = DefaultRedactedArguments
+	}
+
+	var secrets []string
+	redacted := make(map[string]any, len(args))
+	for k, v := range args {
+		if mode != RedactNone && slices.Contains(names, k) {
+			v = redactValue(v, mode, &secrets)
+		} else if s, ok := v.(string); ok {
+			v = shorten(s)
+		}
+		redacted[k] = v
+	}
+	return redacted, secrets
+}
+
+// redactValue redacts a string, the strings of a list or the values of a
+// map, collecting the original texts
+func redactValue(v any, mode Redaction, secrets *[]string) any {
+	switch v := v.(type) {
+	case string:
+		if v != "" {
+			*secrets = append(*secrets, v)
+		}
+		return redactText(v, mode)
+	case []string:
+		redacted := make([]any, len(v))
+		for i, s := range v {
+			redacted[i] = redactValue(s, mode, secrets)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, s := range v {
+			redacted[i] = redactValue(s, mode, secrets)
+		}
+		return redacted
+	case map[string]string:
+		redacted := make(map[string]any, len(v))
+		for k, s := range v {
+			redacted[k] = redactValue(s, mode, secrets)
+		}
+		return redacted
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for k, s := range v {
+			redacted[k] = redactValue(s, mode, secrets)
+		}
+		return redacted
+	}
+	return v
+}
+
+// redactText returns the replacement of text
+func redactText(text string, mode Redaction) string {
+	if mode == RedactHash {
+		sum := sha256.Sum256([]byte(text))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return fmt.Sprintf("[redacted %d characters]", utf8.RuneCountInString(text))
+}
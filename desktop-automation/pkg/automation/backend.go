@@ -0,0 +1,70 @@
+// Package automation provides wrappers for the robotgo library
+// to simplify desktop automation tasks
+package automation
+
+import (
+	"errors"
+	"image"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/display"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/input"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/window"
+)
+
+// ErrUnsupported is returned by the robotgo backend in builds without cgo
+var ErrUnsupported = errors.New("desktop automation requires cgo (build with CGO_ENABLED=1)")
+
+// Backend is the low-level input driver that Mouse and Keyboard delegate to.
+// The default implementation talks to robotgo; FakeBackend records events
+// in memory so that callers can be exercised without a real display.
+type Backend interface {
+	// Move moves the cursor instantly to the given screen coordinates
+	Move(x, y int) error
+	// MoveSmooth moves the cursor to the given coordinates over duration seconds
+	MoveSmooth(x, y int, duration float64) error
+	// Click clicks button count times in a row at the current cursor position
+	Click(button Button, count int) error
+	// MouseDown presses button at the current cursor position
+	MouseDown(button Button) error
+	// MouseUp releases button at the current cursor position
+	MouseUp(button Button) error
+	// Scroll scrolls the wheel by dx and dy notches at the current cursor
+	// position. Positive amounts scroll right and down.
+	Scroll(dx, dy int) error
+	// Location returns the current cursor position
+	Location() (int, int)
+	// Displays returns the monitors that make up the desktop
+	Displays() (display.Layout, error)
+	// ScreenSize returns the width and height of the screen
+	ScreenSize() (int, int)
+	// Capture grabs the w by h rectangle of the screen at (x, y)
+	Capture(x, y, w, h int) (image.Image, error)
+	// Windows returns the top-level windows of the desktop in stacking
+	// order, bottom to top
+	Windows() ([]window.Window, error)
+	// ActiveWindow returns the window holding the input focus
+	ActiveWindow() (window.Window, error)
+	// ActivateWindow raises the window with the given ID and focuses it
+	ActivateWindow(id uint64) error
+	// SetWindowBounds moves the window with the given ID to (x, y) and
+	// resizes it to w by h
+	SetWindowBounds(id uint64, x, y, w, h int) error
+	// SetWindowState minimizes, maximizes or restores the window with the given ID
+	SetWindowState(id uint64, state window.State) error
+	// CloseWindow asks the window with the given ID to close
+	CloseWindow(id uint64) error
+	// ReadClipboard returns the text on the clipboard
+	ReadClipboard() (string, error)
+	// WriteClipboard puts text on the clipboard
+	WriteClipboard(text string) error
+	// RecordInput starts capturing the global mouse and keyboard events
+	RecordInput() (input.Source, error)
+	// TypeStr types the given text at the current focus
+	TypeStr(text string) error
+	// KeyTap taps key while holding the given modifiers
+	KeyTap(key string, modifiers ...string) error
+	// KeyDown presses key and keeps it held until KeyUp
+	KeyDown(key string) error
+	// KeyUp releases a key pressed with KeyDown
+	KeyUp(key string) error
+}
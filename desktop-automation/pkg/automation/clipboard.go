@@ -0,0 +1,87 @@
+// Package automation provides wrappers for the robotgo library
+// to simplify desktop automation tasks
+package automation
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// PasteRestoreDelay is how long Keyboard.Paste waits after the paste chord
+// before restoring the clipboard, so that the target application has read it
+const PasteRestoreDelay = 200 * time.Millisecond
+
+// Clipboard represents clipboard access
+type Clipboard struct {
+	backend Backend
+}
+
+// NewClipboard creates a new clipboard instance for the real desktop
+func NewClipboard() *Clipboard {
+	return NewClipboardWithBackend(NewRobotgoBackend())
+}
+
+// NewClipboardWithBackend creates a new clipboard instance using the given backend
+func NewClipboardWithBackend(backend Backend) *Clipboard {
+	return &Clipboard{backend: backend}
+}
+
+// Get returns the text on the clipboard
+func (c *Clipboard) Get() (string, error) {
+	text, err := c.backend.ReadClipboard()
+	if err != nil {
+		return "", fmt.Errorf("failed to read clipboard: %w", err)
+	}
+
+	return text, nil
+}
+
+// Set puts text on the clipboard
+func (c *Clipboard) Set(text string) error {
+	if err := c.backend.WriteClipboard(text); err != nil {
+		return fmt.Errorf("failed to write clipboard: %w", err)
+	}
+
+	return nil
+}
+
+// PasteChord returns the chord that pastes on this platform: cmd+v on macOS
+// and ctrl+v elsewhere
+func PasteChord() Chord {
+	if runtime.GOOS == "darwin" {
+		return Chord{Modifiers: []string{ModCmd}, Key: "v"}
+	}
+	return Chord{Modifiers: []string{ModCtrl}, Key: "v"}
+}
+
+// Paste enters text by putting it on the clipboard and pressing the paste
+// chord. This is much faster than typing long text and is not limited to the
+// characters of the keyboard layout. If restore is set, the previous
+// clipboard contents are put back after PasteRestoreDelay. A clipboard that
+// is empty or holds something other than text cannot be read and is emptied
+// instead, so the pasted text does not stay on it.
+func (k *Keyboard) Paste(text string, restore bool) (err error) {
+	if text == "" {
+		return fmt.Errorf("cannot paste an empty string")
+	}
+
+	clipboard := NewClipboardWithBackend(k.backend)
+	if restore {
+		// Unreadable contents are restored as an empty clipboard
+		previous, _ := clipboard.Get()
+		defer func() {
+			time.Sleep(PasteRestoreDelay)
+			if restoreErr := clipboard.Set(previous); restoreErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to restore clipboard: %w", restoreErr))
+			}
+		}()
+	}
+
+	if err := clipboard.Set(text); err != nil {
+		return err
+	}
+
+	return k.PressChord(PasteChord())
+}
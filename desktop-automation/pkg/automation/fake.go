@@ -0,0 +1,388 @@
+// Package automation provides wrappers for the robotgo library
+// to simplify desktop automation tasks
+package automation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"slices"
+	"sync"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/display"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/input"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/window"
+)
+
+// Size of the screen simulated by FakeBackend
+const (
+	FakeScreenWidth  = 1920
+	FakeScreenHeight = 1080
+)
+
+// EventKind identifies the type of an event recorded by FakeBackend
+type EventKind string
+
+// Event kinds recorded by FakeBackend
+const (
+	EventMove       EventKind = "move"
+	EventMoveSmooth EventKind = "move_smooth"
+	EventClick      EventKind = "click"
+	EventMouseDown  EventKind = "mouse_down"
+	EventMouseUp    EventKind = "mouse_up"
+	EventScroll     EventKind = "scroll"
+	EventCapture    EventKind = "capture"
+	EventActivate   EventKind = "activate"
+	EventSetBounds  EventKind = "set_bounds"
+	EventSetState   EventKind = "set_state"
+	EventClose      EventKind = "close"
+	EventClipboard  EventKind = "clipboard"
+	EventType       EventKind = "type"
+	EventKeyTap     EventKind = "key_tap"
+	EventKeyDown    EventKind = "key_down"
+	EventKeyUp      EventKind = "key_up"
+)
+
+// Event is a single input event recorded by FakeBackend
+type Event struct {
+	Kind      EventKind
+	X         int
+	Y         int
+	Duration  float64
+	Button    Button
+	Count     int
+	DX        int
+	DY        int
+	Width     int
+	Height    int
+	Window    uint64
+	State     window.State
+	Text      string
+	Key       string
+	Modifiers []string
+}
+
+// FakeBackend is an in-memory Backend that records every event it receives
+// instead of touching the desktop. It is safe for concurrent use.
+type FakeBackend struct {
+	mu       sync.Mutex
+	x, y     int
+	events   []Event
+	displays display.Layout
+	screen   image.Image
+	windows  []window.Window
+	clip     string
+	clipErr  error
+	input    []input.Event
+}
+
+// NewFakeBackend creates a new recording backend with the cursor at (0, 0)
+// on a single FakeScreenWidth by FakeScreenHeight display
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{
+		displays: display.Layout{{Width: FakeScreenWidth, Height: FakeScreenHeight, Primary: true, ScaleFactor: 1}},
+	}
+}
+
+// SetDisplays replaces the simulated display layout
+func (f *FakeBackend) SetDisplays(displays display.Layout) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.displays = slices.Clone(displays)
+}
+
+// SetScreenImage sets the simulated screen contents, with the pixel at
+// screen coordinates (x, y) taken from img.At(x, y). Until it is set the
+// screen shows a gradient.
+func (f *FakeBackend) SetScreenImage(img image.Image) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.screen = img
+}
+
+// SetWindows replaces the simulated top-level windows, listed bottom to top.
+// The window marked Focused, if any, is the active one.
+func (f *FakeBackend) SetWindows(windows ...window.Window) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.windows = slices.Clone(windows)
+}
+
+// SetInputEvents sets the events that RecordInput replays
+func (f *FakeBackend) SetInputEvents(events ...input.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.input = slices.Clone(events)
+}
+
+// Displays returns the simulated display layout
+func (f *FakeBackend) Displays() (display.Layout, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.displays), nil
+}
+
+// Move records a move event and updates the cursor position
+func (f *FakeBackend) Move(x, y int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.x, f.y = x, y
+	f.events = append(f.events, Event{Kind: EventMove, X: x, Y: y})
+	return nil
+}
+
+// MoveSmooth records a smooth move event and updates the cursor position
+func (f *FakeBackend) MoveSmooth(x, y int, duration float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.x, f.y = x, y
+	f.events = append(f.events, Event{Kind: EventMoveSmooth, X: x, Y: y, Duration: duration})
+	return nil
+}
+
+// Click records a click at the current cursor position
+func (f *FakeBackend) Click(button Button, count int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.events = append(f.events, Event{Kind: EventClick, X: f.x, Y: f.y, Button: button, Count: count})
+	return nil
+}
+
+// MouseDown records a button press at the current cursor position
+func (f *FakeBackend) MouseDown(button Button) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.events = append(f.events, Event{Kind: EventMouseDown, X: f.x, Y: f.y, Button: button})
+	return nil
+}
+
+// MouseUp records a button release at the current cursor position
+func (f *FakeBackend) MouseUp(button Button) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.events = append(f.events, Event{Kind: EventMouseUp, X: f.x, Y: f.y, Button: button})
+	return nil
+}
+
+// Scroll records a scroll at the current cursor position
+func (f *FakeBackend) Scroll(dx, dy int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.events = append(f.events, Event{Kind: EventScroll, X: f.x, Y: f.y, DX: dx, DY: dy})
+	return nil
+}
+
+// ScreenSize returns the simulated screen size
+func (f *FakeBackend) ScreenSize() (int, int) {
+	return FakeScreenWidth, FakeScreenHeight
+}
+
+// Capture records a capture and returns the requested part of the simulated screen
+func (f *FakeBackend) Capture(x, y, w, h int) (image.Image, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.events = append(f.events, Event{Kind: EventCapture, X: x, Y: y, Width: w, Height: h})
+
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for py := 0; py < h; py++ {
+		for px := 0; px < w; px++ {
+			if f.screen != nil {
+				img.Set(px, py, f.screen.At(x+px, y+py))
+			} else {
+				img.Set(px, py, color.RGBA{R: uint8(x + px), G: uint8(y + py), B: 128, A: 255})
+			}
+		}
+	}
+	return img, nil
+}
+
+// Windows returns the simulated windows
+func (f *FakeBackend) Windows() ([]window.Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.windows), nil
+}
+
+// ActiveWindow returns the simulated window marked Focused
+func (f *FakeBackend) ActiveWindow() (window.Window, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, w := range f.windows {
+		if w.Focused {
+			return w, nil
+		}
+	}
+	return window.Window{}, fmt.Errorf("no window has the input focus")
+}
+
+// ActivateWindow records an activation and moves the focus to the window
+func (f *FakeBackend) ActivateWindow(id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !slices.ContainsFunc(f.windows, func(w window.Window) bool { return w.ID == id }) {
+		return fmt.Errorf("window %#x not found", id)
+	}
+	for i := range f.windows {
+		f.windows[i].Focused = f.windows[i].ID == id
+	}
+	f.events = append(f.events, Event{Kind: EventActivate, Window: id})
+	return nil
+}
+
+// SetWindowBounds records a move and resize and applies it to the window
+func (f *FakeBackend) SetWindowBounds(id uint64, x, y, w, h int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := slices.IndexFunc(f.windows, func(win window.Window) bool { return win.ID == id })
+	if i < 0 {
+		return fmt.Errorf("window %#x not found", id)
+	}
+	f.windows[i].X, f.windows[i].Y, f.windows[i].Width, f.windows[i].Height = x, y, w, h
+	f.events = append(f.events, Event{Kind: EventSetBounds, Window: id, X: x, Y: y, Width: w, Height: h})
+	return nil
+}
+
+// SetWindowState records a state change and applies it to the window
+func (f *FakeBackend) SetWindowState(id uint64, state window.State) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := slices.IndexFunc(f.windows, func(win window.Window) bool { return win.ID == id })
+	if i < 0 {
+		return fmt.Errorf("window %#x not found", id)
+	}
+	f.windows[i].State = state
+	f.events = append(f.events, Event{Kind: EventSetState, Window: id, State: state})
+	return nil
+}
+
+// CloseWindow records a close and removes the window
+func (f *FakeBackend) CloseWindow(id uint64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := slices.IndexFunc(f.windows, func(win window.Window) bool { return win.ID == id })
+	if i < 0 {
+		return fmt.Errorf("window %#x not found", id)
+	}
+	f.windows = slices.Delete(f.windows, i, i+1)
+	f.events = append(f.events, Event{Kind: EventClose, Window: id})
+	return nil
+}
+
+// Location returns the simulated cursor position
+func (f *FakeBackend) Location() (int, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.x, f.y
+}
+
+// SetClipboardUnreadable makes ReadClipboard fail with err until the
+// clipboard is written, like a clipboard holding an image
+func (f *FakeBackend) SetClipboardUnreadable(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.clipErr = err
+}
+
+// ReadClipboard returns the simulated clipboard contents
+func (f *FakeBackend) ReadClipboard() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.clipErr != nil {
+		return "", f.clipErr
+	}
+	return f.clip, nil
+}
+
+// WriteClipboard records a clipboard write and updates the simulated clipboard
+func (f *FakeBackend) WriteClipboard(text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.clip, f.clipErr = text, nil
+	f.events = append(f.events, Event{Kind: EventClipboard, Text: text})
+	return nil
+}
+
+// RecordInput replays the events set with SetInputEvents
+func (f *FakeBackend) RecordInput() (input.Source, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return input.NewReplay(slices.Clone(f.input)...), nil
+}
+
+// TypeStr records a typing event
+func (f *FakeBackend) TypeStr(text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.events = append(f.events, Event{Kind: EventType, Text: text})
+	return nil
+}
+
+// KeyTap records a key tap with its modifiers
+func (f *FakeBackend) KeyTap(key string, modifiers ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.events = append(f.events, Event{Kind: EventKeyTap, Key: key, Modifiers: append([]string(nil), modifiers...)})
+	return nil
+}
+
+// KeyDown records a key press
+func (f *FakeBackend) KeyDown(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.events = append(f.events, Event{Kind: EventKeyDown, Key: key})
+	return nil
+}
+
+// KeyUp records a key release
+func (f *FakeBackend) KeyUp(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.events = append(f.events, Event{Kind: EventKeyUp, Key: key})
+	return nil
+}
+
+// Events returns a copy of all events recorded so far
+func (f *FakeBackend) Events() []Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	events := make([]Event, len(f.events))
+	copy(events, f.events)
+	return events
+}
+
+// Reset clears the recorded events and moves the simulated cursor to (0, 0)
+func (f *FakeBackend) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.x, f.y = 0, 0
+	f.events = nil
+}
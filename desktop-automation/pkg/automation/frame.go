@@ -0,0 +1,154 @@
+// Package automation provides wrappers for the robotgo library
+// to simplify desktop automation tasks
+package automation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/window"
+)
+
+// FrameKind is the origin that the coordinates of a Frame are measured from
+type FrameKind string
+
+// Frame kinds
+const (
+	// FrameAbsolute measures from the origin of the virtual desktop
+	FrameAbsolute FrameKind = "absolute"
+	// FrameWindow measures from the top left corner of a window
+	FrameWindow FrameKind = "window"
+	// FrameDisplay measures from the top left corner of a display
+	FrameDisplay FrameKind = "display"
+)
+
+// Frame is the coordinate system of a point. The zero Frame is absolute.
+// Relative frames are resolved against the current window and display
+// layout each time they are used, so scripts keep working when a window
+// moves.
+type Frame struct {
+	// Kind selects the origin; FrameAbsolute if empty
+	Kind FrameKind
+	// Window picks the window of a FrameWindow. The first matching window is used.
+	Window window.Selector
+	// Display is the ID of the display of a FrameDisplay, or negative for the
+	// primary display
+	Display int
+}
+
+// ParseFrame builds a frame from its kind name and the window or display it
+// is relative to. A negative displayID means no display was given, which a
+// display frame takes as the primary display. Without a kind the frame is
+// inferred: a window title or pid selects the window frame, a display ID the
+// display frame, and nothing the absolute frame.
+func ParseFrame(kind string, title string, pid int, displayID int) (Frame, error) {
+	hasWindow := title != "" || pid != 0
+	hasDisplay := displayID >= 0
+
+	k := FrameKind(strings.ToLower(strings.TrimSpace(kind)))
+	if k == "" {
+		switch {
+		case hasWindow && hasDisplay:
+			return Frame{}, fmt.Errorf("coordinates cannot be relative to both a window and a display")
+		case hasWindow:
+			k = FrameWindow
+		case hasDisplay:
+			k = FrameDisplay
+		default:
+			k = FrameAbsolute
+		}
+	}
+
+	switch k {
+	case FrameAbsolute:
+		if hasWindow || hasDisplay {
+			return Frame{}, fmt.Errorf("a window or display cannot be given for the absolute frame")
+		}
+		return Frame{Kind: FrameAbsolute}, nil
+	case FrameWindow:
+		if !hasWindow {
+			return Frame{}, fmt.Errorf("the window frame requires a window title or pid")
+		}
+		if hasDisplay {
+			return Frame{}, fmt.Errorf("a display cannot be given for the window frame")
+		}
+		sel, err := window.NewSelector(title, pid)
+		if err != nil {
+			return Frame{}, err
+		}
+		return Frame{Kind: FrameWindow, Window: sel}, nil
+	case FrameDisplay:
+		if hasWindow {
+			return Frame{}, fmt.Errorf("a window title or pid cannot be given for the display frame")
+		}
+		return Frame{Kind: FrameDisplay, Display: displayID}, nil
+	}
+
+	return Frame{}, fmt.Errorf("unknown frame %q (must be absolute, window or display)", kind)
+}
+
+// IsAbsolute reports whether the frame uses screen coordinates as they are
+func (f Frame) IsAbsolute() bool {
+	return f.Kind == "" || f.Kind == FrameAbsolute
+}
+
+// String describes the frame, e.g. `window title ~ "Editor"` or "display 1"
+func (f Frame) String() string {
+	switch f.Kind {
+	case FrameWindow:
+		return "window " + f.Window.String()
+	case FrameDisplay:
+		if f.Display < 0 {
+			return "primary display"
+		}
+		return fmt.Sprintf("display %d", f.Display)
+	}
+	return "screen"
+}
+
+// FormatPoint describes the screen point (sx, sy) that (x, y) in the frame
+// resolved to, e.g. "(510, 320)" or `(510, 320) [(10, 20) in window title ~ "Editor"]`
+func (f Frame) FormatPoint(x, y, sx, sy int) string {
+	if f.IsAbsolute() {
+		return fmt.Sprintf("(%d, %d)", sx, sy)
+	}
+	return fmt.Sprintf("(%d, %d) [(%d, %d) in %s]", sx, sy, x, y, f)
+}
+
+// Resolve converts (x, y) in frame to screen coordinates
+func (m *Mouse) Resolve(frame Frame, x, y int) (int, int, error) {
+	switch frame.Kind {
+	case "", FrameAbsolute:
+		return x, y, nil
+	case FrameWindow:
+		windows, err := m.backend.Windows()
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to list windows: %w", err)
+		}
+		matched := window.Filter(windows, frame.Window)
+		if len(matched) == 0 {
+			return 0, 0, fmt.Errorf("no window matches %s", frame.Window)
+		}
+		return matched[0].X + x, matched[0].Y + y, nil
+	case FrameDisplay:
+		layout, err := m.backend.Displays()
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to list displays: %w", err)
+		}
+		if frame.Display < 0 {
+			d, ok := layout.Primary()
+			if !ok {
+				return 0, 0, fmt.Errorf("no displays found")
+			}
+			return d.X + x, d.Y + y, nil
+		}
+		for _, d := range layout {
+			if d.ID == frame.Display {
+				return d.X + x, d.Y + y, nil
+			}
+		}
+		return 0, 0, fmt.Errorf("display %d not found (displays: %s)", frame.Display, layout)
+	}
+
+	return 0, 0, fmt.Errorf("unknown frame %q (must be absolute, window or display)", frame.Kind)
+}
@@ -0,0 +1,280 @@
+// Package automation provides wrappers for the robotgo library
+// to simplify desktop automation tasks
+package automation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Keyboard represents keyboard automation functionality. It keeps track of
+// the keys held with KeyDown so that they can be released reliably. Keys may
+// be held on behalf of an owner, such as an MCP session, so that one owner
+// cannot release the keys of another and all keys of an owner can be
+// released when it goes away.
+type Keyboard struct {
+	backend Backend
+
+	mu   sync.Mutex
+	held []heldKey
+}
+
+// heldKey is a key held by an owner. A key held by several owners is pressed
+// by the first and released by the last.
+type heldKey struct {
+	owner string
+	key   string
+}
+
+// NewKeyboard creates a new keyboard automation instance backed by robotgo
+func NewKeyboard() *Keyboard {
+	return NewKeyboardWithBackend(NewRobotgoBackend())
+}
+
+// NewKeyboardWithBackend creates a new keyboard automation instance using the given backend
+func NewKeyboardWithBackend(backend Backend) *Keyboard {
+	return &Keyboard{backend: backend}
+}
+
+// Type simulates typing the given text
+func (k *Keyboard) Type(text string) error {
+	return k.TypeString(text)
+}
+
+// Hotkey simulates pressing a keyboard shortcut. The keys are either a single
+// chord such as "ctrl+shift+t" or the individual keys of one chord, e.g.
+// Hotkey("ctrl", "c").
+func (k *Keyboard) Hotkey(keys ...string) error {
+	if len(keys) == 0 {
+		return fmt.Errorf("no keys given")
+	}
+
+	chord, err := ParseChord(strings.Join(keys, "+"))
+	if err != nil {
+		return err
+	}
+
+	return k.PressChord(chord)
+}
+
+// PressChord taps the chord's key while holding its modifiers
+func (k *Keyboard) PressChord(chord Chord) error {
+	if err := k.backend.KeyTap(chord.Key, chord.Modifiers...); err != nil {
+		return fmt.Errorf("failed to press %s: %w", chord, err)
+	}
+
+	return nil
+}
+
+// KeyDown presses key and keeps it held until KeyUp or ReleaseAll. Pressing a
+// key that is already held does nothing.
+func (k *Keyboard) KeyDown(key string) error {
+	_, err := k.keyDown("", key)
+	return err
+}
+
+// KeyDownFor presses key on behalf of owner and keeps it held until owner
+// releases it with KeyUpFor or ReleaseFor
+func (k *Keyboard) KeyDownFor(owner, key string) error {
+	_, err := k.keyDown(owner, key)
+	return err
+}
+
+// keyDown presses key for owner unless owner already holds it and reports
+// whether it did. A key another owner holds is not pressed again.
+func (k *Keyboard) keyDown(owner, key string) (bool, error) {
+	name, err := LookupKey(strings.TrimSpace(key))
+	if err != nil {
+		return false, err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if slices.Contains(k.held, heldKey{owner, name}) {
+		return false, nil
+	}
+	if !k.isDown(name) {
+		if err := k.backend.KeyDown(name); err != nil {
+			return false, fmt.Errorf("failed to press %s: %w", name, err)
+		}
+	}
+	k.held = append(k.held, heldKey{owner, name})
+
+	return true, nil
+}
+
+// KeyUp releases a key held with KeyDown
+func (k *Keyboard) KeyUp(key string) error {
+	return k.KeyUpFor("", key)
+}
+
+// KeyUpFor releases a key owner holds. The key stays down while other owners
+// hold it.
+func (k *Keyboard) KeyUpFor(owner, key string) error {
+	name, err := LookupKey(strings.TrimSpace(key))
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	i := slices.Index(k.held, heldKey{owner, name})
+	if i < 0 {
+		return fmt.Errorf("key %q is not held", name)
+	}
+	k.held = slices.Delete(k.held, i, i+1)
+	if k.isDown(name) {
+		return nil
+	}
+	if err := k.backend.KeyUp(name); err != nil {
+		k.held = slices.Insert(k.held, i, heldKey{owner, name})
+		return fmt.Errorf("failed to release %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// isDown reports whether any owner holds key. k.mu must be held.
+func (k *Keyboard) isDown(key string) bool {
+	return slices.ContainsFunc(k.held, func(h heldKey) bool { return h.key == key })
+}
+
+// HeldKeys returns the keys currently held by any owner, in the order they
+// were pressed
+func (k *Keyboard) HeldKeys() []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	var keys []string
+	for _, h := range k.held {
+		if !slices.Contains(keys, h.key) {
+			keys = append(keys, h.key)
+		}
+	}
+	return keys
+}
+
+// HeldKeysFor returns the keys owner holds, in the order they were pressed
+func (k *Keyboard) HeldKeysFor(owner string) []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	var keys []string
+	for _, h := range k.held {
+		if h.owner == owner {
+			keys = append(keys, h.key)
+		}
+	}
+	return keys
+}
+
+// ReleaseAll releases every held key of every owner in reverse order of
+// pressing. Keys are forgotten even if releasing them fails, so a broken
+// backend cannot leave them stuck in the list.
+func (k *Keyboard) ReleaseAll() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.release(func(heldKey) bool { return true })
+}
+
+// ReleaseFor releases every key owner holds in reverse order of pressing,
+// leaving down the keys other owners still hold. Like ReleaseAll it forgets
+// the keys even if releasing them fails.
+func (k *Keyboard) ReleaseFor(owner string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.release(func(h heldKey) bool { return h.owner == owner })
+}
+
+// release forgets the held keys that match and releases those no other
+// owner holds, newest first. k.mu must be held.
+func (k *Keyboard) release(match func(heldKey) bool) error {
+	var errs []error
+	for i := len(k.held) - 1; i >= 0; i-- {
+		h := k.held[i]
+		if !match(h) {
+			continue
+		}
+		k.held = slices.Delete(k.held, i, i+1)
+		if k.isDown(h.key) {
+			continue
+		}
+		if err := k.backend.KeyUp(h.key); err != nil {
+			errs = append(errs, fmt.Errorf("failed to release %s: %w", h.key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Hold presses keys, runs fn and releases the keys again, even if fn fails or
+// panics. Keys that were already held stay held. fn is not run if ctx is done
+// before all keys are down.
+func (k *Keyboard) Hold(ctx context.Context, keys []string, fn func() error) (err error) {
+	var pressed []string
+	defer func() {
+		for i := len(pressed) - 1; i >= 0; i-- {
+			if upErr := k.KeyUp(pressed[i]); upErr != nil {
+				err = errors.Join(err, upErr)
+			}
+		}
+	}()
+
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		ok, err := k.keyDown("", key)
+		if err != nil {
+			return err
+		}
+		if ok {
+			pressed = append(pressed, key)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return fn()
+}
+
+// TypeString types the given text
+func (k *Keyboard) TypeString(text string) error {
+	if text == "" {
+		return fmt.Errorf("cannot type an empty string")
+	}
+
+	return k.backend.TypeStr(text)
+}
+
+// TypeStringWithDelay types the given text with a delay between keystrokes
+func (k *Keyboard) TypeStringWithDelay(text string, delayMs int) error {
+	if text == "" {
+		return fmt.Errorf("cannot type an empty string")
+	}
+
+	if delayMs <= 0 {
+		// If no delay or invalid delay, use regular typing
+		return k.TypeString(text)
+	}
+
+	// Type each character with delay
+	for _, char := range text {
+		if err := k.backend.TypeStr(string(char)); err != nil {
+			return err
+		}
+		time.Sleep(time.Duration(delayMs) * time.Millisecond)
+	}
+
+	return nil
+}
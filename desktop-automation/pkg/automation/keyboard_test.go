@@ -0,0 +1,76 @@
+package automation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestKeyOwners(t *testing.T) {
+	backend := NewFakeBackend()
+	k := NewKeyboardWithBackend(backend)
+
+	// Two owners share shift, which is pressed once
+	for _, step := range []struct{ owner, key string }{{"a", "shift"}, {"b", "shift"}, {"b", "ctrl"}, {"a", "alt"}} {
+		if err := k.KeyDownFor(step.owner, step.key); err != nil {
+			t.Fatalf("KeyDownFor(%s, %s) failed: %v", step.owner, step.key, err)
+		}
+	}
+	if got, want := k.HeldKeys(), []string{"shift", "ctrl", "alt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("HeldKeys() = %v, want %v", got, want)
+	}
+	if got, want := k.HeldKeysFor("b"), []string{"shift", "ctrl"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("HeldKeysFor(b) = %v, want %v", got, want)
+	}
+
+	// An owner cannot release the keys of another
+	if err := k.KeyUpFor("b", "alt"); err == nil {
+		t.Error("KeyUpFor(b, alt) succeeded, want an error")
+	}
+
+	// Shift stays down while b still holds it
+	if err := k.ReleaseFor("a"); err != nil {
+		t.Fatalf("ReleaseFor(a) failed: %v", err)
+	}
+	if err := k.KeyUpFor("b", "shift"); err != nil {
+		t.Fatalf("KeyUpFor(b, shift) failed: %v", err)
+	}
+	if err := k.ReleaseAll(); err != nil {
+		t.Fatalf("ReleaseAll() failed: %v", err)
+	}
+
+	want := []Event{
+		{Kind: EventKeyDown, Key: "shift"},
+		{Kind: EventKeyDown, Key: "ctrl"},
+		{Kind: EventKeyDown, Key: "alt"},
+		{Kind: EventKeyUp, Key: "alt"},
+		{Kind: EventKeyUp, Key: "shift"},
+		{Kind: EventKeyUp, Key: "ctrl"},
+	}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+	if held := k.HeldKeys(); len(held) != 0 {
+		t.Errorf("HeldKeys() = %v after ReleaseAll, want none", held)
+	}
+}
+
+func TestPasteRestoreUnreadable(t *testing.T) {
+	backend := NewFakeBackend()
+	backend.SetClipboardUnreadable(errors.New("no text on the clipboard"))
+	k := NewKeyboardWithBackend(backend)
+
+	if err := k.Paste("secret", true); err != nil {
+		t.Fatalf("Paste() failed: %v", err)
+	}
+
+	// The clipboard is emptied instead of keeping the pasted text
+	want := []Event{
+		{Kind: EventClipboard, Text: "secret"},
+		{Kind: EventKeyTap, Key: "v", Modifiers: PasteChord().Modifiers},
+		{Kind: EventClipboard, Text: ""},
+	}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,163 @@
+// Package automation provides wrappers for the robotgo library
+// to simplify desktop automation tasks
+package automation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Modifier keys in their canonical form
+const (
+	ModCtrl  = "ctrl"
+	ModShift = "shift"
+	ModAlt   = "alt"
+	ModCmd   = "cmd"
+)
+
+// modifierNames maps accepted modifier spellings to their canonical form
+var modifierNames = map[string]string{
+	"ctrl":    ModCtrl,
+	"control": ModCtrl,
+	"shift":   ModShift,
+	"alt":     ModAlt,
+	"option":  ModAlt,
+	"opt":     ModAlt,
+	"cmd":     ModCmd,
+	"command": ModCmd,
+	"super":   ModCmd,
+	"win":     ModCmd,
+	"meta":    ModCmd,
+}
+
+// keyNames maps accepted named keys to the names understood by robotgo
+var keyNames = map[string]string{
+	"enter":        "enter",
+	"return":       "enter",
+	"tab":          "tab",
+	"space":        "space",
+	"backspace":    "backspace",
+	"delete":       "delete",
+	"del":          "delete",
+	"insert":       "insert",
+	"esc":          "esc",
+	"escape":       "esc",
+	"up":           "up",
+	"down":         "down",
+	"left":         "left",
+	"right":        "right",
+	"home":         "home",
+	"end":          "end",
+	"pageup":       "pageup",
+	"pagedown":     "pagedown",
+	"capslock":     "capslock",
+	"printscreen":  "printscreen",
+	"menu":         "menu",
+	"minus":        "-",
+	"plus":         "+",
+	"equal":        "=",
+	"comma":        ",",
+	"period":       ".",
+	"slash":        "/",
+	"backslash":    "\\",
+	"semicolon":    ";",
+	"quote":        "'",
+	"backquote":    "`",
+	"bracketleft":  "[",
+	"bracketright": "]",
+}
+
+// punctuationKeys are the single character keys that can be used directly in a chord
+const punctuationKeys = "-=+,./\\;'`[]"
+
+func init() {
+	for i := 1; i <= 24; i++ {
+		name := fmt.Sprintf("f%d", i)
+		keyNames[name] = name
+	}
+}
+
+// Chord is a key combination such as ctrl+shift+t
+type Chord struct {
+	// Modifiers are the canonical modifier keys held while Key is pressed
+	Modifiers []string
+	// Key is the robotgo name of the key that is tapped
+	Key string
+}
+
+// String returns the chord in its canonical "mod+mod+key" form
+func (c Chord) String() string {
+	return strings.Join(append(append([]string{}, c.Modifiers...), c.Key), "+")
+}
+
+// ParseChord parses a chord such as "ctrl+shift+t", "cmd+space" or "alt+F4".
+// Key names are case-insensitive; every part but the last must be a modifier.
+// A chord consisting of a single modifier taps that modifier on its own. The
+// plus key is written as "plus" or as a trailing "+", as in "ctrl++".
+func ParseChord(chord string) (Chord, error) {
+	trimmed := strings.TrimSpace(chord)
+	if trimmed == "" {
+		return Chord{}, fmt.Errorf("empty key chord")
+	}
+	parts := strings.Split(trimmed, "+")
+	switch {
+	case trimmed == "+":
+		parts = []string{"+"}
+	case strings.HasSuffix(trimmed, "++"):
+		parts = append(strings.Split(strings.TrimSuffix(trimmed, "++"), "+"), "+")
+	}
+
+	var c Chord
+	seen := make(map[string]bool)
+	for i, part := range parts {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" {
+			return Chord{}, fmt.Errorf("invalid key chord %q: empty key name", chord)
+		}
+
+		if i == len(parts)-1 {
+			key, err := LookupKey(name)
+			if err != nil {
+				return Chord{}, fmt.Errorf("invalid key chord %q: %w", chord, err)
+			}
+			c.Key = key
+			break
+		}
+
+		mod, ok := modifierNames[name]
+		if !ok {
+			if _, err := LookupKey(name); err == nil {
+				return Chord{}, fmt.Errorf("invalid key chord %q: %q is not a modifier (only the last key may be a non-modifier)", chord, part)
+			}
+			return Chord{}, fmt.Errorf("invalid key chord %q: unknown modifier %q", chord, part)
+		}
+		if seen[mod] {
+			return Chord{}, fmt.Errorf("invalid key chord %q: modifier %q repeated", chord, part)
+		}
+		seen[mod] = true
+		c.Modifiers = append(c.Modifiers, mod)
+	}
+
+	return c, nil
+}
+
+// LookupKey validates a single key name and returns its robotgo name.
+// Letters, digits, common punctuation, named keys and modifiers are accepted.
+func LookupKey(name string) (string, error) {
+	name = strings.ToLower(name)
+
+	if mod, ok := modifierNames[name]; ok {
+		return mod, nil
+	}
+	if key, ok := keyNames[name]; ok {
+		return key, nil
+	}
+	if len(name) == 1 {
+		ch := name[0]
+		if (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') || strings.IndexByte(punctuationKeys, ch) >= 0 {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown key %q", name)
+}
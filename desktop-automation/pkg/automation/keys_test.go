@@ -0,0 +1,115 @@
+package automation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseChord(t *testing.T) {
+	tests := []struct {
+		chord   string
+		want    Chord
+		wantErr string
+	}{
+		{chord: "a", want: Chord{Key: "a"}},
+		{chord: "ctrl+c", want: Chord{Modifiers: []string{ModCtrl}, Key: "c"}},
+		{chord: "ctrl+shift+t", want: Chord{Modifiers: []string{ModCtrl, ModShift}, Key: "t"}},
+		{chord: "cmd+space", want: Chord{Modifiers: []string{ModCmd}, Key: "space"}},
+		{chord: "Alt+F4", want: Chord{Modifiers: []string{ModAlt}, Key: "f4"}},
+		{chord: " CTRL + Shift + Esc ", want: Chord{Modifiers: []string{ModCtrl, ModShift}, Key: "esc"}},
+		{chord: "control+option+delete", want: Chord{Modifiers: []string{ModCtrl, ModAlt}, Key: "delete"}},
+		{chord: "super+return", want: Chord{Modifiers: []string{ModCmd}, Key: "enter"}},
+		{chord: "win+l", want: Chord{Modifiers: []string{ModCmd}, Key: "l"}},
+		{chord: "ctrl+minus", want: Chord{Modifiers: []string{ModCtrl}, Key: "-"}},
+		{chord: "ctrl+/", want: Chord{Modifiers: []string{ModCtrl}, Key: "/"}},
+		{chord: "shift", want: Chord{Key: ModShift}},
+		{chord: "ctrl+plus", want: Chord{Modifiers: []string{ModCtrl}, Key: "+"}},
+		{chord: "ctrl++", want: Chord{Modifiers: []string{ModCtrl}, Key: "+"}},
+		{chord: "ctrl+shift++", want: Chord{Modifiers: []string{ModCtrl, ModShift}, Key: "+"}},
+		{chord: "+", want: Chord{Key: "+"}},
+		{chord: "", wantErr: "empty key chord"},
+		{chord: "   ", wantErr: "empty key chord"},
+		{chord: "ctrl+", wantErr: "empty key name"},
+		{chord: "+c", wantErr: "empty key name"},
+		{chord: "ctrl++c", wantErr: "empty key name"},
+		{chord: "++", wantErr: "empty key name"},
+		{chord: "ctrl+foo", wantErr: `unknown key "foo"`},
+		{chord: "hyper+c", wantErr: `unknown modifier "hyper"`},
+		{chord: "a+b", wantErr: `"a" is not a modifier`},
+		{chord: "ctrl+control+c", wantErr: "repeated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chord, func(t *testing.T) {
+			got, err := ParseChord(tt.chord)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseChord(%q) error = %v, want %q", tt.chord, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseChord(%q) failed: %v", tt.chord, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseChord(%q) = %+v, want %+v", tt.chord, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChordString(t *testing.T) {
+	for chord, want := range map[string]string{
+		"Control+Shift+T": "ctrl+shift+t",
+		"ctrl++":          "ctrl++",
+		"escape":          "esc",
+	} {
+		c, err := ParseChord(chord)
+		if err != nil {
+			t.Fatalf("ParseChord(%q) failed: %v", chord, err)
+		}
+		if got := c.String(); got != want {
+			t.Errorf("ParseChord(%q).String() = %q, want %q", chord, got, want)
+		}
+		// The canonical form parses back to the same chord
+		if again, err := ParseChord(c.String()); err != nil || !reflect.DeepEqual(again, c) {
+			t.Errorf("ParseChord(%q) = %+v, %v, want %+v", c.String(), again, err, c)
+		}
+	}
+}
+
+func TestLookupKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "a", want: "a"},
+		{name: "Z", want: "z"},
+		{name: "7", want: "7"},
+		{name: "ENTER", want: "enter"},
+		{name: "pageDown", want: "pagedown"},
+		{name: "f24", want: "f24"},
+		{name: "bracketleft", want: "["},
+		{name: "+", want: "+"},
+		{name: "plus", want: "+"},
+		{name: "opt", want: ModAlt},
+		{name: "meta", want: ModCmd},
+		{name: "f25", wantErr: true},
+		{name: "é", wantErr: true},
+		{name: "!", wantErr: true},
+		{name: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := LookupKey(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LookupKey(%q) error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("LookupKey(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// Package automation provides wrappers for the robotgo library
+// to simplify desktop automation tasks
+package automation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+	"time"
+)
+
+// Button is a mouse button
+type Button string
+
+// Mouse buttons
+const (
+	ButtonLeft   Button = "left"
+	ButtonRight  Button = "right"
+	ButtonMiddle Button = "middle"
+)
+
+// MaxClickCount is the largest number of clicks ClickButton issues at once
+const MaxClickCount = 3
+
+// ParseButton parses a button name such as "left", "right" or "middle".
+// An empty name selects the left button.
+func ParseButton(name string) (Button, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "left":
+		return ButtonLeft, nil
+	case "right":
+		return ButtonRight, nil
+	case "middle", "center":
+		return ButtonMiddle, nil
+	}
+
+	return "", fmt.Errorf("unknown mouse button %q (must be left, right or middle)", name)
+}
+
+// Mouse represents mouse automation functionality
+type Mouse struct {
+	backend Backend
+}
+
+// NewMouse creates a new mouse automation instance backed by robotgo
+func NewMouse() *Mouse {
+	return NewMouseWithBackend(NewRobotgoBackend())
+}
+
+// NewMouseWithBackend creates a new mouse automation instance using the given backend
+func NewMouseWithBackend(backend Backend) *Mouse {
+	return &Mouse{backend: backend}
+}
+
+// Move moves the mouse instantly to the specified coordinates
+func (m *Mouse) Move(x, y int) error {
+	if err := m.checkPoint(x, y); err != nil {
+		return err
+	}
+
+	return m.backend.Move(x, y)
+}
+
+// SmoothMove moves the mouse smoothly to the specified coordinates over the given duration
+func (m *Mouse) SmoothMove(x, y int, duration float64) error {
+	if err := m.checkPoint(x, y); err != nil {
+		return err
+	}
+
+	if duration <= 0 {
+		return fmt.Errorf("invalid duration: %f (must be positive)", duration)
+	}
+
+	return m.backend.MoveSmooth(x, y, duration)
+}
+
+// MoveTo moves the mouse to the specified coordinates (legacy method)
+func (m *Mouse) MoveTo(x, y int) error {
+	return m.Move(x, y)
+}
+
+// Click performs a left mouse click at the specified coordinates
+func (m *Mouse) Click(x, y int) error {
+	return m.ClickButton(x, y, ButtonLeft, 1)
+}
+
+// DoubleClick performs a left double click at the specified coordinates
+func (m *Mouse) DoubleClick(x, y int) error {
+	return m.ClickButton(x, y, ButtonLeft, 2)
+}
+
+// TripleClick performs a left triple click at the specified coordinates
+func (m *Mouse) TripleClick(x, y int) error {
+	return m.ClickButton(x, y, ButtonLeft, 3)
+}
+
+// ClickButton clicks button count times in a row at the specified coordinates,
+// so that a count of 2 is a double click and 3 a triple click
+func (m *Mouse) ClickButton(x, y int, button Button, count int) error {
+	if err := m.checkPoint(x, y); err != nil {
+		return err
+	}
+
+	if count < 1 || count > MaxClickCount {
+		return fmt.Errorf("invalid click count: %d (must be between 1 and %d)", count, MaxClickCount)
+	}
+
+	button, err := ParseButton(string(button))
+	if err != nil {
+		return err
+	}
+
+	// Move to position first; the point was checked above
+	if err := m.backend.Move(x, y); err != nil {
+		return err
+	}
+
+	// Perform the click
+	return m.backend.Click(button, count)
+}
+
+// MouseDown presses button at the current cursor position and keeps it held
+// until MouseUp
+func (m *Mouse) MouseDown(button Button) error {
+	button, err := ParseButton(string(button))
+	if err != nil {
+		return err
+	}
+
+	return m.backend.MouseDown(button)
+}
+
+// MouseUp releases a button pressed with MouseDown
+func (m *Mouse) MouseUp(button Button) error {
+	button, err := ParseButton(string(button))
+	if err != nil {
+		return err
+	}
+
+	return m.backend.MouseUp(button)
+}
+
+// DefaultDragSteps is the number of intermediate moves of a smooth drag when
+// DragOptions.Steps is not set
+const DefaultDragSteps = 20
+
+// DragOptions controls how Drag moves between its end points
+type DragOptions struct {
+	// Button is the button held during the drag; the left button by default
+	Button Button
+	// Smooth moves the cursor in Steps intermediate moves spread over Duration
+	// instead of jumping straight to the target
+	Smooth bool
+	// Duration is the length of a smooth drag in seconds
+	Duration float64
+	// Steps is the number of intermediate moves of a smooth drag
+	Steps int
+}
+
+// Drag presses the button at (fromX, fromY), moves to (toX, toY) and releases
+// it there. A smooth drag stops between steps once ctx is done. The button is
+// released even if moving fails or the drag is canceled.
+func (m *Mouse) Drag(ctx context.Context, fromX, fromY, toX, toY int, opts DragOptions) (err error) {
+	if err := m.checkPoint(fromX, fromY); err != nil {
+		return err
+	}
+	if err := m.checkPoint(toX, toY); err != nil {
+		return err
+	}
+
+	button, err := ParseButton(string(opts.Button))
+	if err != nil {
+		return err
+	}
+
+	if opts.Smooth {
+		if opts.Duration <= 0 {
+			return fmt.Errorf("invalid duration: %f (must be positive)", opts.Duration)
+		}
+		if opts.Steps < 0 {
+			return fmt.Errorf("invalid steps: %d (must be positive)", opts.Steps)
+		}
+	}
+	path := DragPath(fromX, fromY, toX, toY, opts)
+
+	if err := m.backend.Move(fromX, fromY); err != nil {
+		return err
+	}
+	if err := m.backend.MouseDown(button); err != nil {
+		return fmt.Errorf("failed to press %s button: %w", button, err)
+	}
+	defer func() {
+		if upErr := m.backend.MouseUp(button); upErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to release %s button: %w", button, upErr))
+		}
+	}()
+
+	interval := time.Duration(opts.Duration * float64(time.Second) / float64(len(path)))
+	for _, pt := range path {
+		if err := m.backend.Move(pt.X, pt.Y); err != nil {
+			return err
+		}
+		if !opts.Smooth {
+			continue
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// DragPath returns the points that Drag moves the cursor to while the button
+// is held, ending with (toX, toY). A drag that is not smooth jumps straight
+// to the target.
+func DragPath(fromX, fromY, toX, toY int, opts DragOptions) []image.Point {
+	steps := 1
+	if opts.Smooth {
+		steps = opts.Steps
+		if steps <= 0 {
+			steps = DefaultDragSteps
+		}
+	}
+
+	path := make([]image.Point, steps)
+	for i := range path {
+		path[i] = image.Pt(fromX+(toX-fromX)*(i+1)/steps, fromY+(toY-fromY)*(i+1)/steps)
+	}
+	return path
+}
+
+// checkPoint reports an error unless (x, y) lies on one of the displays
+func (m *Mouse) checkPoint(x, y int) error {
+	layout, err := m.backend.Displays()
+	if err != nil {
+		return fmt.Errorf("failed to list displays: %w", err)
+	}
+
+	if !layout.Contains(x, y) {
+		return fmt.Errorf("invalid coordinates: x=%d, y=%d (outside every display: %s)", x, y, layout)
+	}
+
+	return nil
+}
+
+// GetPosition returns the current mouse cursor position
+func (m *Mouse) GetPosition() (int, int) {
+	x, y := m.backend.Location()
+	return x, y
+}
@@ -0,0 +1,86 @@
+package automation
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDragCanceled(t *testing.T) {
+	backend := NewFakeBackend()
+	mouse := NewMouseWithBackend(backend)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := mouse.Drag(ctx, 0, 0, 100, 100, DragOptions{Smooth: true, Duration: 60, Steps: 10})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drag() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Drag() took %s after cancellation", elapsed)
+	}
+
+	// The drag stopped after its first step and still released the button
+	want := []Event{
+		{Kind: EventMove, X: 0, Y: 0},
+		{Kind: EventMouseDown, X: 0, Y: 0, Button: ButtonLeft},
+		{Kind: EventMove, X: 10, Y: 10},
+		{Kind: EventMouseUp, X: 10, Y: 10, Button: ButtonLeft},
+	}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
+
+func TestScrollCanceled(t *testing.T) {
+	backend := NewFakeBackend()
+	mouse := NewMouseWithBackend(backend)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := mouse.Scroll(ctx, 0, 10, ScrollOptions{Smooth: true, Duration: 60})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Scroll() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The scroll stopped after its first notch
+	want := []Event{{Kind: EventScroll, DY: 1}}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
+
+func TestScrollAtInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		dx, dy int
+		opts   ScrollOptions
+	}{
+		{name: "zero amount"},
+		{name: "too many notches", dy: MaxScrollNotches + 1},
+		{name: "too many pixels", dx: -(MaxScrollNotches + 1) * PixelsPerNotch, opts: ScrollOptions{Unit: ScrollPixels}},
+		{name: "unknown unit", dy: 1, opts: ScrollOptions{Unit: "lines"}},
+		{name: "no duration", dy: 1, opts: ScrollOptions{Smooth: true}},
+		{name: "too long", dy: 1, opts: ScrollOptions{Smooth: true, Duration: MaxScrollDuration + 1}},
+		{name: "NaN duration", dy: 1, opts: ScrollOptions{Smooth: true, Duration: math.NaN()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := NewFakeBackend()
+			mouse := NewMouseWithBackend(backend)
+
+			if err := mouse.ScrollAt(context.Background(), 10, 20, tt.dx, tt.dy, tt.opts); err == nil {
+				t.Fatal("ScrollAt() succeeded, want an error")
+			}
+			// The pointer was not moved
+			if got := backend.Events(); len(got) != 0 {
+				t.Errorf("events = %+v, want none", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+//go:build !cgo
+
+// Package automation provides wrappers for the robotgo library
+// to simplify desktop automation tasks
+package automation
+
+import (
+	"image"
+
+	"github.com/go-vgo/robotgo/clipboard"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/display"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/input"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/window"
+)
+
+// RobotgoBackend stands in for the robotgo backend, which needs cgo. Every
+// input and capture action fails with ErrUnsupported; window control, the
+// clipboard and input recording do not need cgo and still work. Use
+// FakeBackend to run without a desktop.
+type RobotgoBackend struct{}
+
+// NewRobotgoBackend creates a backend that reports ErrUnsupported
+func NewRobotgoBackend() *RobotgoBackend {
+	return &RobotgoBackend{}
+}
+
+// Move returns ErrUnsupported
+func (b *RobotgoBackend) Move(x, y int) error {
+	return ErrUnsupported
+}
+
+// MoveSmooth returns ErrUnsupported
+func (b *RobotgoBackend) MoveSmooth(x, y int, duration float64) error {
+	return ErrUnsupported
+}
+
+// Click returns ErrUnsupported
+func (b *RobotgoBackend) Click(button Button, count int) error {
+	return ErrUnsupported
+}
+
+// MouseDown returns ErrUnsupported
+func (b *RobotgoBackend) MouseDown(button Button) error {
+	return ErrUnsupported
+}
+
+// MouseUp returns ErrUnsupported
+func (b *RobotgoBackend) MouseUp(button Button) error {
+	return ErrUnsupported
+}
+
+// Scroll returns ErrUnsupported
+func (b *RobotgoBackend) Scroll(dx, dy int) error {
+	return ErrUnsupported
+}
+
+// Location returns the origin as the cursor cannot be located
+func (b *RobotgoBackend) Location() (int, int) {
+	return 0, 0
+}
+
+// Displays returns ErrUnsupported
+func (b *RobotgoBackend) Displays() (display.Layout, error) {
+	return nil, ErrUnsupported
+}
+
+// ScreenSize returns zero as the screen cannot be measured
+func (b *RobotgoBackend) ScreenSize() (int, int) {
+	return 0, 0
+}
+
+// Capture returns ErrUnsupported
+func (b *RobotgoBackend) Capture(x, y, w, h int) (image.Image, error) {
+	return nil, ErrUnsupported
+}
+
+// Windows returns the top-level windows of the desktop
+func (b *RobotgoBackend) Windows() ([]window.Window, error) {
+	return window.List()
+}
+
+// ActiveWindow returns the window holding the input focus
+func (b *RobotgoBackend) ActiveWindow() (window.Window, error) {
+	return window.Active()
+}
+
+// ActivateWindow raises and focuses the window with the given ID
+func (b *RobotgoBackend) ActivateWindow(id uint64) error {
+	return window.Activate(id)
+}
+
+// SetWindowBounds moves and resizes the window with the given ID
+func (b *RobotgoBackend) SetWindowBounds(id uint64, x, y, w, h int) error {
+	return window.SetBounds(id, x, y, w, h)
+}
+
+// SetWindowState minimizes, maximizes or restores the window with the given ID
+func (b *RobotgoBackend) SetWindowState(id uint64, state window.State) error {
+	return window.SetState(id, state)
+}
+
+// CloseWindow asks the window with the given ID to close
+func (b *RobotgoBackend) CloseWindow(id uint64) error {
+	return window.Close(id)
+}
+
+// ReadClipboard returns the text on the clipboard
+func (b *RobotgoBackend) ReadClipboard() (string, error) {
+	return clipboard.ReadAll()
+}
+
+// WriteClipboard puts text on the clipboard
+func (b *RobotgoBackend) WriteClipboard(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+// RecordInput starts capturing the global input events
+func (b *RobotgoBackend) RecordInput() (input.Source, error) {
+	return input.NewSource()
+}
+
+// TypeStr returns ErrUnsupported
+func (b *RobotgoBackend) TypeStr(text string) error {
+	return ErrUnsupported
+}
+
+// KeyTap returns ErrUnsupported
+func (b *RobotgoBackend) KeyTap(key string, modifiers ...string) error {
+	return ErrUnsupported
+}
+
+// KeyDown returns ErrUnsupported
+func (b *RobotgoBackend) KeyDown(key string) error {
+	return ErrUnsupported
+}
+
+// KeyUp returns ErrUnsupported
+func (b *RobotgoBackend) KeyUp(key string) error {
+	return ErrUnsupported
+}
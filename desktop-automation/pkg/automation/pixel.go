@@ -0,0 +1,155 @@
+// Package automation provides wrappers for the robotgo library
+// to simplify desktop automation tasks
+package automation
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Color is an RGB color as seen on the screen
+type Color struct {
+	R, G, B uint8
+}
+
+// ParseColor parses a hex color such as "#ff8800", "ff8800" or "#f80"
+func ParseColor(s string) (Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return Color{}, fmt.Errorf("invalid color: %s (must be a hex color such as #ff8800)", s)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid color: %s (must be a hex color such as #ff8800)", s)
+	}
+
+	return Color{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, nil
+}
+
+// String returns the color as "#rrggbb"
+func (c Color) String() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Matches reports whether every channel of c is within tolerance of other
+func (c Color) Matches(other Color, tolerance int) bool {
+	return channelDiff(c.R, other.R) <= tolerance &&
+		channelDiff(c.G, other.G) <= tolerance &&
+		channelDiff(c.B, other.B) <= tolerance
+}
+
+// channelDiff returns the absolute difference of two color channels
+func channelDiff(a, b uint8) int {
+	return abs(int(a) - int(b))
+}
+
+// GetPixelColor returns the color of the pixel at (x, y)
+func (s *Screen) GetPixelColor(x, y int) (Color, error) {
+	return s.AverageColor(Rect{X: x, Y: y, Width: 1, Height: 1})
+}
+
+// AverageColor returns the average color of the pixels in region
+func (s *Screen) AverageColor(region Rect) (Color, error) {
+	img, _, err := s.Capture(&region)
+	if err != nil {
+		return Color{}, err
+	}
+
+	return averageColor(img), nil
+}
+
+// averageColor returns the mean color of every pixel of img
+func averageColor(img image.Image) Color {
+	bounds := img.Bounds()
+	var r, g, b, n uint64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pr, pg, pb, _ := img.At(x, y).RGBA()
+			r += uint64(pr >> 8)
+			g += uint64(pg >> 8)
+			b += uint64(pb >> 8)
+			n++
+		}
+	}
+	if n == 0 {
+		return Color{}
+	}
+
+	return Color{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n)}
+}
+
+// Default polling settings of WaitForColor
+const (
+	DefaultWaitTimeout  = 5 * time.Second
+	DefaultPollInterval = 100 * time.Millisecond
+)
+
+// ColorCondition describes what WaitForColor waits for
+type ColorCondition struct {
+	// Region is averaged on every poll; use a 1x1 region for a single pixel
+	Region Rect
+	// Color is the color to compare against
+	Color Color
+	// Tolerance is the largest difference per channel that still matches
+	Tolerance int
+	// Mismatch waits until the region stops matching Color instead
+	Mismatch bool
+	// Timeout bounds the wait; DefaultWaitTimeout if zero
+	Timeout time.Duration
+	// Interval is the time between polls; DefaultPollInterval if zero
+	Interval time.Duration
+}
+
+// WaitForColor polls the screen until the condition holds, the timeout
+// expires or ctx is done. It returns the last color seen.
+func (s *Screen) WaitForColor(ctx context.Context, cond ColorCondition) (Color, error) {
+	if cond.Tolerance < 0 || cond.Tolerance > 255 {
+		return Color{}, fmt.Errorf("invalid tolerance: %d (must be between 0 and 255)", cond.Tolerance)
+	}
+	if cond.Timeout < 0 || cond.Interval < 0 {
+		return Color{}, fmt.Errorf("invalid timing: timeout=%s, interval=%s (must be non-negative)", cond.Timeout, cond.Interval)
+	}
+
+	timeout := cond.Timeout
+	if timeout == 0 {
+		timeout = DefaultWaitTimeout
+	}
+	interval := cond.Interval
+	if interval == 0 {
+		interval = DefaultPollInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c, err := s.AverageColor(cond.Region)
+		if err != nil {
+			return Color{}, err
+		}
+		if c.Matches(cond.Color, cond.Tolerance) != cond.Mismatch {
+			return c, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			what := "match"
+			if cond.Mismatch {
+				what = "stop matching"
+			}
+			return c, fmt.Errorf("region %s did not %s %s within tolerance %d (last color %s): %w", cond.Region, what, cond.Color, cond.Tolerance, c, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,188 @@
+//go:build cgo
+
+// Package automation provides wrappers for the robotgo library
+// to simplify desktop automation tasks
+package automation
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/go-vgo/robotgo"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/display"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/input"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/window"
+)
+
+// RobotgoBackend is the default Backend, driving the real desktop via robotgo
+type RobotgoBackend struct{}
+
+// NewRobotgoBackend creates a new robotgo backed input driver
+func NewRobotgoBackend() *RobotgoBackend {
+	return &RobotgoBackend{}
+}
+
+// Move moves the cursor instantly to the given coordinates
+func (b *RobotgoBackend) Move(x, y int) error {
+	robotgo.Move(x, y)
+	return nil
+}
+
+// MoveSmooth moves the cursor smoothly to the given coordinates
+func (b *RobotgoBackend) MoveSmooth(x, y int, duration float64) error {
+	robotgo.MoveSmooth(x, y, duration, 1.0)
+	return nil
+}
+
+// Click clicks button count times at the current position
+func (b *RobotgoBackend) Click(button Button, count int) error {
+	name := robotgoButton(button)
+	if count == 2 {
+		robotgo.Click(name, true)
+		return nil
+	}
+
+	for i := 0; i < count; i++ {
+		robotgo.Click(name)
+	}
+	return nil
+}
+
+// MouseDown presses button at the current position
+func (b *RobotgoBackend) MouseDown(button Button) error {
+	return robotgo.MouseDown(robotgoButton(button))
+}
+
+// MouseUp releases button at the current position
+func (b *RobotgoBackend) MouseUp(button Button) error {
+	return robotgo.MouseUp(robotgoButton(button))
+}
+
+// robotgoButton returns the robotgo name of button
+func robotgoButton(button Button) string {
+	if button == ButtonMiddle {
+		return "center"
+	}
+	return string(button)
+}
+
+// Scroll scrolls the wheel at the current position. robotgo scrolls up and
+// left for positive amounts, so the directions are flipped.
+func (b *RobotgoBackend) Scroll(dx, dy int) error {
+	robotgo.Scroll(-dx, -dy)
+	return nil
+}
+
+// Location returns the current cursor position
+func (b *RobotgoBackend) Location() (int, int) {
+	return robotgo.Location()
+}
+
+// Displays returns the monitors of the desktop. If the system cannot
+// enumerate them the main screen is reported as the only display.
+func (b *RobotgoBackend) Displays() (display.Layout, error) {
+	n := robotgo.DisplaysNum()
+	if n <= 0 {
+		w, h := robotgo.GetScreenSize()
+		if w <= 0 || h <= 0 {
+			return nil, fmt.Errorf("no displays found")
+		}
+		return display.Layout{{Width: w, Height: h, Primary: true, ScaleFactor: robotgo.ScaleF()}}, nil
+	}
+
+	mainID := robotgo.GetMainId()
+	layout := make(display.Layout, 0, n)
+	for i := 0; i < n; i++ {
+		x, y, w, h := robotgo.GetDisplayBounds(i)
+		layout = append(layout, display.Display{
+			ID:          i,
+			X:           x,
+			Y:           y,
+			Width:       w,
+			Height:      h,
+			Primary:     i == mainID,
+			ScaleFactor: robotgo.ScaleF(i),
+		})
+	}
+
+	return layout, nil
+}
+
+// ScreenSize returns the size of the main screen
+func (b *RobotgoBackend) ScreenSize() (int, int) {
+	return robotgo.GetScreenSize()
+}
+
+// Capture grabs a rectangle of the screen
+func (b *RobotgoBackend) Capture(x, y, w, h int) (image.Image, error) {
+	return robotgo.CaptureImg(x, y, w, h)
+}
+
+// Windows returns the top-level windows of the desktop
+func (b *RobotgoBackend) Windows() ([]window.Window, error) {
+	return window.List()
+}
+
+// ActiveWindow returns the window holding the input focus
+func (b *RobotgoBackend) ActiveWindow() (window.Window, error) {
+	return window.Active()
+}
+
+// ActivateWindow raises and focuses the window with the given ID
+func (b *RobotgoBackend) ActivateWindow(id uint64) error {
+	return window.Activate(id)
+}
+
+// SetWindowBounds moves and resizes the window with the given ID
+func (b *RobotgoBackend) SetWindowBounds(id uint64, x, y, w, h int) error {
+	return window.SetBounds(id, x, y, w, h)
+}
+
+// SetWindowState minimizes, maximizes or restores the window with the given ID
+func (b *RobotgoBackend) SetWindowState(id uint64, state window.State) error {
+	return window.SetState(id, state)
+}
+
+// CloseWindow asks the window with the given ID to close
+func (b *RobotgoBackend) CloseWindow(id uint64) error {
+	return window.Close(id)
+}
+
+// ReadClipboard returns the text on the clipboard
+func (b *RobotgoBackend) ReadClipboard() (string, error) {
+	return robotgo.ReadAll()
+}
+
+// WriteClipboard puts text on the clipboard
+func (b *RobotgoBackend) WriteClipboard(text string) error {
+	return robotgo.WriteAll(text)
+}
+
+// RecordInput starts capturing the global input events
+func (b *RobotgoBackend) RecordInput() (input.Source, error) {
+	return input.NewSource()
+}
+
+// TypeStr types the given text
+func (b *RobotgoBackend) TypeStr(text string) error {
+	robotgo.TypeStr(text)
+	return nil
+}
+
+// KeyTap taps key while holding the given modifiers
+func (b *RobotgoBackend) KeyTap(key string, modifiers ...string) error {
+	if len(modifiers) == 0 {
+		return robotgo.KeyTap(key)
+	}
+	return robotgo.KeyTap(key, modifiers)
+}
+
+// KeyDown presses key and keeps it held
+func (b *RobotgoBackend) KeyDown(key string) error {
+	return robotgo.KeyDown(key)
+}
+
+// KeyUp releases key
+func (b *RobotgoBackend) KeyUp(key string) error {
+	return robotgo.KeyUp(key)
+}
@@ -0,0 +1,269 @@
+// Package automation provides wrappers for the robotgo library
+// to simplify desktop automation tasks
+package automation
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/display"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/imagematch"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/ocr"
+	"golang.org/x/image/draw"
+)
+
+// Rect is a rectangle on the screen
+type Rect struct {
+	X, Y          int
+	Width, Height int
+}
+
+// String returns the rectangle in the x,y,w,h form accepted by ParseRect
+func (r Rect) String() string {
+	return fmt.Sprintf("%d,%d,%d,%d", r.X, r.Y, r.Width, r.Height)
+}
+
+// ParseRect parses a rectangle written as x,y,w,h
+func ParseRect(s string) (Rect, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return Rect{}, fmt.Errorf("invalid region: %s (must be x,y,w,h)", s)
+	}
+
+	var v [4]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return Rect{}, fmt.Errorf("invalid region: %s (must be x,y,w,h integers)", s)
+		}
+		v[i] = n
+	}
+
+	return Rect{X: v[0], Y: v[1], Width: v[2], Height: v[3]}, nil
+}
+
+// ImageFormat is the encoding of a screenshot
+type ImageFormat string
+
+// Image formats
+const (
+	FormatPNG  ImageFormat = "png"
+	FormatJPEG ImageFormat = "jpeg"
+)
+
+// ParseImageFormat parses a format name such as "png", "jpeg" or "jpg"
+func ParseImageFormat(name string) (ImageFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "png":
+		return FormatPNG, nil
+	case "jpeg", "jpg":
+		return FormatJPEG, nil
+	}
+
+	return "", fmt.Errorf("unknown image format %q (must be png or jpeg)", name)
+}
+
+// MIMEType returns the MIME type of images in format f
+func (f ImageFormat) MIMEType() string {
+	if f == FormatJPEG {
+		return "image/jpeg"
+	}
+	return "image/png"
+}
+
+// DefaultJPEGQuality is used when CaptureOptions.Quality is not set
+const DefaultJPEGQuality = 80
+
+// CaptureOptions controls what Screen.CaptureEncoded grabs and how it encodes it
+type CaptureOptions struct {
+	// Region is the part of the screen to capture; the full screen if nil
+	Region *Rect
+	// MaxWidth and MaxHeight downscale the capture to fit, keeping its aspect
+	// ratio. Zero means no limit.
+	MaxWidth, MaxHeight int
+	// Format is the encoding of the result; PNG by default
+	Format ImageFormat
+	// Quality is the JPEG quality from 1 to 100
+	Quality int
+}
+
+// Screenshot is an encoded screen capture
+type Screenshot struct {
+	// Data is the encoded image
+	Data []byte
+	// Format is the encoding of Data
+	Format ImageFormat
+	// Region is the captured part of the screen
+	Region Rect
+	// Width and Height are the dimensions of the encoded image
+	Width, Height int
+	// Scale is the captured size divided by the encoded size, so that screen
+	// coordinates are Region.X + x*Scale for an x in the image
+	Scale float64
+}
+
+// Screen represents screen capture functionality
+type Screen struct {
+	backend Backend
+	ocr     ocr.Engine
+}
+
+// NewScreen creates a new screen capture instance backed by robotgo
+func NewScreen() *Screen {
+	return NewScreenWithBackend(NewRobotgoBackend())
+}
+
+// NewScreenWithBackend creates a new screen capture instance using the given
+// backend and tesseract for OCR
+func NewScreenWithBackend(backend Backend) *Screen {
+	return NewScreenWithOCR(backend, ocr.NewTesseractEngine())
+}
+
+// NewScreenWithOCR creates a new screen capture instance using the given
+// backend and OCR engine
+func NewScreenWithOCR(backend Backend, engine ocr.Engine) *Screen {
+	return &Screen{backend: backend, ocr: engine}
+}
+
+// Size returns the width and height of the screen
+func (s *Screen) Size() (int, int) {
+	return s.backend.ScreenSize()
+}
+
+// Displays returns the monitors that make up the desktop
+func (s *Screen) Displays() (display.Layout, error) {
+	layout, err := s.backend.Displays()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list displays: %w", err)
+	}
+
+	return layout, nil
+}
+
+// Capture grabs the given region of the desktop, or the full primary screen
+// if region is nil. The region may lie on any display, including those left
+// of or above the primary one, and span several, but not the gaps between
+// displays of different sizes.
+func (s *Screen) Capture(region *Rect) (image.Image, Rect, error) {
+	width, height := s.Size()
+	r := Rect{Width: width, Height: height}
+	if region != nil {
+		r = *region
+		layout, err := s.Displays()
+		if err != nil {
+			return nil, Rect{}, err
+		}
+		if r.Width <= 0 || r.Height <= 0 || !layout.Covers(image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height)) {
+			return nil, Rect{}, fmt.Errorf("invalid region: %s (must lie on the displays %s)", r, layout)
+		}
+	}
+
+	img, err := s.backend.Capture(r.X, r.Y, r.Width, r.Height)
+	if err != nil {
+		return nil, Rect{}, fmt.Errorf("failed to capture screen: %w", err)
+	}
+
+	return img, r, nil
+}
+
+// FindImage captures the given region, or the full screen if region is nil,
+// and searches it for tmpl. The matches are in screen coordinates.
+func (s *Screen) FindImage(tmpl image.Image, region *Rect, opts imagematch.Options) (imagematch.Result, error) {
+	img, r, err := s.Capture(region)
+	if err != nil {
+		return imagematch.Result{}, err
+	}
+
+	result, err := imagematch.Find(img, tmpl, opts)
+	if err != nil {
+		return imagematch.Result{}, err
+	}
+
+	// HiDPI captures may have more pixels than the region has points
+	scale := float64(img.Bounds().Dx()) / float64(r.Width)
+	for i, m := range result.Matches {
+		result.Matches[i].X = r.X + int(float64(m.X)/scale)
+		result.Matches[i].Y = r.Y + int(float64(m.Y)/scale)
+		result.Matches[i].Width = int(float64(m.Width) / scale)
+		result.Matches[i].Height = int(float64(m.Height) / scale)
+	}
+
+	return result, nil
+}
+
+// CaptureEncoded grabs the screen, downscales and encodes it as set by opts
+func (s *Screen) CaptureEncoded(opts CaptureOptions) (*Screenshot, error) {
+	if opts.MaxWidth < 0 || opts.MaxHeight < 0 {
+		return nil, fmt.Errorf("invalid maximum size: %dx%d (must be non-negative)", opts.MaxWidth, opts.MaxHeight)
+	}
+
+	format, err := ParseImageFormat(string(opts.Format))
+	if err != nil {
+		return nil, err
+	}
+
+	quality := opts.Quality
+	if quality == 0 {
+		quality = DefaultJPEGQuality
+	}
+	if quality < 1 || quality > 100 {
+		return nil, fmt.Errorf("invalid quality: %d (must be between 1 and 100)", quality)
+	}
+
+	img, region, err := s.Capture(opts.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	img, scale := downscale(img, opts.MaxWidth, opts.MaxHeight)
+
+	var buf bytes.Buffer
+	switch format {
+	case FormatJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	default:
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", format, err)
+	}
+
+	bounds := img.Bounds()
+	return &Screenshot{
+		Data:   buf.Bytes(),
+		Format: format,
+		Region: region,
+		Width:  bounds.Dx(),
+		Height: bounds.Dy(),
+		Scale:  scale,
+	}, nil
+}
+
+// downscale shrinks img to fit within maxWidth by maxHeight, keeping its
+// aspect ratio, and returns the scale factor. Zero limits are ignored and
+// images are never enlarged.
+func downscale(img image.Image, maxWidth, maxHeight int) (image.Image, float64) {
+	bounds := img.Bounds()
+	scale := 1.0
+	if maxWidth > 0 && bounds.Dx() > maxWidth {
+		scale = max(scale, float64(bounds.Dx())/float64(maxWidth))
+	}
+	if maxHeight > 0 && bounds.Dy() > maxHeight {
+		scale = max(scale, float64(bounds.Dy())/float64(maxHeight))
+	}
+	if scale == 1.0 {
+		return img, scale
+	}
+
+	width := max(int(float64(bounds.Dx())/scale), 1)
+	height := max(int(float64(bounds.Dy())/scale), 1)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+
+	return dst, scale
+}
@@ -0,0 +1,59 @@
+package automation
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/display"
+)
+
+func TestCaptureRegion(t *testing.T) {
+	// The secondary display is shorter and sits higher than the primary one,
+	// so together they form an L with gaps above and below
+	backend := NewFakeBackend()
+	backend.SetDisplays(display.Layout{
+		{ID: 0, Width: 1920, Height: 1080, Primary: true, ScaleFactor: 1},
+		{ID: 1, X: -1280, Y: -200, Width: 1280, Height: 720, ScaleFactor: 1},
+	})
+	screen := NewScreenWithBackend(backend)
+
+	tests := []struct {
+		name    string
+		region  Rect
+		wantErr bool
+	}{
+		{name: "primary display", region: Rect{X: 100, Y: 100, Width: 50, Height: 50}},
+		{name: "secondary display", region: Rect{X: -1280, Y: -200, Width: 100, Height: 100}},
+		{name: "across displays", region: Rect{X: -50, Y: 0, Width: 100, Height: 100}},
+		{name: "left of every display", region: Rect{X: -1300, Y: 0, Width: 100, Height: 100}, wantErr: true},
+		{name: "below every display", region: Rect{X: 0, Y: 1000, Width: 100, Height: 100}, wantErr: true},
+		{name: "above the primary display", region: Rect{X: 10, Y: -100, Width: 50, Height: 50}, wantErr: true},
+		{name: "below the secondary display", region: Rect{X: -100, Y: 600, Width: 50, Height: 50}, wantErr: true},
+		{name: "across displays into the gap", region: Rect{X: -50, Y: 500, Width: 100, Height: 50}, wantErr: true},
+		{name: "across displays along the gap", region: Rect{X: -50, Y: 420, Width: 100, Height: 100}},
+		{name: "empty", region: Rect{X: 0, Y: 0, Width: 0, Height: 100}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend.Reset()
+			img, r, err := screen.Capture(&tt.region)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Capture(%s) succeeded, want an error", tt.region)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Capture(%s) failed: %v", tt.region, err)
+			}
+			if r != tt.region || img.Bounds().Dx() != tt.region.Width {
+				t.Errorf("Capture(%s) = %v image of %s", tt.region, img.Bounds(), r)
+			}
+			want := []Event{{Kind: EventCapture, X: tt.region.X, Y: tt.region.Y, Width: tt.region.Width, Height: tt.region.Height}}
+			if got := backend.Events(); !reflect.DeepEqual(got, want) {
+				t.Errorf("events = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
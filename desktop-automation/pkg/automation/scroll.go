@@ -0,0 +1,194 @@
+// Package automation provides wrappers for the robotgo library
+// to simplify desktop automation tasks
+package automation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScrollDirection is the direction the content under the cursor moves towards
+type ScrollDirection string
+
+// Scroll directions
+const (
+	ScrollUp    ScrollDirection = "up"
+	ScrollDown  ScrollDirection = "down"
+	ScrollLeft  ScrollDirection = "left"
+	ScrollRight ScrollDirection = "right"
+)
+
+// ParseScrollDirection parses a direction such as "up" or "right"
+func ParseScrollDirection(name string) (ScrollDirection, error) {
+	switch d := ScrollDirection(strings.ToLower(strings.TrimSpace(name))); d {
+	case ScrollUp, ScrollDown, ScrollLeft, ScrollRight:
+		return d, nil
+	}
+
+	return "", fmt.Errorf("unknown scroll direction %q (must be up, down, left or right)", name)
+}
+
+// Delta returns the horizontal and vertical scroll amounts for scrolling
+// amount in direction d. Positive amounts scroll right and down.
+func (d ScrollDirection) Delta(amount int) (int, int) {
+	switch d {
+	case ScrollUp:
+		return 0, -amount
+	case ScrollDown:
+		return 0, amount
+	case ScrollLeft:
+		return -amount, 0
+	case ScrollRight:
+		return amount, 0
+	}
+
+	return 0, 0
+}
+
+// ScrollUnit is the unit of a scroll amount
+type ScrollUnit string
+
+// Scroll units
+const (
+	// ScrollNotches counts wheel notches, the default
+	ScrollNotches ScrollUnit = "notches"
+	// ScrollPixels approximates a distance in pixels, see PixelsPerNotch
+	ScrollPixels ScrollUnit = "pixels"
+)
+
+// PixelsPerNotch is the distance a single wheel notch is assumed to scroll.
+// The real distance depends on the application and desktop settings.
+const PixelsPerNotch = 40
+
+// ScrollOptions controls how Scroll moves the wheel
+type ScrollOptions struct {
+	// Unit is the unit of the scroll amounts; notches by default
+	Unit ScrollUnit
+	// Smooth spreads the scroll over Duration one notch at a time instead
+	// of scrolling the whole amount at once
+	Smooth bool
+	// Duration is the length of a smooth scroll in seconds
+	Duration float64
+}
+
+// Limits of a single scroll
+const (
+	// MaxScrollNotches is the largest number of notches Scroll turns the
+	// wheel along either axis
+	MaxScrollNotches = 1000
+	// MaxScrollDuration is the longest a smooth scroll may take, in seconds
+	MaxScrollDuration = 60
+)
+
+// Scroll scrolls the wheel by dx and dy at the current cursor position.
+// Positive amounts scroll right and down, negative amounts left and up. A
+// smooth scroll stops between notches once ctx is done.
+func (m *Mouse) Scroll(ctx context.Context, dx, dy int, opts ScrollOptions) error {
+	dx, dy, err := validateScroll(dx, dy, opts)
+	if err != nil {
+		return err
+	}
+
+	return m.scroll(ctx, dx, dy, opts)
+}
+
+// ScrollAt moves the cursor to (x, y) and scrolls there like Scroll. The
+// cursor is not moved if the scroll is invalid.
+func (m *Mouse) ScrollAt(ctx context.Context, x, y, dx, dy int, opts ScrollOptions) error {
+	dx, dy, err := validateScroll(dx, dy, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := m.Move(x, y); err != nil {
+		return err
+	}
+
+	return m.scroll(ctx, dx, dy, opts)
+}
+
+// scroll turns the wheel by dx and dy notches, already validated
+func (m *Mouse) scroll(ctx context.Context, dx, dy int, opts ScrollOptions) error {
+	if !opts.Smooth {
+		return m.backend.Scroll(dx, dy)
+	}
+
+	// Scroll one notch per step along the longer axis
+	steps := max(abs(dx), abs(dy))
+	interval := time.Duration(opts.Duration * float64(time.Second) / float64(steps))
+	var doneX, doneY int
+	for i := 1; i <= steps; i++ {
+		stepX := dx*i/steps - doneX
+		stepY := dy*i/steps - doneY
+		if err := m.backend.Scroll(stepX, stepY); err != nil {
+			return err
+		}
+		doneX += stepX
+		doneY += stepY
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// validateScroll checks a scroll and returns its amounts in notches
+func validateScroll(dx, dy int, opts ScrollOptions) (int, int, error) {
+	dx, dy, err := scrollNotches(dx, dy, opts.Unit)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if dx == 0 && dy == 0 {
+		return 0, 0, fmt.Errorf("invalid scroll amount: dx=0, dy=0 (must scroll at least one notch)")
+	}
+	if dx < -MaxScrollNotches || dx > MaxScrollNotches || dy < -MaxScrollNotches || dy > MaxScrollNotches {
+		return 0, 0, fmt.Errorf("invalid scroll amount: dx=%d, dy=%d notches (must be at most %d)", dx, dy, MaxScrollNotches)
+	}
+
+	if opts.Smooth && !(opts.Duration > 0 && opts.Duration <= MaxScrollDuration) {
+		return 0, 0, fmt.Errorf("invalid duration: %f (must be positive and at most %d)", opts.Duration, MaxScrollDuration)
+	}
+
+	return dx, dy, nil
+}
+
+// scrollNotches converts scroll amounts in unit to wheel notches. Pixel
+// amounts round to the nearest notch but never to zero.
+func scrollNotches(dx, dy int, unit ScrollUnit) (int, int, error) {
+	switch unit {
+	case "", ScrollNotches:
+		return dx, dy, nil
+	case ScrollPixels:
+		return pixelsToNotches(dx), pixelsToNotches(dy), nil
+	}
+
+	return 0, 0, fmt.Errorf("unknown scroll unit %q (must be notches or pixels)", unit)
+}
+
+// pixelsToNotches rounds a pixel distance to whole notches
+func pixelsToNotches(pixels int) int {
+	if pixels == 0 {
+		return 0
+	}
+
+	notches := (abs(pixels) + PixelsPerNotch/2) / PixelsPerNotch
+	notches = max(notches, 1)
+	if pixels < 0 {
+		return -notches
+	}
+	return notches
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,66 @@
+// Package automation provides wrappers for the robotgo library
+// to simplify desktop automation tasks
+package automation
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/ocr"
+)
+
+// ReadText captures the given region, or the full screen if region is nil,
+// and returns the words recognized in it. Their positions are in screen
+// coordinates.
+func (s *Screen) ReadText(region *Rect) ([]ocr.Word, error) {
+	img, r, err := s.Capture(region)
+	if err != nil {
+		return nil, err
+	}
+
+	// Show the engine the capture at its place on the screen. HiDPI captures
+	// may have more pixels than the region has points.
+	scale := float64(img.Bounds().Dx()) / float64(r.Width)
+	origin := image.Pt(int(float64(r.X)*scale), int(float64(r.Y)*scale))
+	words, err := s.ocr.Recognize(&translatedImage{Image: img, offset: origin.Sub(img.Bounds().Min)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read text: %w", err)
+	}
+
+	for i, w := range words {
+		words[i].X = int(float64(w.X) / scale)
+		words[i].Y = int(float64(w.Y) / scale)
+		words[i].Width = int(float64(w.Width) / scale)
+		words[i].Height = int(float64(w.Height) / scale)
+	}
+
+	return words, nil
+}
+
+// FindText reads the text of the given region, or the full screen if region
+// is nil, and returns every occurrence of query as described by ocr.Find
+func (s *Screen) FindText(query string, exact bool, region *Rect) ([]ocr.Word, error) {
+	words, err := s.ReadText(region)
+	if err != nil {
+		return nil, err
+	}
+
+	return ocr.Find(words, query, exact), nil
+}
+
+// translatedImage moves an image by offset
+type translatedImage struct {
+	image.Image
+	offset image.Point
+}
+
+// Bounds returns the bounds of the moved image
+func (t *translatedImage) Bounds() image.Rectangle {
+	return t.Image.Bounds().Add(t.offset)
+}
+
+// At returns the color of the moved image at (x, y)
+func (t *translatedImage) At(x, y int) color.Color {
+	return t.Image.At(x-t.offset.X, y-t.offset.Y)
+}
@@ -0,0 +1,148 @@
+// Package automation provides wrappers for the robotgo library
+// to simplify desktop automation tasks
+package automation
+
+import (
+	"fmt"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/window"
+)
+
+// Windows represents window enumeration and focus control
+type Windows struct {
+	backend Backend
+}
+
+// NewWindows creates a new window controller for the real desktop
+func NewWindows() *Windows {
+	return NewWindowsWithBackend(NewRobotgoBackend())
+}
+
+// NewWindowsWithBackend creates a new window controller using the given backend
+func NewWindowsWithBackend(backend Backend) *Windows {
+	return &Windows{backend: backend}
+}
+
+// List returns the top-level windows picked by sel
+func (w *Windows) List(sel window.Selector) ([]window.Window, error) {
+	windows, err := w.backend.Windows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list windows: %w", err)
+	}
+
+	return window.Filter(windows, sel), nil
+}
+
+// Active returns the window holding the input focus
+func (w *Windows) Active() (window.Window, error) {
+	active, err := w.backend.ActiveWindow()
+	if err != nil {
+		return window.Window{}, fmt.Errorf("failed to get active window: %w", err)
+	}
+
+	return active, nil
+}
+
+// Activate raises the first window picked by sel, gives it the input focus
+// and returns it
+func (w *Windows) Activate(sel window.Selector) (window.Window, error) {
+	target, err := w.find(sel)
+	if err != nil {
+		return window.Window{}, err
+	}
+
+	if err := w.backend.ActivateWindow(target.ID); err != nil {
+		return window.Window{}, fmt.Errorf("failed to activate window: %w", err)
+	}
+
+	target.Focused = true
+	return target, nil
+}
+
+// SetBounds moves the first window picked by sel to bounds and returns it
+func (w *Windows) SetBounds(sel window.Selector, bounds Rect) (window.Window, error) {
+	target, err := w.find(sel)
+	if err != nil {
+		return window.Window{}, err
+	}
+
+	return w.setBounds(target, bounds)
+}
+
+// Move moves the first window picked by sel so that its top left corner is
+// at (x, y), keeping its size
+func (w *Windows) Move(sel window.Selector, x, y int) (window.Window, error) {
+	target, err := w.find(sel)
+	if err != nil {
+		return window.Window{}, err
+	}
+
+	return w.setBounds(target, Rect{X: x, Y: y, Width: target.Width, Height: target.Height})
+}
+
+// Resize resizes the first window picked by sel, keeping its position
+func (w *Windows) Resize(sel window.Selector, width, height int) (window.Window, error) {
+	target, err := w.find(sel)
+	if err != nil {
+		return window.Window{}, err
+	}
+
+	return w.setBounds(target, Rect{X: target.X, Y: target.Y, Width: width, Height: height})
+}
+
+// setBounds moves target to bounds
+func (w *Windows) setBounds(target window.Window, bounds Rect) (window.Window, error) {
+	if bounds.Width <= 0 || bounds.Height <= 0 {
+		return window.Window{}, fmt.Errorf("invalid window size: %dx%d (must be positive)", bounds.Width, bounds.Height)
+	}
+
+	if err := w.backend.SetWindowBounds(target.ID, bounds.X, bounds.Y, bounds.Width, bounds.Height); err != nil {
+		return window.Window{}, fmt.Errorf("failed to set window bounds: %w", err)
+	}
+
+	target.X, target.Y, target.Width, target.Height = bounds.X, bounds.Y, bounds.Width, bounds.Height
+	return target, nil
+}
+
+// SetState minimizes, maximizes or restores the first window picked by sel
+func (w *Windows) SetState(sel window.Selector, state window.State) (window.Window, error) {
+	target, err := w.find(sel)
+	if err != nil {
+		return window.Window{}, err
+	}
+
+	if err := w.backend.SetWindowState(target.ID, state); err != nil {
+		return window.Window{}, fmt.Errorf("failed to set window state: %w", err)
+	}
+
+	target.State = state
+	return target, nil
+}
+
+// Close asks the first window picked by sel to close and returns it. The
+// application may ask for confirmation or refuse.
+func (w *Windows) Close(sel window.Selector) (window.Window, error) {
+	target, err := w.find(sel)
+	if err != nil {
+		return window.Window{}, err
+	}
+
+	if err := w.backend.CloseWindow(target.ID); err != nil {
+		return window.Window{}, fmt.Errorf("failed to close window: %w", err)
+	}
+
+	return target, nil
+}
+
+// find returns the first window picked by sel
+func (w *Windows) find(sel window.Selector) (window.Window, error) {
+	matched, err := w.List(sel)
+	if err != nil {
+		return window.Window{}, err
+	}
+	if len(matched) == 0 {
+		return window.Window{}, fmt.Errorf("no window matches %s", sel)
+	}
+
+	return matched[0], nil
+}
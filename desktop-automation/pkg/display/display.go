@@ -0,0 +1,133 @@
+// Package display describes the monitors that make up the desktop
+package display
+
+import (
+	"fmt"
+	"image"
+	"slices"
+	"strings"
+)
+
+// Display is a single monitor. Its bounds are in virtual desktop coordinates,
+// so displays to the left of or above the primary one may have negative
+// origins.
+type Display struct {
+	// ID is the index of the display as reported by the system
+	ID int `json:"id"`
+	// X and Y are the top left corner of the display
+	X int `json:"x"`
+	Y int `json:"y"`
+	// Width and Height are the size of the display in pixels
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Primary is set for the main display
+	Primary bool `json:"primary"`
+	// ScaleFactor is the ratio of physical to logical pixels, e.g. 2 on HiDPI displays
+	ScaleFactor float64 `json:"scale_factor"`
+}
+
+// Bounds returns the rectangle covered by the display
+func (d Display) Bounds() image.Rectangle {
+	return image.Rect(d.X, d.Y, d.X+d.Width, d.Y+d.Height)
+}
+
+// Contains reports whether the point (x, y) lies on the display
+func (d Display) Contains(x, y int) bool {
+	return image.Pt(x, y).In(d.Bounds())
+}
+
+// String returns the display as "id: WxH at (x, y)"
+func (d Display) String() string {
+	s := fmt.Sprintf("%d: %dx%d at (%d, %d)", d.ID, d.Width, d.Height, d.X, d.Y)
+	if d.Primary {
+		s += " primary"
+	}
+	return s
+}
+
+// Layout is the set of displays that make up the desktop
+type Layout []Display
+
+// Contains reports whether the point (x, y) lies on any display. Gaps between
+// displays of different sizes are not part of the layout.
+func (l Layout) Contains(x, y int) bool {
+	for _, d := range l {
+		if d.Contains(x, y) {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers reports whether every point of r lies on a display. Unlike the
+// Bounds of the layout, it leaves out the gaps between displays of different
+// sizes.
+func (l Layout) Covers(r image.Rectangle) bool {
+	if r.Empty() {
+		return false
+	}
+
+	// The displays on a row only change at their top and bottom edges, so
+	// checking the first row of each band between edges checks every row
+	rows := []int{r.Min.Y}
+	for _, d := range l {
+		for _, y := range []int{d.Y, d.Y + d.Height} {
+			if y > r.Min.Y && y < r.Max.Y {
+				rows = append(rows, y)
+			}
+		}
+	}
+
+	for _, y := range rows {
+		var spans []image.Rectangle
+		for _, d := range l {
+			if b := d.Bounds(); y >= b.Min.Y && y < b.Max.Y {
+				spans = append(spans, b)
+			}
+		}
+		slices.SortFunc(spans, func(a, b image.Rectangle) int { return a.Min.X - b.Min.X })
+
+		x := r.Min.X
+		for _, b := range spans {
+			if b.Min.X > x {
+				break
+			}
+			x = max(x, b.Max.X)
+		}
+		if x < r.Max.X {
+			return false
+		}
+	}
+	return true
+}
+
+// Bounds returns the smallest rectangle covering every display
+func (l Layout) Bounds() image.Rectangle {
+	var r image.Rectangle
+	for _, d := range l {
+		r = r.Union(d.Bounds())
+	}
+	return r
+}
+
+// Primary returns the primary display, or the first one if none is marked
+func (l Layout) Primary() (Display, bool) {
+	for _, d := range l {
+		if d.Primary {
+			return d, true
+		}
+	}
+	if len(l) > 0 {
+		return l[0], true
+	}
+	return Display{}, false
+}
+
+// String lists the displays separated by semicolons
+func (l Layout) String() string {
+	parts := make([]string, len(l))
+	for i, d := range l {
+		parts[i] = d.String()
+	}
+	return strings.Join(parts, "; ")
+}
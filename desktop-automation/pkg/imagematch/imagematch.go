@@ -0,0 +1,338 @@
+// Package imagematch finds a template image inside a larger image, e.g. a
+// button inside a screenshot, using normalized cross-correlation. It is pure
+// Go. Every position is first scored against a sparse sample of the
+// template, and only the promising ones are scored in full, so that full
+// screen searches stay fast.
+package imagematch
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"sort"
+)
+
+// DefaultThreshold is the confidence a match needs when Options.Threshold is not set
+const DefaultThreshold = 0.9
+
+// ErrNoContrast is returned for templates of a single flat color, which match
+// every equally flat area of the image
+var ErrNoContrast = errors.New("template has no contrast (it is a single flat color)")
+
+// Options controls a search
+type Options struct {
+	// Threshold is the confidence from 0 to 1 a match needs; DefaultThreshold if zero
+	Threshold float64
+	// MaxResults limits the number of matches returned; zero means no limit
+	MaxResults int
+}
+
+// Match is a place where the template was found
+type Match struct {
+	// X and Y are the top left corner of the match
+	X int
+	Y int
+	// Width and Height are the size of the template
+	Width  int
+	Height int
+	// Confidence is the normalized cross-correlation from 0 to 1, where 1 is
+	// an exact match up to brightness and contrast
+	Confidence float64
+}
+
+// Center returns the center point of the match
+func (m Match) Center() (int, int) {
+	return m.X + m.Width/2, m.Y + m.Height/2
+}
+
+// Result holds the matches of a search, best first
+type Result struct {
+	Matches []Match
+}
+
+// Best returns the most confident match, if any
+func (r Result) Best() (Match, bool) {
+	if len(r.Matches) == 0 {
+		return Match{}, false
+	}
+	return r.Matches[0], true
+}
+
+const (
+	// sparseSamples is roughly how many template pixels the first pass samples
+	sparseSamples = 64
+	// maxCandidates bounds the positions scored in full
+	maxCandidates = 256
+	// sparseSlack lowers the threshold of the sparse pass, which only
+	// approximates the full score
+	sparseSlack = 0.15
+)
+
+// Find searches img for every occurrence of tmpl with at least the threshold
+// confidence. Overlapping matches are reduced to the best of them.
+func Find(img, tmpl image.Image, opts Options) (Result, error) {
+	threshold := opts.Threshold
+	if threshold == 0 {
+		threshold = DefaultThreshold
+	}
+	if threshold < 0 || threshold > 1 {
+		return Result{}, fmt.Errorf("invalid threshold: %g (must be between 0 and 1)", threshold)
+	}
+	if opts.MaxResults < 0 {
+		return Result{}, fmt.Errorf("invalid max results: %d (must be non-negative)", opts.MaxResults)
+	}
+
+	hay := newGray(img)
+	needle := newGray(tmpl)
+	if needle.w == 0 || needle.h == 0 {
+		return Result{}, fmt.Errorf("template is empty")
+	}
+	if needle.w > hay.w || needle.h > hay.h {
+		return Result{}, fmt.Errorf("template (%dx%d) is larger than the image (%dx%d)", needle.w, needle.h, hay.w, hay.h)
+	}
+
+	t, err := newTemplate(needle)
+	if err != nil {
+		return Result{}, err
+	}
+	sparse := newSparseTemplate(needle, hay.w)
+
+	// Score every position against the sparse template
+	sparseThreshold := threshold
+	if sparse.step > 1 {
+		sparseThreshold -= sparseSlack
+	}
+	var candidates []Match
+	for y := 0; y+t.h <= hay.h; y++ {
+		for x := 0; x+t.w <= hay.w; x++ {
+			if score := sparse.score(hay, x, y); score >= sparseThreshold {
+				candidates = append(candidates, Match{X: x, Y: y, Confidence: score})
+			}
+		}
+	}
+	candidates = suppress(candidates, t.w, t.h, maxCandidates)
+
+	// Score the candidates in full, allowing them to shift by a pixel
+	s := newSearcher(hay)
+	matches := candidates[:0:0]
+	for _, c := range candidates {
+		best := Match{Confidence: math.Inf(-1)}
+		for y := c.Y - 1; y <= c.Y+1; y++ {
+			for x := c.X - 1; x <= c.X+1; x++ {
+				if x < 0 || y < 0 || x+t.w > s.w || y+t.h > s.h {
+					continue
+				}
+				if score := s.score(t, x, y); score > best.Confidence {
+					best = Match{X: x, Y: y, Confidence: score}
+				}
+			}
+		}
+		if best.Confidence >= threshold {
+			matches = append(matches, best)
+		}
+	}
+	matches = suppress(matches, t.w, t.h, maxCandidates)
+
+	if opts.MaxResults > 0 && len(matches) > opts.MaxResults {
+		matches = matches[:opts.MaxResults]
+	}
+	for i := range matches {
+		matches[i].Width = needle.w
+		matches[i].Height = needle.h
+		matches[i].Confidence = math.Min(math.Max(matches[i].Confidence, 0), 1)
+	}
+
+	return Result{Matches: matches}, nil
+}
+
+// suppress sorts matches best first and drops every match that overlaps a
+// better one by more than half the template size, keeping at most limit
+func suppress(matches []Match, w, h, limit int) []Match {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Confidence > matches[j].Confidence
+	})
+
+	var kept []Match
+	for _, m := range matches {
+		overlaps := false
+		for _, k := range kept {
+			if abs(m.X-k.X) < w/2+1 && abs(m.Y-k.Y) < h/2+1 {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			kept = append(kept, m)
+			if len(kept) == limit {
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// gray is a grayscale image stored as luminance values
+type gray struct {
+	w, h int
+	pix  []float64
+}
+
+// newGray converts img to grayscale
+func newGray(img image.Image) *gray {
+	b := img.Bounds()
+	g := &gray{w: b.Dx(), h: b.Dy(), pix: make([]float64, b.Dx()*b.Dy())}
+	for y := 0; y < g.h; y++ {
+		for x := 0; x < g.w; x++ {
+			r, gr, bl, _ := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			g.pix[y*g.w+x] = (0.299*float64(r) + 0.587*float64(gr) + 0.114*float64(bl)) / 257
+		}
+	}
+	return g
+}
+
+// template is a zero-mean template ready for correlation
+type template struct {
+	w, h int
+	pix  []float64
+	norm float64
+}
+
+// newTemplate subtracts the mean of g and computes its norm
+func newTemplate(g *gray) (*template, error) {
+	var sum float64
+	for _, v := range g.pix {
+		sum += v
+	}
+	mean := sum / float64(len(g.pix))
+
+	t := &template{w: g.w, h: g.h, pix: make([]float64, len(g.pix))}
+	var sq float64
+	for i, v := range g.pix {
+		t.pix[i] = v - mean
+		sq += t.pix[i] * t.pix[i]
+	}
+	t.norm = math.Sqrt(sq)
+	if t.norm < 1e-6*float64(len(g.pix)) {
+		return nil, ErrNoContrast
+	}
+	return t, nil
+}
+
+// sparseTemplate is a zero-mean grid of template samples, every step pixels
+type sparseTemplate struct {
+	step int
+	// offsets are the sample positions within an image of width stride
+	offsets []int
+	pix     []float64
+	norm    float64
+}
+
+// newSparseTemplate samples g on a grid coarse enough for about
+// sparseSamples points, refining the grid until the samples have contrast.
+// The template can score images of width stride.
+func newSparseTemplate(g *gray, stride int) *sparseTemplate {
+	step := max(1, int(math.Sqrt(float64(g.w*g.h)/sparseSamples)))
+	for ; ; step-- {
+		t := &sparseTemplate{step: step}
+		var sum float64
+		for y := step / 2; y < g.h; y += step {
+			for x := step / 2; x < g.w; x += step {
+				t.offsets = append(t.offsets, y*stride+x)
+				t.pix = append(t.pix, g.pix[y*g.w+x])
+				sum += g.pix[y*g.w+x]
+			}
+		}
+		mean := sum / float64(len(t.pix))
+		var sq float64
+		for i := range t.pix {
+			t.pix[i] -= mean
+			sq += t.pix[i] * t.pix[i]
+		}
+		t.norm = math.Sqrt(sq)
+		if t.norm >= 1e-6*float64(len(t.pix)) || step == 1 {
+			return t
+		}
+	}
+}
+
+// score returns the normalized cross-correlation of the samples at (x, y)
+// in img, from -1 to 1. Flat windows score zero.
+func (t *sparseTemplate) score(img *gray, x, y int) float64 {
+	var sum, sq, cross float64
+	window := img.pix[y*img.w+x:]
+	for i, v := range t.pix {
+		p := window[t.offsets[i]]
+		sum += p
+		sq += p * p
+		cross += v * p
+	}
+
+	n := float64(len(t.pix))
+	variance := sq - sum*sum/n
+	if variance <= 1e-6 {
+		return 0
+	}
+	return cross / (t.norm * math.Sqrt(variance))
+}
+
+// searcher scores template positions in an image, using summed area tables
+// for the window statistics
+type searcher struct {
+	*gray
+	sum, sq []float64
+}
+
+// newSearcher builds the summed area tables of g
+func newSearcher(g *gray) *searcher {
+	s := &searcher{gray: g, sum: make([]float64, (g.w+1)*(g.h+1)), sq: make([]float64, (g.w+1)*(g.h+1))}
+	stride := g.w + 1
+	for y := 0; y < g.h; y++ {
+		var rowSum, rowSq float64
+		for x := 0; x < g.w; x++ {
+			v := g.pix[y*g.w+x]
+			rowSum += v
+			rowSq += v * v
+			s.sum[(y+1)*stride+x+1] = s.sum[y*stride+x+1] + rowSum
+			s.sq[(y+1)*stride+x+1] = s.sq[y*stride+x+1] + rowSq
+		}
+	}
+	return s
+}
+
+// window returns the sum of table over the w by h window at (x, y)
+func (s *searcher) window(table []float64, x, y, w, h int) float64 {
+	stride := s.w + 1
+	return table[(y+h)*stride+x+w] - table[y*stride+x+w] - table[(y+h)*stride+x] + table[y*stride+x]
+}
+
+// score returns the normalized cross-correlation of t at (x, y), from -1 to 1.
+// Flat windows score zero.
+func (s *searcher) score(t *template, x, y int) float64 {
+	n := float64(t.w * t.h)
+	sum := s.window(s.sum, x, y, t.w, t.h)
+	variance := s.window(s.sq, x, y, t.w, t.h) - sum*sum/n
+	if variance <= 1e-6 {
+		return 0
+	}
+
+	// The template has zero mean, so the window mean drops out
+	var cross float64
+	for j := 0; j < t.h; j++ {
+		row := s.pix[(y+j)*s.w+x : (y+j)*s.w+x+t.w]
+		trow := t.pix[j*t.w : (j+1)*t.w]
+		for i, v := range trow {
+			cross += v * row[i]
+		}
+	}
+
+	return cross / (t.norm * math.Sqrt(variance))
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
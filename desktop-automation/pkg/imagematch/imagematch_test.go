@@ -0,0 +1,112 @@
+package imagematch
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"testing"
+)
+
+// noise returns a w by h image of random gray levels
+func noise(rng *rand.Rand, w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(rng.Intn(256))
+			img.Set(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	return img
+}
+
+// paste copies src into dst with its top left corner at (x, y)
+func paste(dst *image.RGBA, src image.Image, x, y int) {
+	b := src.Bounds()
+	draw.Draw(dst, image.Rect(x, y, x+b.Dx(), y+b.Dy()), src, b.Min, draw.Src)
+}
+
+func TestFind(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tmpl := noise(rng, 60, 40)
+
+	tests := []struct {
+		name    string
+		size    image.Point
+		at      []image.Point
+		opts    Options
+		want    []image.Point
+		wantErr bool
+	}{
+		{
+			name: "single match",
+			size: image.Pt(640, 480),
+			at:   []image.Point{{321, 157}},
+			want: []image.Point{{321, 157}},
+		},
+		{
+			name: "several matches",
+			size: image.Pt(640, 480),
+			at:   []image.Point{{10, 10}, {400, 300}, {555, 17}},
+			want: []image.Point{{10, 10}, {400, 300}, {555, 17}},
+		},
+		{
+			name: "max results",
+			size: image.Pt(640, 480),
+			at:   []image.Point{{10, 10}, {400, 300}},
+			opts: Options{MaxResults: 1},
+			want: []image.Point{{10, 10}},
+		},
+		{
+			name: "no match",
+			size: image.Pt(320, 240),
+		},
+		{
+			name:    "template larger than image",
+			size:    image.Pt(50, 50),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := noise(rng, tt.size.X, tt.size.Y)
+			for _, p := range tt.at {
+				paste(img, tmpl, p.X, p.Y)
+			}
+
+			result, err := Find(img, tmpl, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Find() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(result.Matches) != len(tt.want) {
+				t.Fatalf("Find() found %d matches %+v, want %d", len(result.Matches), result.Matches, len(tt.want))
+			}
+
+			// Exact copies are equally good, so compare them as a set
+			found := make(map[image.Point]bool)
+			for _, m := range result.Matches {
+				if m.Confidence < 0.99 || m.Width != 60 || m.Height != 40 {
+					t.Errorf("match %+v, want a 60x40 match with confidence 1", m)
+				}
+				found[image.Pt(m.X, m.Y)] = true
+			}
+			for _, p := range tt.want {
+				if !found[p] && tt.opts.MaxResults == 0 {
+					t.Errorf("no match at %v in %+v", p, result.Matches)
+				}
+			}
+		})
+	}
+}
+
+func TestFindFlatTemplate(t *testing.T) {
+	img := noise(rand.New(rand.NewSource(1)), 100, 100)
+
+	flat := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	if _, err := Find(img, flat, Options{}); !errors.Is(err, ErrNoContrast) {
+		t.Errorf("Find() error = %v, want ErrNoContrast", err)
+	}
+}
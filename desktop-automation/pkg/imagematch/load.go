@@ -0,0 +1,51 @@
+// Package imagematch finds a template image inside a larger image
+package imagematch
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"os"
+	"strings"
+
+	// Register the formats templates may be given in
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+// ReadFile decodes the PNG or JPEG template stored at path
+func ReadFile(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open template: %w", err)
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode template %s: %w", path, err)
+	}
+
+	return img, nil
+}
+
+// DecodeBase64 decodes a base64 encoded PNG or JPEG template. A data URL
+// prefix such as "data:image/png;base64," is accepted.
+func DecodeBase64(data string) (image.Image, error) {
+	if i := strings.Index(data, ";base64,"); strings.HasPrefix(data, "data:") && i >= 0 {
+		data = data[i+len(";base64,"):]
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 template: %w", err)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode template: %w", err)
+	}
+
+	return img, nil
+}
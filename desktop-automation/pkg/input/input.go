@@ -0,0 +1,108 @@
+// Package input captures global mouse and keyboard events, regardless of
+// which window has the focus
+package input
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrUnsupported is returned on platforms without an input capture integration
+var ErrUnsupported = errors.New("input capture is not supported on this platform")
+
+// Kind is the type of an input event
+type Kind string
+
+// Event kinds
+const (
+	KindMove       Kind = "move"
+	KindButtonDown Kind = "button_down"
+	KindButtonUp   Kind = "button_up"
+	KindKeyDown    Kind = "key_down"
+	KindKeyUp      Kind = "key_up"
+	KindScroll     Kind = "scroll"
+)
+
+// Event is a single change of the mouse or keyboard state
+type Event struct {
+	// Time is when the change was seen
+	Time time.Time
+	// Kind is the type of the event
+	Kind Kind
+	// X and Y are the cursor position in virtual desktop coordinates
+	X, Y int
+	// Button is the mouse button of a button event: left, middle or right
+	Button string
+	// Direction is which way a scroll event turned the wheel by one notch:
+	// up, down, left or right
+	Direction string
+	// Key is the name of the key of a key event as accepted in chords, like
+	// "a", "enter" or "ctrl"
+	Key string
+	// Text is the character a key down produces with the current shift
+	// state, or empty for keys that do not produce text
+	Text string
+}
+
+// String describes the event
+func (e Event) String() string {
+	switch e.Kind {
+	case KindButtonDown, KindButtonUp:
+		return fmt.Sprintf("%s %s at (%d, %d)", e.Kind, e.Button, e.X, e.Y)
+	case KindKeyDown, KindKeyUp:
+		return fmt.Sprintf("%s %s", e.Kind, e.Key)
+	case KindScroll:
+		return fmt.Sprintf("%s %s at (%d, %d)", e.Kind, e.Direction, e.X, e.Y)
+	}
+	return fmt.Sprintf("%s to (%d, %d)", e.Kind, e.X, e.Y)
+}
+
+// Source delivers global input events in the order they happened
+type Source interface {
+	// Next blocks until the next event. It returns io.EOF when the source
+	// has no more events and ctx.Err() when ctx is done.
+	Next(ctx context.Context) (Event, error)
+	// Close stops capturing
+	Close() error
+}
+
+// Degraded is implemented by sources that capture only part of the input,
+// so that callers can warn about it
+type Degraded interface {
+	// Warning explains what the source misses
+	Warning() string
+}
+
+// replay is a Source delivering a fixed list of events
+type replay struct {
+	events []Event
+}
+
+// NewReplay returns a Source delivering events and then io.EOF, to drive
+// recording without a desktop
+func NewReplay(events ...Event) Source {
+	return &replay{events: events}
+}
+
+// Next returns the next of the events
+func (r *replay) Next(ctx context.Context) (Event, error) {
+	if err := ctx.Err(); err != nil {
+		return Event{}, err
+	}
+	if len(r.events) == 0 {
+		return Event{}, io.EOF
+	}
+
+	e := r.events[0]
+	r.events = r.events[1:]
+	return e, nil
+}
+
+// Close drops the remaining events
+func (r *replay) Close() error {
+	r.events = nil
+	return nil
+}
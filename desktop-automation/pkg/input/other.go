@@ -0,0 +1,10 @@
+//go:build !linux
+
+// Package input captures global mouse and keyboard events, regardless of
+// which window has the focus
+package input
+
+// NewSource returns ErrUnsupported
+func NewSource() (Source, error) {
+	return nil, ErrUnsupported
+}
@@ -0,0 +1,278 @@
+//go:build linux
+
+// Package input captures global mouse and keyboard events, regardless of
+// which window has the focus
+package input
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// PollInterval is how often the polling fallback samples the pointer and
+// keyboard. Presses shorter than this may be missed.
+const PollInterval = 5 * time.Millisecond
+
+// NewSource starts capturing the input of the X display named by $DISPLAY.
+// It reads the stream of input events with the RECORD extension. X servers
+// without it are sampled every PollInterval instead; that source is
+// Degraded.
+func NewSource() (Source, error) {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the X server: %w", err)
+	}
+
+	keys, err := readKeymap(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	source, err := newRecordSource(conn, keys)
+	if err != nil {
+		return &pollSource{conn: conn, root: xproto.Setup(conn).DefaultScreen(conn).Root, keymap: keys, reason: err}, nil
+	}
+	return source, nil
+}
+
+// keymap names the keys of the X display
+type keymap struct {
+	minCode xproto.Keycode
+	perCode int
+	keysyms []xproto.Keysym
+}
+
+// readKeymap reads the keyboard mapping of the X display
+func readKeymap(conn *xgb.Conn) (keymap, error) {
+	setup := xproto.Setup(conn)
+	count := byte(setup.MaxKeycode - setup.MinKeycode + 1)
+	mapping, err := xproto.GetKeyboardMapping(conn, setup.MinKeycode, count).Reply()
+	if err != nil {
+		return keymap{}, fmt.Errorf("failed to read the keyboard mapping: %w", err)
+	}
+
+	return keymap{minCode: setup.MinKeycode, perCode: int(mapping.KeysymsPerKeycode), keysyms: mapping.Keysyms}, nil
+}
+
+// pollSource samples the pointer and the keymap of the X display and turns
+// the differences between samples into events. Sampling needs no extension
+// and sees input to every window, but misses wheel scrolling, whose button
+// presses are released before the next sample, and cannot order the changes
+// within one sample: they are reported in keycode order.
+type pollSource struct {
+	conn *xgb.Conn
+	root xproto.Window
+	keymap
+	// reason is why the input cannot be recorded instead
+	reason error
+
+	started bool
+	x, y    int
+	mask    uint16
+	keys    [32]byte
+	pending []Event
+}
+
+// Warning explains what sampling misses
+func (s *pollSource) Warning() string {
+	return fmt.Sprintf("cannot record the input stream (%v); sampling it every %s instead, which misses wheel scrolling and presses shorter than that, and orders keys pressed within one sample by keycode", s.reason, PollInterval)
+}
+
+// Next samples the input until it changes
+func (s *pollSource) Next(ctx context.Context) (Event, error) {
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+
+	for len(s.pending) == 0 {
+		if err := s.sample(); err != nil {
+			return Event{}, err
+		}
+		if len(s.pending) > 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return Event{}, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	e := s.pending[0]
+	s.pending = s.pending[1:]
+	return e, nil
+}
+
+// Close disconnects from the X server
+func (s *pollSource) Close() error {
+	s.conn.Close()
+	return nil
+}
+
+// pointerButtons maps the button bits of the pointer mask to button names
+var pointerButtons = []struct {
+	mask uint16
+	name string
+}{
+	{xproto.KeyButMaskButton1, "left"},
+	{xproto.KeyButMaskButton2, "middle"},
+	{xproto.KeyButMaskButton3, "right"},
+}
+
+// sample reads the pointer and keymap and queues an event for every change
+// since the previous sample. The first sample only sets the baseline, so
+// keys held when recording starts are not reported as pressed. Changes
+// within one sample share its time and are queued in keycode order.
+func (s *pollSource) sample() error {
+	pointer, err := xproto.QueryPointer(s.conn, s.root).Reply()
+	if err != nil {
+		return fmt.Errorf("failed to query the pointer: %w", err)
+	}
+	pressed, err := xproto.QueryKeymap(s.conn).Reply()
+	if err != nil {
+		return fmt.Errorf("failed to query the keyboard: %w", err)
+	}
+
+	now := time.Now()
+	x, y := int(pointer.RootX), int(pointer.RootY)
+	var keys [32]byte
+	copy(keys[:], pressed.Keys)
+
+	if !s.started {
+		s.started = true
+		s.x, s.y, s.mask, s.keys = x, y, pointer.Mask, keys
+		return nil
+	}
+
+	if x != s.x || y != s.y {
+		s.pending = append(s.pending, Event{Time: now, Kind: KindMove, X: x, Y: y})
+	}
+	for _, b := range pointerButtons {
+		was, is := s.mask&b.mask != 0, pointer.Mask&b.mask != 0
+		switch {
+		case is && !was:
+			s.pending = append(s.pending, Event{Time: now, Kind: KindButtonDown, X: x, Y: y, Button: b.name})
+		case was && !is:
+			s.pending = append(s.pending, Event{Time: now, Kind: KindButtonUp, X: x, Y: y, Button: b.name})
+		}
+	}
+
+	for code := 0; code < 256; code++ {
+		was, is := s.keys[code/8]&(1<<(code%8)) != 0, keys[code/8]&(1<<(code%8)) != 0
+		if was == is {
+			continue
+		}
+
+		name, text := s.describeKey(xproto.Keycode(code), pointer.Mask)
+		if name == "" {
+			continue
+		}
+		e := Event{Time: now, Kind: KindKeyUp, X: x, Y: y, Key: name}
+		if is {
+			e.Kind, e.Text = KindKeyDown, text
+		}
+		s.pending = append(s.pending, e)
+	}
+
+	s.x, s.y, s.mask, s.keys = x, y, pointer.Mask, keys
+	return nil
+}
+
+// describeKey returns the name of the key with the given code and the text
+// it produces with the modifiers of state, or an empty name for keys without
+// a chord name or character
+func (m keymap) describeKey(code xproto.Keycode, state uint16) (string, string) {
+	if code < m.minCode || m.perCode == 0 {
+		return "", ""
+	}
+	i := int(code-m.minCode) * m.perCode
+	if i >= len(m.keysyms) {
+		return "", ""
+	}
+
+	base := m.keysyms[i]
+	level := base
+	if m.perCode > 1 && m.keysyms[i+1] != 0 {
+		upper := m.keysyms[i+1]
+		isLetter := base >= 'a' && base <= 'z'
+		shifted := state&xproto.KeyButMaskShift != 0
+		capsLock := state&xproto.KeyButMaskLock != 0
+		if shifted != (capsLock && isLetter) {
+			level = upper
+		}
+	}
+
+	name := keysymName(base)
+	if name == "" {
+		// Keys without a chord name, such as accented letters, can still
+		// be typed
+		name = keysymText(base)
+	}
+	return name, keysymText(level)
+}
+
+// namedKeysyms maps the keysyms of non-character keys to their chord names
+var namedKeysyms = map[xproto.Keysym]string{
+	0xff08: "backspace",
+	0xff09: "tab",
+	0xff0d: "enter",
+	0xff8d: "enter",
+	0xff1b: "esc",
+	0xffff: "delete",
+	0xff63: "insert",
+	0xff50: "home",
+	0xff51: "left",
+	0xff52: "up",
+	0xff53: "right",
+	0xff54: "down",
+	0xff55: "pageup",
+	0xff56: "pagedown",
+	0xff57: "end",
+	0xff61: "printscreen",
+	0xff67: "menu",
+	0xffe5: "capslock",
+	0xffe1: "shift",
+	0xffe2: "shift",
+	0xffe3: "ctrl",
+	0xffe4: "ctrl",
+	0xffe9: "alt",
+	0xffea: "alt",
+	0xffe7: "cmd",
+	0xffe8: "cmd",
+	0xffeb: "cmd",
+	0xffec: "cmd",
+}
+
+// keysymName returns the chord name of a keysym, or "" if it has none
+func keysymName(sym xproto.Keysym) string {
+	if name, ok := namedKeysyms[sym]; ok {
+		return name
+	}
+	if sym >= 0xffbe && sym <= 0xffd5 {
+		return fmt.Sprintf("f%d", sym-0xffbe+1)
+	}
+	if sym == ' ' {
+		return "space"
+	}
+	if sym >= 'A' && sym <= 'Z' {
+		sym += 'a' - 'A'
+	}
+	if sym > ' ' && sym <= '~' {
+		return string(rune(sym))
+	}
+	return ""
+}
+
+// keysymText returns the character of a Latin-1 keysym, or "" for keysyms
+// that do not produce text
+func keysymText(sym xproto.Keysym) string {
+	if (sym >= ' ' && sym <= '~') || (sym >= 0xa0 && sym <= 0xff) {
+		return string(rune(sym))
+	}
+	return ""
+}
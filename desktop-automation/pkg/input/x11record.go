@@ -0,0 +1,386 @@
+//go:build linux
+
+// Package input captures global mouse and keyboard events, regardless of
+// which window has the focus
+package input
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/record"
+	"github.com/jezek/xgb/xproto"
+)
+
+// Categories of the replies of a RECORD context
+const (
+	recordFromServer  = 0
+	recordStartOfData = 4
+	recordEndOfData   = 5
+)
+
+// recordEnableContext is the minor opcode of the RECORD EnableContext request
+const recordEnableContext = 5
+
+// eventButtons names the mouse buttons of X11 button events
+var eventButtons = map[xproto.Button]string{1: "left", 2: "middle", 3: "right"}
+
+// scrollButtons maps the wheel buttons of X11 to scroll directions
+var scrollButtons = map[xproto.Button]string{4: "up", 5: "down", 6: "left", 7: "right"}
+
+// recordSource reads the input events of the X display from a context of the
+// RECORD extension, in the order the server processed them. The context is
+// enabled on a connection of its own: the server answers that request with a
+// stream of replies, which xgb cannot deliver since it expects a single reply
+// per request.
+type recordSource struct {
+	conn    *xgb.Conn
+	context record.Context
+	data    net.Conn
+	keymap
+
+	events chan Event
+	// err is why events was closed
+	err  error
+	done chan struct{}
+}
+
+// newRecordSource starts recording the device events of the X server of conn
+func newRecordSource(conn *xgb.Conn, keys keymap) (*recordSource, error) {
+	if err := record.Init(conn); err != nil {
+		return nil, fmt.Errorf("the X server lacks the RECORD extension: %w", err)
+	}
+	if _, err := record.QueryVersion(conn, 1, 13).Reply(); err != nil {
+		return nil, fmt.Errorf("failed to query the RECORD extension: %w", err)
+	}
+
+	id, err := record.NewContextId(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate a RECORD context: %w", err)
+	}
+	ranges := []record.Range{{DeviceEvents: record.Range8{First: xproto.KeyPress, Last: xproto.MotionNotify}}}
+	clients := []record.ClientSpec{record.CsAllClients}
+	if err := record.CreateContextChecked(conn, id, 0, uint32(len(clients)), uint32(len(ranges)), clients, ranges).Check(); err != nil {
+		return nil, fmt.Errorf("failed to create a RECORD context: %w", err)
+	}
+
+	data, err := enableContext(conn, id)
+	if err != nil {
+		record.FreeContext(conn, id)
+		return nil, err
+	}
+
+	s := &recordSource{
+		conn:    conn,
+		context: id,
+		data:    data,
+		keymap:  keys,
+		events:  make(chan Event, 256),
+		done:    make(chan struct{}),
+	}
+	go s.read()
+
+	return s, nil
+}
+
+// enableContext enables the RECORD context id on a new connection to the X
+// display and waits for its data to start
+func enableContext(conn *xgb.Conn, id record.Context) (net.Conn, error) {
+	conn.ExtLock.RLock()
+	opcode := conn.Extensions["RECORD"]
+	conn.ExtLock.RUnlock()
+
+	data, err := dialDisplay(os.Getenv("DISPLAY"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open a connection for recording: %w", err)
+	}
+
+	req := make([]byte, 8)
+	req[0], req[1] = opcode, recordEnableContext
+	binary.LittleEndian.PutUint16(req[2:], uint16(len(req)/4))
+	binary.LittleEndian.PutUint32(req[4:], uint32(id))
+	if _, err := data.Write(req); err != nil {
+		data.Close()
+		return nil, fmt.Errorf("failed to enable the RECORD context: %w", err)
+	}
+
+	category, _, err := readRecordReply(data)
+	if err == nil && category != recordStartOfData {
+		err = fmt.Errorf("unexpected RECORD reply of category %d", category)
+	}
+	if err != nil {
+		data.Close()
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Next waits for the next recorded event
+func (s *recordSource) Next(ctx context.Context) (Event, error) {
+	select {
+	case e, ok := <-s.events:
+		if !ok {
+			return Event{}, s.err
+		}
+		return e, nil
+	case <-ctx.Done():
+		return Event{}, ctx.Err()
+	}
+}
+
+// Close stops recording and disconnects from the X server
+func (s *recordSource) Close() error {
+	close(s.done)
+	record.DisableContextChecked(s.conn, s.context).Check()
+	record.FreeContext(s.conn, s.context)
+	s.data.Close()
+	for range s.events {
+		// Wait for the reader to stop
+	}
+	s.conn.Close()
+	return nil
+}
+
+// read passes the recorded events to Next until the context is disabled or
+// the connection fails
+func (s *recordSource) read() {
+	defer close(s.events)
+
+	for {
+		category, data, err := readRecordReply(s.data)
+		if err != nil {
+			s.err = err
+			return
+		}
+		if category == recordEndOfData {
+			s.err = io.EOF
+			return
+		}
+		if category != recordFromServer {
+			continue
+		}
+
+		now := time.Now()
+		for ; len(data) >= 32; data = data[32:] {
+			e, ok := s.decode(data[:32], now)
+			if !ok {
+				continue
+			}
+			select {
+			case s.events <- e:
+			case <-s.done:
+				s.err = io.EOF
+				return
+			}
+		}
+	}
+}
+
+// decode turns a recorded device event into an Event. Wheel buttons become
+// one scroll event per notch; their releases, and buttons and keys without a
+// name, are dropped.
+func (s *recordSource) decode(buf []byte, now time.Time) (Event, bool) {
+	switch code := buf[0] & 0x7f; code {
+	case xproto.KeyPress, xproto.KeyRelease:
+		ev := xproto.KeyPressEventNew(buf).(xproto.KeyPressEvent)
+		name, text := s.describeKey(ev.Detail, ev.State)
+		if name == "" {
+			return Event{}, false
+		}
+		e := Event{Time: now, Kind: KindKeyUp, X: int(ev.RootX), Y: int(ev.RootY), Key: name}
+		if code == xproto.KeyPress {
+			e.Kind, e.Text = KindKeyDown, text
+		}
+		return e, true
+
+	case xproto.ButtonPress, xproto.ButtonRelease:
+		ev := xproto.ButtonPressEventNew(buf).(xproto.ButtonPressEvent)
+		e := Event{Time: now, X: int(ev.RootX), Y: int(ev.RootY)}
+		if direction, ok := scrollButtons[ev.Detail]; ok {
+			e.Kind, e.Direction = KindScroll, direction
+			return e, code == xproto.ButtonPress
+		}
+		name, ok := eventButtons[ev.Detail]
+		if !ok {
+			return Event{}, false
+		}
+		e.Kind, e.Button = KindButtonUp, name
+		if code == xproto.ButtonPress {
+			e.Kind = KindButtonDown
+		}
+		return e, true
+
+	case xproto.MotionNotify:
+		ev := xproto.MotionNotifyEventNew(buf).(xproto.MotionNotifyEvent)
+		return Event{Time: now, Kind: KindMove, X: int(ev.RootX), Y: int(ev.RootY)}, true
+	}
+
+	return Event{}, false
+}
+
+// readRecordReply reads the next reply of an enabled RECORD context and
+// returns its category and recorded data. Data recorded in the byte order of
+// another client is dropped; device events never are.
+func readRecordReply(r io.Reader) (int, []byte, error) {
+	for {
+		head := make([]byte, 32)
+		if _, err := io.ReadFull(r, head); err != nil {
+			return 0, nil, fmt.Errorf("failed to read recorded input: %w", err)
+		}
+		switch head[0] {
+		case 0:
+			return 0, nil, fmt.Errorf("the X server refused to record input (error code %d)", head[1])
+		case 1:
+		default:
+			// Events are not sent on the recording connection
+			continue
+		}
+
+		data := make([]byte, int(binary.LittleEndian.Uint32(head[4:]))*4)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return 0, nil, fmt.Errorf("failed to read recorded input: %w", err)
+		}
+		if swapped := head[10] != 0; swapped {
+			continue
+		}
+		return int(head[1]), data, nil
+	}
+}
+
+// dialDisplay connects to an X display named like ":0" or "host:0.0" and
+// completes the connection setup, authenticating with the cookie of
+// $XAUTHORITY if it has one. The connection uses little-endian byte order.
+func dialDisplay(display string) (net.Conn, error) {
+	colon := strings.LastIndex(display, ":")
+	if colon < 0 {
+		return nil, fmt.Errorf("invalid display %q", display)
+	}
+	host := strings.TrimPrefix(display[:colon], "tcp/")
+	number, _, _ := strings.Cut(display[colon+1:], ".")
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return nil, fmt.Errorf("invalid display %q", display)
+	}
+
+	var conn net.Conn
+	if host == "" || host == "unix" {
+		conn, err = net.Dial("unix", "/tmp/.X11-unix/X"+number)
+	} else {
+		conn, err = net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(6000+n)))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setupConnection(conn, host, number); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// setupConnection sends the connection setup of the X protocol and discards
+// the server's description of itself, which xgb already has
+func setupConnection(conn net.Conn, host, number string) error {
+	name, cookie := readXauthority(host, number)
+
+	req := make([]byte, 12+pad(len(name))+pad(len(cookie)))
+	req[0] = 'l'
+	binary.LittleEndian.PutUint16(req[2:], 11)
+	binary.LittleEndian.PutUint16(req[6:], uint16(len(name)))
+	binary.LittleEndian.PutUint16(req[8:], uint16(len(cookie)))
+	copy(req[12:], name)
+	copy(req[12+pad(len(name)):], cookie)
+	if _, err := conn.Write(req); err != nil {
+		return fmt.Errorf("failed to set up the connection: %w", err)
+	}
+
+	head := make([]byte, 8)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return fmt.Errorf("failed to set up the connection: %w", err)
+	}
+	rest := make([]byte, int(binary.LittleEndian.Uint16(head[6:]))*4)
+	if _, err := io.ReadFull(conn, rest); err != nil {
+		return fmt.Errorf("failed to set up the connection: %w", err)
+	}
+	if head[0] != 1 {
+		reason := rest[:min(int(head[1]), len(rest))]
+		return fmt.Errorf("the X server refused the connection: %s", reason)
+	}
+
+	return nil
+}
+
+// readXauthority returns the MIT-MAGIC-COOKIE-1 cookie of the display in
+// $XAUTHORITY or ~/.Xauthority, or nothing if there is none
+func readXauthority(host, number string) (string, []byte) {
+	const (
+		familyLocal = 256
+		familyWild  = 65535
+		cookieName  = "MIT-MAGIC-COOKIE-1"
+	)
+
+	path := os.Getenv("XAUTHORITY")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", nil
+		}
+		path = home + "/.Xauthority"
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil
+	}
+	if host == "" || host == "unix" || host == "localhost" {
+		host, _ = os.Hostname()
+	}
+
+	// Entries are a family followed by an address, a display number, an
+	// authorization name and its data, each prefixed by its length
+	field := func() ([]byte, error) {
+		if len(data) < 2 {
+			return nil, errors.New("truncated")
+		}
+		n := int(binary.BigEndian.Uint16(data))
+		if len(data) < 2+n {
+			return nil, errors.New("truncated")
+		}
+		f := data[2 : 2+n]
+		data = data[2+n:]
+		return f, nil
+	}
+	for len(data) >= 2 {
+		family := binary.BigEndian.Uint16(data)
+		data = data[2:]
+		var fields [4][]byte
+		for i := range fields {
+			if fields[i], err = field(); err != nil {
+				return "", nil
+			}
+		}
+		addr, disp, name, cookie := string(fields[0]), string(fields[1]), string(fields[2]), fields[3]
+
+		hostMatch := family == familyWild || (family == familyLocal && addr == host)
+		if hostMatch && (disp == "" || disp == number) && name == cookieName {
+			return name, cookie
+		}
+	}
+
+	return "", nil
+}
+
+// pad rounds n up to a multiple of 4, the unit of the X protocol
+func pad(n int) int {
+	return (n + 3) &^ 3
+}
@@ -0,0 +1,85 @@
+//go:build linux
+
+package input
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+)
+
+// recordReply encodes a reply of a RECORD context carrying data
+func recordReply(category byte, data ...[]byte) []byte {
+	body := bytes.Join(data, nil)
+	head := make([]byte, 32)
+	head[0], head[1] = 1, category
+	binary.LittleEndian.PutUint32(head[4:], uint32(len(body)/4))
+	return append(head, body...)
+}
+
+func TestRecordSource(t *testing.T) {
+	// Keycode 10 is a/A, keycode 11 is shift
+	keys := keymap{minCode: 10, perCode: 2, keysyms: []xproto.Keysym{'a', 'A', 0xffe1, 0}}
+	stream := bytes.Join([][]byte{
+		recordReply(recordFromServer,
+			xproto.MotionNotifyEvent{RootX: 5, RootY: 6}.Bytes(),
+			xproto.ButtonPressEvent{Detail: 1, RootX: 5, RootY: 6}.Bytes(),
+			xproto.ButtonReleaseEvent{Detail: 1, RootX: 5, RootY: 6}.Bytes(),
+			xproto.ButtonPressEvent{Detail: 5, RootX: 7, RootY: 8}.Bytes(),
+			xproto.ButtonReleaseEvent{Detail: 5, RootX: 7, RootY: 8}.Bytes(),
+			xproto.ButtonPressEvent{Detail: 6, RootX: 7, RootY: 8}.Bytes(),
+		),
+		recordReply(recordFromServer,
+			xproto.KeyPressEvent{Detail: 11}.Bytes(),
+			xproto.KeyPressEvent{Detail: 10, State: xproto.KeyButMaskShift}.Bytes(),
+			xproto.KeyReleaseEvent{Detail: 10, State: xproto.KeyButMaskShift}.Bytes(),
+			xproto.KeyPressEvent{Detail: 99}.Bytes(),
+		),
+		recordReply(recordEndOfData),
+	}, nil)
+
+	now := time.Now()
+	s := &recordSource{keymap: keys}
+	r := bytes.NewReader(stream)
+	var got []Event
+	for {
+		category, data, err := readRecordReply(r)
+		if err != nil {
+			t.Fatalf("readRecordReply() failed: %v", err)
+		}
+		if category == recordEndOfData {
+			break
+		}
+		for ; len(data) >= 32; data = data[32:] {
+			if e, ok := s.decode(data[:32], now); ok {
+				got = append(got, e)
+			}
+		}
+	}
+
+	want := []Event{
+		{Time: now, Kind: KindMove, X: 5, Y: 6},
+		{Time: now, Kind: KindButtonDown, X: 5, Y: 6, Button: "left"},
+		{Time: now, Kind: KindButtonUp, X: 5, Y: 6, Button: "left"},
+		{Time: now, Kind: KindScroll, X: 7, Y: 8, Direction: "down"},
+		{Time: now, Kind: KindScroll, X: 7, Y: 8, Direction: "left"},
+		{Time: now, Kind: KindKeyDown, Key: "shift"},
+		{Time: now, Kind: KindKeyDown, Key: "a", Text: "A"},
+		{Time: now, Kind: KindKeyUp, Key: "a"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
+
+func TestRecordRefused(t *testing.T) {
+	refusal := make([]byte, 32)
+	refusal[1] = 8 // BadMatch
+	if _, _, err := readRecordReply(bytes.NewReader(refusal)); err == nil {
+		t.Error("readRecordReply() of an X error succeeded, want an error")
+	}
+}
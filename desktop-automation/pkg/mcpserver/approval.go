@@ -0,0 +1,322 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"slices"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/audit"
+)
+
+// DefaultApprovalTimeout is how long a call waits for approval unless
+// Approval.Timeout is set
+const DefaultApprovalTimeout = time.Minute
+
+// maxArgumentLength bounds the characters of a binary argument shown to
+// approvers
+const maxArgumentLength = 200
+
+// binaryArguments are the arguments that carry encoded data rather than text
+// a human can read. They are shortened for approvers; every other argument is
+// shown in full, since approving a call approves all of it.
+var binaryArguments = []string{"image_base64"}
+
+// ApprovalRequest is a tool call waiting for a human decision
+type ApprovalRequest struct {
+	// ID identifies the request, e.g. in the HTTP approval endpoint
+	ID string `json:"id"`
+	// Tool is the tool that was called
+	Tool string `json:"tool"`
+	// Arguments are the arguments of the call, with long binary data such as
+	// base64 images shortened; text is complete
+	Arguments map[string]any `json:"arguments,omitempty"`
+	// Requested is when the call arrived
+	Requested time.Time `json:"requested"`
+	// Deadline is when the call is denied unless someone decides first
+	Deadline time.Time `json:"deadline"`
+}
+
+// String describes the request for prompts, e.g. `keyboard_type {"text":"hi"}`
+func (r ApprovalRequest) String() string {
+	if len(r.Arguments) == 0 {
+		return r.Tool
+	}
+	args, err := json.Marshal(r.Arguments)
+	if err != nil {
+		return r.Tool
+	}
+	return fmt.Sprintf("%s %s", r.Tool, args)
+}
+
+// Verdict is the answer of an approver
+type Verdict struct {
+	// Approved lets the call proceed
+	Approved bool
+	// By names who decided, e.g. "terminal"
+	By string
+	// Reason optionally explains the decision
+	Reason string
+}
+
+// Approver asks a human whether tool calls may proceed
+type Approver interface {
+	// Approve blocks until someone decides on req or ctx is done, in which
+	// case it returns ctx.Err()
+	Approve(ctx context.Context, req ApprovalRequest) (Verdict, error)
+}
+
+// Decision is a recorded approval decision. In the decision log, typed text
+// among its arguments is redacted like in the audit log.
+type Decision struct {
+	ApprovalRequest
+	// Approved is whether the call went ahead
+	Approved bool `json:"approved"`
+	// By names who decided: an approver, or "timeout" or "canceled" when
+	// nobody did
+	By string `json:"by"`
+	// Reason explains the decision, if known
+	Reason string `json:"reason,omitempty"`
+	// Decided is when the decision was made
+	Decided time.Time `json:"decided"`
+}
+
+// Approval holds calls of the tools it names until a human approves them.
+// Calls nobody approves within the timeout are denied.
+type Approval struct {
+	// Tools are the tools that need approval, as names or path.Match
+	// patterns like "keyboard_*"
+	Tools []string
+	// Timeout is how long a call waits before it is denied;
+	// DefaultApprovalTimeout if zero
+	Timeout time.Duration
+	// Terminal asks on the controlling terminal of the process
+	Terminal bool
+	// Addr, if set, serves the approval endpoint of an HTTPApprover there.
+	// It must be a loopback address, like DefaultApprovalAddr.
+	Addr string
+	// Token is the secret that requests to the approval endpoint must send
+	// as a bearer token; required with Addr. See NewApprovalToken.
+	Token string
+	// Approvers are asked besides the terminal and the HTTP endpoint
+	Approvers []Approver
+	// Log, if set, receives every decision as a line of JSON
+	Log io.Writer
+	// Redact is how typed text is written to Log; audit.RedactMask if empty
+	Redact audit.Redaction
+
+	approvers []Approver
+	logMu     sync.Mutex
+}
+
+// Requires reports whether calls of the named tool need approval
+func (a *Approval) Requires(tool string) bool {
+	return matchesAny(a.Tools, tool)
+}
+
+// install makes calls of s to the tools that need approval wait for it,
+// starting the terminal and HTTP approvers until ctx is done. A denied call
+// returns a tool error.
+func (a *Approval) install(ctx context.Context, s *server.MCPServer) error {
+	for _, pattern := range a.Tools {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	if a.Timeout < 0 {
+		return fmt.Errorf("invalid approval timeout: %s (must be positive)", a.Timeout)
+	}
+	if _, err := audit.ParseRedaction(string(a.Redact)); err != nil {
+		return err
+	}
+
+	a.approvers = slices.Clone(a.Approvers)
+	if a.Terminal {
+		terminal, err := OpenTerminalApprover()
+		if err != nil {
+			return err
+		}
+		a.approvers = append(a.approvers, terminal)
+	}
+	if a.Addr != "" {
+		if a.Token == "" {
+			return errors.New("the HTTP approval endpoint needs a token")
+		}
+		ln, err := net.Listen("tcp", a.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen for approvals on %s: %w", a.Addr, err)
+		}
+		if tcp, ok := ln.Addr().(*net.TCPAddr); !ok || !tcp.IP.IsLoopback() {
+			ln.Close()
+			return fmt.Errorf("invalid approval address: %s (must be a loopback address like %s)", a.Addr, DefaultApprovalAddr)
+		}
+		endpoint := NewHTTPApprover(a.Token)
+		go endpoint.Serve(ctx, ln)
+		a.approvers = append(a.approvers, endpoint)
+	}
+	if len(a.Tools) > 0 && len(a.approvers) == 0 {
+		return errors.New("tools need approval but no approver is configured")
+	}
+
+	server.WithToolHandlerMiddleware(func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !a.Requires(req.Params.Name) {
+				return next(ctx, req)
+			}
+			if d := a.decide(ctx, req); !d.Approved {
+				return d.result(), nil
+			}
+			return next(ctx, req)
+		}
+	})(s)
+
+	return nil
+}
+
+// decide asks the approvers about a call and records the decision
+func (a *Approval) decide(ctx context.Context, call mcp.CallToolRequest) Decision {
+	timeout := a.Timeout
+	if timeout == 0 {
+		timeout = DefaultApprovalTimeout
+	}
+	now := time.Now()
+	req := ApprovalRequest{
+		ID:        newApprovalID(),
+		Tool:      call.Params.Name,
+		Arguments: displayArguments(call.GetArguments()),
+		Requested: now,
+		Deadline:  now.Add(timeout),
+	}
+
+	d := Decision{ApprovalRequest: req}
+	verdict, err := a.ask(ctx, req)
+	switch {
+	case err == nil:
+		d.Approved, d.By, d.Reason = verdict.Approved, verdict.By, verdict.Reason
+	case errors.Is(err, context.DeadlineExceeded):
+		d.By, d.Reason = "timeout", fmt.Sprintf("no decision within %s", timeout)
+	case errors.Is(err, context.Canceled):
+		d.By, d.Reason = "canceled", "the call was canceled while waiting"
+	default:
+		d.By, d.Reason = "error", err.Error()
+	}
+	d.Decided = time.Now()
+
+	// The approvers saw the text to decide on it, but the log keeps it out
+	logged := d
+	logged.Arguments = audit.RedactArguments(call.GetArguments(), audit.Options{Redact: a.Redact})
+	a.record(logged)
+	return d
+}
+
+// ask asks every approver at once and returns the first verdict. It fails
+// if ctx is done or every approver fails.
+func (a *Approval) ask(ctx context.Context, req ApprovalRequest) (Verdict, error) {
+	ctx, cancel := context.WithDeadline(ctx, req.Deadline)
+	defer cancel()
+
+	type answer struct {
+		verdict Verdict
+		err     error
+	}
+	answers := make(chan answer, len(a.approvers))
+	for _, approver := range a.approvers {
+		go func() {
+			verdict, err := approver.Approve(ctx, req)
+			answers <- answer{verdict, err}
+		}()
+	}
+
+	var errs []error
+	for range a.approvers {
+		select {
+		case ans := <-answers:
+			if ans.err == nil {
+				return ans.verdict, nil
+			}
+			if ctx.Err() == nil {
+				errs = append(errs, ans.err)
+			}
+		case <-ctx.Done():
+			return Verdict{}, ctx.Err()
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return Verdict{}, err
+	}
+	return Verdict{}, fmt.Errorf("no approver could ask: %w", errors.Join(errs...))
+}
+
+// record writes the decision to the log
+func (a *Approval) record(d Decision) {
+	if a.Log == nil {
+		return
+	}
+	data, err := json.Marshal(d)
+	if err != nil {
+		return
+	}
+
+	a.logMu.Lock()
+	defer a.logMu.Unlock()
+	a.Log.Write(append(data, '\n'))
+}
+
+// result returns a denial as a tool error whose text is a JSON object with
+// the error category, request ID, tool, decider and reason
+func (d Decision) result() *mcp.CallToolResult {
+	data, err := json.Marshal(map[string]string{
+		"error":  "approval_denied",
+		"id":     d.ID,
+		"tool":   d.Tool,
+		"by":     d.By,
+		"reason": d.Reason,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("%s was not approved: %s", d.Tool, d.Reason))
+	}
+	return mcp.NewToolResultError(string(data))
+}
+
+// newApprovalID returns a short random request ID
+func newApprovalID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// NewApprovalToken returns a random token for the HTTP approval endpoint
+func NewApprovalToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// displayArguments copies args with long binary arguments, like base64
+// images, shortened for display
+func displayArguments(args map[string]any) map[string]any {
+	if len(args) == 0 {
+		return nil
+	}
+
+	short := make(map[string]any, len(args))
+	for k, v := range args {
+		if s, ok := v.(string); ok && slices.Contains(binaryArguments, k) && utf8.RuneCountInString(s) > maxArgumentLength {
+			v = fmt.Sprintf("%s… (%d characters)", string([]rune(s)[:maxArgumentLength]), utf8.RuneCountInString(s))
+		}
+		short[k] = v
+	}
+	return short
+}
@@ -0,0 +1,452 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/window"
+)
+
+// approverFunc adapts a function to the Approver interface
+type approverFunc func(ctx context.Context, req ApprovalRequest) (Verdict, error)
+
+func (f approverFunc) Approve(ctx context.Context, req ApprovalRequest) (Verdict, error) {
+	return f(ctx, req)
+}
+
+// newApprovalClient starts an in-process client of a server on the backend
+// that holds calls for approval
+func newApprovalClient(t *testing.T, backend *automation.FakeBackend, approval *Approval) *client.Client {
+	t.Helper()
+
+	s := New(automation.NewMouseWithBackend(backend), automation.NewKeyboardWithBackend(backend), automation.NewScreenWithBackend(backend), automation.NewWindowsWithBackend(backend), automation.NewClipboardWithBackend(backend))
+	if err := approval.install(context.Background(), s); err != nil {
+		t.Fatalf("install() failed: %v", err)
+	}
+
+	return newInProcessClient(t, s)
+}
+
+func TestApproval(t *testing.T) {
+	approve := approverFunc(func(ctx context.Context, req ApprovalRequest) (Verdict, error) {
+		return Verdict{Approved: true, By: "test"}, nil
+	})
+	deny := approverFunc(func(ctx context.Context, req ApprovalRequest) (Verdict, error) {
+		return Verdict{By: "test", Reason: "not now"}, nil
+	})
+	never := approverFunc(func(ctx context.Context, req ApprovalRequest) (Verdict, error) {
+		<-ctx.Done()
+		return Verdict{}, ctx.Err()
+	})
+	broken := approverFunc(func(ctx context.Context, req ApprovalRequest) (Verdict, error) {
+		return Verdict{}, errors.New("terminal closed")
+	})
+
+	typed := []automation.Event{{Kind: automation.EventType, Text: "hi"}}
+	tests := []struct {
+		name      string
+		approvers []Approver
+		tool      string
+		args      map[string]any
+		wantBy    string
+		want      []automation.Event
+	}{
+		{name: "approved", approvers: []Approver{approve}, tool: "keyboard_type", args: map[string]any{"text": "hi"}, wantBy: "test", want: typed},
+		{name: "denied", approvers: []Approver{deny}, tool: "keyboard_type", args: map[string]any{"text": "hi"}, wantBy: "test"},
+		{name: "timeout", approvers: []Approver{never}, tool: "keyboard_type", args: map[string]any{"text": "hi"}, wantBy: "timeout"},
+		{name: "first decision wins", approvers: []Approver{never, broken, approve}, tool: "keyboard_type", args: map[string]any{"text": "hi"}, wantBy: "test", want: typed},
+		{name: "every approver fails", approvers: []Approver{broken}, tool: "keyboard_type", args: map[string]any{"text": "hi"}, wantBy: "error"},
+		{
+			name:      "no approval needed",
+			approvers: []Approver{deny},
+			tool:      "mouse_move",
+			args:      map[string]any{"x": 1, "y": 2},
+			want:      []automation.Event{{Kind: automation.EventMove, X: 1, Y: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := automation.NewFakeBackend()
+			var log bytes.Buffer
+			approval := &Approval{Tools: []string{"keyboard_*"}, Timeout: 50 * time.Millisecond, Approvers: tt.approvers, Log: &log}
+			c := newApprovalClient(t, backend, approval)
+
+			result := callTool(t, c, tt.tool, tt.args)
+			approved := tt.want != nil
+			if result.IsError == approved {
+				t.Fatalf("%s result = %s, want approved %v", tt.tool, resultText(result), approved)
+			}
+			if tt.want == nil {
+				tt.want = []automation.Event{}
+			}
+			if got := backend.Events(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %+v, want %+v", got, tt.want)
+			}
+
+			if tt.wantBy == "" {
+				if log.Len() != 0 {
+					t.Errorf("decision log = %s, want nothing", log.String())
+				}
+				return
+			}
+			var d Decision
+			if err := json.Unmarshal(log.Bytes(), &d); err != nil {
+				t.Fatalf("decision log %q is not JSON: %v", log.String(), err)
+			}
+			if d.Tool != tt.tool || d.Approved != approved || d.By != tt.wantBy || d.ID == "" || d.Arguments["text"] != "[redacted 2 characters]" {
+				t.Errorf("decision = %+v, want approved %v of %s by %s", d, approved, tt.tool, tt.wantBy)
+			}
+			if !approved {
+				var denial map[string]string
+				if err := json.Unmarshal([]byte(resultText(result)), &denial); err != nil {
+					t.Fatalf("denial %q is not JSON: %v", resultText(result), err)
+				}
+				if denial["error"] != "approval_denied" || denial["id"] != d.ID || denial["by"] != tt.wantBy {
+					t.Errorf("denial = %v, want approval_denied of %s by %s", denial, d.ID, tt.wantBy)
+				}
+			}
+		})
+	}
+}
+
+func TestApprovalArguments(t *testing.T) {
+	text := strings.Repeat("a", 500)
+	image := strings.Repeat("A", 500)
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want map[string]any
+	}{
+		{name: "text is shown in full", tool: "keyboard_type", args: map[string]any{"text": text}, want: map[string]any{"text": text}},
+		{name: "clipboard text is shown in full", tool: "clipboard_set", args: map[string]any{"text": text}, want: map[string]any{"text": text}},
+		{
+			name: "images are shortened",
+			tool: "screen_find_image",
+			args: map[string]any{"image_base64": image},
+			want: map[string]any{"image_base64": strings.Repeat("A", maxArgumentLength) + "… (500 characters)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shown := make(chan map[string]any, 1)
+			deny := approverFunc(func(ctx context.Context, req ApprovalRequest) (Verdict, error) {
+				shown <- req.Arguments
+				return Verdict{By: "test"}, nil
+			})
+			approval := &Approval{Tools: []string{"*"}, Approvers: []Approver{deny}}
+			c := newApprovalClient(t, automation.NewFakeBackend(), approval)
+
+			callTool(t, c, tt.tool, tt.args)
+			if got := <-shown; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("approver was shown %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyBeforeApproval(t *testing.T) {
+	policy, err := ParsePolicy([]byte(`pointer: {regions: ["0,0,400,300"]}`))
+	if err != nil {
+		t.Fatalf("ParsePolicy() failed: %v", err)
+	}
+	backend := automation.NewFakeBackend()
+	backend.SetWindows(window.Window{ID: 0x100, Title: "Editor", X: 350, Y: 250, Width: 200, Height: 100})
+
+	asked := make(chan string, 10)
+	approve := approverFunc(func(ctx context.Context, req ApprovalRequest) (Verdict, error) {
+		asked <- req.Tool
+		return Verdict{Approved: true, By: "test"}, nil
+	})
+	config := &Config{
+		Transport: TransportHTTP,
+		Policy:    policy,
+		Approval:  &Approval{Tools: []string{"mouse_*"}, Approvers: []Approver{approve}},
+	}
+	c := newLoopbackClient(t, TransportHTTP, startLoopbackServer(t, config, backend))
+
+	tests := []struct {
+		name  string
+		tool  string
+		args  map[string]any
+		asked bool
+	}{
+		{name: "allowed click", tool: "mouse_click", args: map[string]any{"x": 10, "y": 20}, asked: true},
+		{name: "click outside the regions", tool: "mouse_click", args: map[string]any{"x": 500, "y": 20}},
+		{name: "click in a window outside the regions", tool: "mouse_click", args: map[string]any{"x": 100, "y": 10, "window_title": "Editor"}},
+		{name: "click in an unknown window", tool: "mouse_click", args: map[string]any{"x": 1, "y": 1, "window_title": "Browser"}},
+		{name: "drag out of the regions", tool: "mouse_drag", args: map[string]any{"from_x": 10, "from_y": 10, "to_x": 600, "to_y": 10}},
+		{name: "scroll outside the regions", tool: "mouse_scroll", args: map[string]any{"direction": "down", "amount": 1, "x": 10, "y": 400}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, c, tt.tool, tt.args)
+			if result.IsError == tt.asked {
+				t.Errorf("%s result = %s, want error %v", tt.tool, resultText(result), !tt.asked)
+			}
+			select {
+			case tool := <-asked:
+				if !tt.asked {
+					t.Errorf("approver was asked about %s, which the policy refuses", tool)
+				}
+			default:
+				if tt.asked {
+					t.Errorf("approver was not asked about %s", tt.tool)
+				}
+			}
+		})
+	}
+}
+
+func TestApprovalWithoutApprover(t *testing.T) {
+	approval := &Approval{Tools: []string{"keyboard_*"}}
+	backend := automation.NewFakeBackend()
+	s := New(automation.NewMouseWithBackend(backend), automation.NewKeyboardWithBackend(backend), automation.NewScreenWithBackend(backend), automation.NewWindowsWithBackend(backend), automation.NewClipboardWithBackend(backend))
+	if err := approval.install(context.Background(), s); err == nil {
+		t.Error("install() without approvers succeeded, want an error")
+	}
+}
+
+// promptWriter passes every prompt written to it to a channel
+type promptWriter chan string
+
+func (w promptWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestTerminalApprover(t *testing.T) {
+	in, answers := io.Pipe()
+	prompts := make(promptWriter, 10)
+	approver := NewTerminalApprover(in, prompts)
+	req := ApprovalRequest{ID: "abc", Tool: "keyboard_type", Arguments: map[string]any{"text": "hi"}, Deadline: time.Now().Add(time.Minute)}
+
+	for _, tt := range []struct {
+		answer   string
+		approved bool
+	}{
+		{"y", true},
+		{"YES", true},
+		{"n", false},
+		{"", false},
+	} {
+		done := make(chan Verdict, 1)
+		go func() {
+			v, err := approver.Approve(context.Background(), req)
+			if err != nil {
+				t.Errorf("Approve() failed: %v", err)
+			}
+			done <- v
+		}()
+
+		if prompt := <-prompts; !strings.Contains(prompt, `keyboard_type {"text":"hi"} [abc]`) {
+			t.Errorf("prompt = %q, want the request", prompt)
+		}
+		io.WriteString(answers, tt.answer+"\n")
+		if v := <-done; v.Approved != tt.approved || v.By != "terminal" {
+			t.Errorf("answer %q gave %+v, want approved %v", tt.answer, v, tt.approved)
+		}
+	}
+
+	// Long text is listed in full before the prompt
+	text := strings.Repeat("a", 500)
+	long := ApprovalRequest{ID: "def", Tool: "keyboard_type", Arguments: map[string]any{"text": text}, Deadline: time.Now().Add(time.Minute)}
+	done := make(chan Verdict, 1)
+	go func() {
+		v, _ := approver.Approve(context.Background(), long)
+		done <- v
+	}()
+	if listing := <-prompts; !strings.Contains(listing, `text: "`+text+`"`) {
+		t.Errorf("listing = %q, want the full text", listing)
+	}
+	if prompt := <-prompts; !strings.HasPrefix(prompt, "Approve keyboard_type [def]?") {
+		t.Errorf("prompt = %q, want the tool without its arguments", prompt)
+	}
+	io.WriteString(answers, "n\n")
+	<-done
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := approver.Approve(ctx, req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unanswered Approve() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	answers.Close()
+	if _, err := approver.Approve(context.Background(), req); err == nil {
+		t.Error("Approve() on a closed terminal succeeded, want an error")
+	}
+}
+
+func TestHTTPApprover(t *testing.T) {
+	approver := NewHTTPApprover("secret")
+	srv := httptest.NewServer(approver.Handler())
+	defer srv.Close()
+
+	// send makes a request to the endpoint with the token
+	send := func(method, path, body string) (*http.Response, error) {
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(ApprovalTokenHeader, "Bearer secret")
+		return http.DefaultClient.Do(req)
+	}
+
+	// waitPending returns the pending requests once there are n of them
+	waitPending := func(n int) []ApprovalRequest {
+		t.Helper()
+		for range 100 {
+			resp, err := send(http.MethodGet, "/approvals", "")
+			if err != nil {
+				t.Fatalf("GET /approvals failed: %v", err)
+			}
+			var pending []ApprovalRequest
+			err = json.NewDecoder(resp.Body).Decode(&pending)
+			resp.Body.Close()
+			if err != nil {
+				t.Fatalf("GET /approvals returned invalid JSON: %v", err)
+			}
+			if len(pending) == n {
+				return pending
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("never saw %d pending requests", n)
+		return nil
+	}
+
+	now := time.Now()
+	type outcome struct {
+		verdict Verdict
+		err     error
+	}
+	results := make(map[string]chan outcome)
+	for i, id := range []string{"first", "second"} {
+		results[id] = make(chan outcome, 1)
+		req := ApprovalRequest{ID: id, Tool: "mouse_click", Requested: now.Add(time.Duration(i) * time.Second)}
+		go func() {
+			v, err := approver.Approve(context.Background(), req)
+			results[id] <- outcome{v, err}
+		}()
+	}
+
+	pending := waitPending(2)
+	if pending[0].ID != "first" || pending[1].ID != "second" {
+		t.Errorf("pending = %+v, want first and second in order", pending)
+	}
+
+	resp, err := send(http.MethodPost, "/approvals/first/approve", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("approving returned %s", resp.Status)
+	}
+	if got := <-results["first"]; got.err != nil || !got.verdict.Approved || !strings.HasPrefix(got.verdict.By, "http") {
+		t.Errorf("first = %+v, want approved over HTTP", got)
+	}
+
+	resp, err = send(http.MethodPost, "/approvals/second/deny", `{"reason": "wrong window"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := <-results["second"]; got.err != nil || got.verdict.Approved || got.verdict.Reason != "wrong window" {
+		t.Errorf("second = %+v, want denied for the wrong window", got)
+	}
+
+	waitPending(0)
+	resp, err = send(http.MethodPost, "/approvals/second/approve", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("deciding twice returned %s, want 404", resp.Status)
+	}
+}
+
+func TestHTTPApproverRefuses(t *testing.T) {
+	srv := httptest.NewServer(NewHTTPApprover("secret").Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		header     map[string]string
+		wantStatus int
+	}{
+		{name: "no token", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", header: map[string]string{"Authorization": "Bearer guess"}, wantStatus: http.StatusUnauthorized},
+		{name: "token without scheme", header: map[string]string{"Authorization": "secret"}, wantStatus: http.StatusUnauthorized},
+		{name: "browser", header: map[string]string{"Authorization": "Bearer secret", "Origin": "https://example.com"}, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/approvals", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("GET /approvals returned %s, want %d", resp.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestApprovalAddr(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		token   string
+		wantErr string
+	}{
+		{name: "loopback", addr: "127.0.0.1:0", token: "secret"},
+		{name: "every interface", addr: "0.0.0.0:0", token: "secret", wantErr: "loopback"},
+		{name: "no token", addr: "127.0.0.1:0", wantErr: "needs a token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			approval := &Approval{Tools: []string{"window_close"}, Addr: tt.addr, Token: tt.token}
+			backend := automation.NewFakeBackend()
+			s := New(automation.NewMouseWithBackend(backend), automation.NewKeyboardWithBackend(backend), automation.NewScreenWithBackend(backend), automation.NewWindowsWithBackend(backend), automation.NewClipboardWithBackend(backend))
+			err := approval.install(ctx, s)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("install() failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("install() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
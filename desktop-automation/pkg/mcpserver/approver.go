@@ -0,0 +1,271 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultApprovalAddr is where the HTTP approval endpoint is usually served.
+// It only accepts local connections.
+const DefaultApprovalAddr = "localhost:8090"
+
+// ApprovalTokenHeader is the header that carries the token of the HTTP
+// approval endpoint, as "Bearer <token>"
+const ApprovalTokenHeader = "Authorization"
+
+// terminalLineLength is the longest request the terminal prompt shows on
+// one line; longer requests list their arguments one per line first
+const terminalLineLength = 120
+
+// TerminalApprover asks on a terminal, one request at a time. It reads its
+// own terminal rather than standard input, which may carry the MCP protocol.
+type TerminalApprover struct {
+	out   io.Writer
+	lines chan string
+	turn  chan struct{}
+}
+
+// NewTerminalApprover creates an approver that prompts on out and reads the
+// answers from in
+func NewTerminalApprover(in io.Reader, out io.Writer) *TerminalApprover {
+	t := &TerminalApprover{out: out, lines: make(chan string), turn: make(chan struct{}, 1)}
+	go t.read(in)
+	return t
+}
+
+// OpenTerminalApprover creates an approver on the controlling terminal of
+// the process
+func OpenTerminalApprover() (*TerminalApprover, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the terminal for approvals: %w", err)
+	}
+	return NewTerminalApprover(tty, tty), nil
+}
+
+// read passes the lines typed on the terminal to the pending prompt
+func (t *TerminalApprover) read(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		t.lines <- strings.TrimSpace(scanner.Text())
+	}
+	close(t.lines)
+}
+
+// Approve prompts for req and waits for a yes or no. Any answer but y or yes
+// denies the call.
+func (t *TerminalApprover) Approve(ctx context.Context, req ApprovalRequest) (Verdict, error) {
+	select {
+	case t.turn <- struct{}{}:
+		defer func() { <-t.turn }()
+	case <-ctx.Done():
+		return Verdict{}, ctx.Err()
+	}
+
+	// Drop answers typed while no prompt was shown
+	for drained := false; !drained; {
+		select {
+		case _, ok := <-t.lines:
+			if !ok {
+				return Verdict{}, errors.New("terminal closed")
+			}
+		default:
+			drained = true
+		}
+	}
+
+	shown := req.String()
+	if utf8.RuneCountInString(shown) > terminalLineLength {
+		t.list(req)
+		shown = req.Tool
+	}
+	fmt.Fprintf(t.out, "Approve %s [%s]? (y/N, %s left): ", shown, req.ID, time.Until(req.Deadline).Round(time.Second))
+	select {
+	case line, ok := <-t.lines:
+		if !ok {
+			return Verdict{}, errors.New("terminal closed")
+		}
+		switch strings.ToLower(line) {
+		case "y", "yes":
+			return Verdict{Approved: true, By: "terminal"}, nil
+		}
+		return Verdict{By: "terminal", Reason: "denied on the terminal"}, nil
+	case <-ctx.Done():
+		fmt.Fprintf(t.out, "\nRequest %s is no longer pending: %v\n", req.ID, ctx.Err())
+		return Verdict{}, ctx.Err()
+	}
+}
+
+// list writes the arguments of req in full, one per line, so that long text
+// can be read before it is approved
+func (t *TerminalApprover) list(req ApprovalRequest) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Request %s calls %s with:\n", req.ID, req.Tool)
+	keys := slices.Sorted(maps.Keys(req.Arguments))
+	for _, k := range keys {
+		value, err := json.Marshal(req.Arguments[k])
+		if err != nil {
+			value = []byte(fmt.Sprint(req.Arguments[k]))
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", k, value)
+	}
+	io.WriteString(t.out, b.String())
+}
+
+// HTTPApprover lists pending requests on a local HTTP endpoint and takes
+// decisions from it:
+//
+//	GET  /approvals               pending requests as JSON
+//	POST /approvals/{id}/approve  approve a request
+//	POST /approvals/{id}/deny     deny a request, with an optional JSON
+//	                              body like {"reason": "..."}
+//
+// Every request must carry the token as "Authorization: Bearer <token>".
+// Requests with an Origin header are refused, so that web pages open in a
+// browser on the same machine cannot approve calls.
+type HTTPApprover struct {
+	token   string
+	mu      sync.Mutex
+	pending map[string]*pendingApproval
+}
+
+// pendingApproval is a request waiting for the HTTP endpoint
+type pendingApproval struct {
+	req     ApprovalRequest
+	verdict chan Verdict
+}
+
+// NewHTTPApprover creates an approver with no pending requests whose
+// endpoint accepts requests carrying token. An empty token refuses every
+// request.
+func NewHTTPApprover(token string) *HTTPApprover {
+	return &HTTPApprover{token: token, pending: make(map[string]*pendingApproval)}
+}
+
+// Approve lists req on the endpoint until it is decided or ctx is done
+func (h *HTTPApprover) Approve(ctx context.Context, req ApprovalRequest) (Verdict, error) {
+	p := &pendingApproval{req: req, verdict: make(chan Verdict, 1)}
+	h.mu.Lock()
+	h.pending[req.ID] = p
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.pending, req.ID)
+		h.mu.Unlock()
+	}()
+
+	select {
+	case v := <-p.verdict:
+		return v, nil
+	case <-ctx.Done():
+		return Verdict{}, ctx.Err()
+	}
+}
+
+// Handler returns the HTTP handler of the approval endpoint
+func (h *HTTPApprover) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /approvals", h.list)
+	mux.HandleFunc("POST /approvals/{id}/approve", func(w http.ResponseWriter, r *http.Request) { h.decide(w, r, true) })
+	mux.HandleFunc("POST /approvals/{id}/deny", func(w http.ResponseWriter, r *http.Request) { h.decide(w, r, false) })
+	return h.authenticate(mux)
+}
+
+// authenticate refuses requests from browsers and requests without the token
+func (h *HTTPApprover) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") != "" {
+			http.Error(w, "cross-origin requests are not accepted", http.StatusForbidden)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get(ApprovalTokenHeader), "Bearer ")
+		if !ok || h.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="approvals"`)
+			http.Error(w, "missing or invalid approval token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Serve serves the approval endpoint on ln until ctx is done, and closes ln
+func (h *HTTPApprover) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{Handler: h.Handler()}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("approval endpoint error: %w", err)
+	}
+	return nil
+}
+
+// list writes the pending requests, oldest first
+func (h *HTTPApprover) list(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	pending := make([]ApprovalRequest, 0, len(h.pending))
+	for _, p := range h.pending {
+		pending = append(pending, p.req)
+	}
+	h.mu.Unlock()
+	slices.SortFunc(pending, func(a, b ApprovalRequest) int { return a.Requested.Compare(b.Requested) })
+
+	writeJSON(w, http.StatusOK, pending)
+}
+
+// decide approves or denies the request named in the path
+func (h *HTTPApprover) decide(w http.ResponseWriter, r *http.Request, approved bool) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<16)).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, fmt.Sprintf("invalid body: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	id := r.PathValue("id")
+	h.mu.Lock()
+	p, ok := h.pending[id]
+	if ok {
+		delete(h.pending, id)
+	}
+	h.mu.Unlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("no pending request %q", id), http.StatusNotFound)
+		return
+	}
+
+	v := Verdict{Approved: approved, By: "http " + r.RemoteAddr, Reason: body.Reason}
+	if !approved && v.Reason == "" {
+		v.Reason = "denied over HTTP"
+	}
+	p.verdict <- v
+
+	writeJSON(w, http.StatusOK, map[string]any{"id": id, "approved": approved})
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,82 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/audit"
+)
+
+// secretResults are the tools whose result is text a user typed or copied,
+// which the log redacts like typed text
+var secretResults = map[string]bool{"clipboard_get": true}
+
+// installAudit records every tool call of s, including those the policy or
+// an approver refuses, in log. Write errors are kept by log for its Close.
+func installAudit(s *server.MCPServer, log *audit.Logger) {
+	server.WithToolHandlerMiddleware(func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := time.Now()
+			result, err := next(ctx, req)
+
+			entry := audit.Entry{
+				Time:      start,
+				Source:    audit.SourceMCP,
+				Action:    req.Params.Name,
+				Arguments: req.GetArguments(),
+				Duration:  audit.Duration(time.Since(start)),
+			}
+			entry.Session, entry.Client = auditClient(ctx)
+			switch {
+			case err != nil:
+				entry.Result = err.Error()
+			case result != nil:
+				entry.OK = !result.IsError
+				entry.Result = resultSummary(result)
+				entry.RedactResult = entry.OK && secretResults[req.Params.Name]
+			}
+			log.Log(entry)
+
+			return result, err
+		}
+	})(s)
+}
+
+// auditClient returns the session of the call and who made it: the name of
+// the bearer token, or else the name the client gave when it connected
+func auditClient(ctx context.Context) (session, client string) {
+	if s := server.ClientSessionFromContext(ctx); s != nil {
+		session = s.SessionID()
+		if info, ok := s.(server.SessionWithClientInfo); ok {
+			if impl := info.GetClientInfo(); impl.Name != "" {
+				client = strings.TrimSpace(impl.Name + " " + impl.Version)
+			}
+		}
+	}
+	if token, ok := ctx.Value(tokenKey{}).(*Token); ok {
+		client = token.Name
+	}
+	return session, client
+}
+
+// resultSummary returns the text of a result, with images and other
+// content named by their type
+func resultSummary(result *mcp.CallToolResult) string {
+	parts := make([]string, 0, len(result.Content))
+	for _, c := range result.Content {
+		switch c := c.(type) {
+		case mcp.TextContent:
+			parts = append(parts, c.Text)
+		case mcp.ImageContent:
+			parts = append(parts, fmt.Sprintf("[image %s, %d base64 characters]", c.MIMEType, len(c.Data)))
+		default:
+			parts = append(parts, fmt.Sprintf("[%T]", c))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,71 @@
+package mcpserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/audit"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+)
+
+func TestAudit(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := audit.New(&buf, audit.Options{})
+	if err != nil {
+		t.Fatalf("audit.New() failed: %v", err)
+	}
+	policy, err := ParsePolicy([]byte("tools: {deny: [window_close]}"))
+	if err != nil {
+		t.Fatalf("ParsePolicy() failed: %v", err)
+	}
+
+	backend := automation.NewFakeBackend()
+	windows := automation.NewWindowsWithBackend(backend)
+	s := New(automation.NewMouseWithBackend(backend), automation.NewKeyboardWithBackend(backend), automation.NewScreenWithBackend(backend), windows, automation.NewClipboardWithBackend(backend))
+	installAudit(s, log)
+	if err := policy.install(s, automation.NewMouseWithBackend(backend), windows); err != nil {
+		t.Fatalf("install() failed: %v", err)
+	}
+	c := newInProcessClient(t, s)
+
+	callTool(t, c, "keyboard_type", map[string]any{"text": "secret"})
+	callTool(t, c, "window_close", map[string]any{"title": "Editor"})
+	callTool(t, c, "screen_capture", nil)
+	callTool(t, c, "clipboard_set", map[string]any{"text": "password"})
+	callTool(t, c, "clipboard_get", nil)
+
+	var entries []audit.Entry
+	scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	for scanner.Scan() {
+		var e audit.Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("entry %q is not JSON: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("logged %d entries, want 5", len(entries))
+	}
+	if bytes.Contains(buf.Bytes(), []byte("password")) {
+		t.Errorf("log = %s, want the clipboard text redacted", buf.String())
+	}
+
+	typed, closed, captured := entries[0], entries[1], entries[2]
+	if typed.Source != audit.SourceMCP || typed.Action != "keyboard_type" || !typed.OK || typed.Time.IsZero() {
+		t.Errorf("keyboard_type entry = %+v, want a successful MCP call", typed)
+	}
+	if typed.Arguments["text"] != "[redacted 6 characters]" || typed.Result != "Typed: [redacted 6 characters]" {
+		t.Errorf("keyboard_type entry = %+v, want the text redacted", typed)
+	}
+	if closed.Action != "window_close" || closed.OK || !bytes.Contains([]byte(closed.Result), []byte("policy_violation")) {
+		t.Errorf("window_close entry = %+v, want the policy violation", closed)
+	}
+	if !captured.OK || !bytes.Contains([]byte(captured.Result), []byte("[image image/")) {
+		t.Errorf("screen_capture entry = %+v, want the image summarized", captured)
+	}
+	if set, got := entries[3], entries[4]; set.Arguments["text"] != "[redacted 8 characters]" || got.Result != "[redacted 8 characters]" {
+		t.Errorf("clipboard entries = %+v and %+v, want the text redacted", set, got)
+	}
+}
@@ -0,0 +1,269 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+// TokenEnv is the environment variable holding comma-separated bearer tokens
+// that may call every tool
+const TokenEnv = "DESKTOP_AUTOMATION_MCP_TOKEN"
+
+// maxAuthorizedBody bounds how much of a request body is read to check which
+// tools it calls
+const maxAuthorizedBody = 16 << 20
+
+// Token is a bearer token accepted by the network transports
+type Token struct {
+	// Name identifies the token in errors; it is not secret
+	Name string `yaml:"name"`
+	// Secret is the value clients send as "Authorization: Bearer <secret>"
+	Secret string `yaml:"token"`
+	// Tools are the tools the token may call, as names or path.Match
+	// patterns like "mouse_*". If empty, every tool is allowed.
+	Tools []string `yaml:"tools,omitempty"`
+}
+
+// Allows reports whether the token may call the named tool
+func (t *Token) Allows(tool string) bool {
+	if len(t.Tools) == 0 {
+		return true
+	}
+	for _, pattern := range t.Tools {
+		if ok, _ := path.Match(pattern, tool); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenFile is the layout of a token file
+type tokenFile struct {
+	Tokens []Token `yaml:"tokens"`
+}
+
+// LoadTokens returns the tokens of the YAML file at path, if path is not
+// empty, followed by the tokens in the TokenEnv environment variable. A token
+// file looks like:
+//
+//	tokens:
+//	  - name: ci
+//	    token: 9f8e...
+//	    tools: [screen_capture, "mouse_*"]
+func LoadTokens(path string) ([]Token, error) {
+	var tokens []Token
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token file: %w", err)
+		}
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		var file tokenFile
+		if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid token file %s: %w", path, err)
+		}
+		if len(file.Tokens) == 0 {
+			return nil, fmt.Errorf("invalid token file %s: no tokens", path)
+		}
+		tokens = file.Tokens
+	}
+
+	for i, secret := range strings.Split(os.Getenv(TokenEnv), ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			tokens = append(tokens, Token{Name: fmt.Sprintf("%s[%d]", TokenEnv, i), Secret: secret})
+		}
+	}
+
+	if err := validateTokens(tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// validateTokens checks that tokens are set, distinct and have valid tool
+// patterns, and names the unnamed ones
+func validateTokens(tokens []Token) error {
+	seen := make(map[string]string)
+	for i := range tokens {
+		t := &tokens[i]
+		if t.Name == "" {
+			t.Name = fmt.Sprintf("token %d", i+1)
+		}
+		if t.Secret == "" {
+			return fmt.Errorf("token %q has no secret", t.Name)
+		}
+		if other, ok := seen[t.Secret]; ok {
+			return fmt.Errorf("tokens %q and %q have the same secret", other, t.Name)
+		}
+		seen[t.Secret] = t.Name
+		for _, pattern := range t.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("token %q has invalid tool pattern %q: %w", t.Name, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// tlsConfig loads the server certificate and, for mutual TLS, the client CAs.
+// It returns nil if TLS is not configured.
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	if c.CertFile == "" && c.KeyFile == "" {
+		if c.ClientCAFile != "" {
+			return nil, errors.New("client certificates require a server certificate and key")
+		}
+		return nil, nil
+	}
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, errors.New("TLS requires both a certificate and a key")
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+
+	if c.ClientCAFile != "" {
+		pem, err := os.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in client CA file %s", c.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		// A missing certificate is rejected with a 401 by authenticate rather
+		// than by a failed handshake, which clients report poorly
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return config, nil
+}
+
+// tokenKey is the context key of the token that authenticated a request
+type tokenKey struct{}
+
+// authenticate wraps next so that it only serves clients with a verified
+// client certificate, when mutual TLS is on, and a valid bearer token, when
+// tokens are configured. Calls to tools outside the token's allowlist are
+// refused with 403 Forbidden.
+func (c *Config) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c.ClientCAFile != "" && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+			http.Error(w, "Unauthorized: client certificate required", http.StatusUnauthorized)
+			return
+		}
+		if len(c.Tokens) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		secret, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || secret == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="desktop-automation"`)
+			http.Error(w, "Unauthorized: missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		token := c.lookupToken(secret)
+		if token == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="desktop-automation", error="invalid_token"`)
+			http.Error(w, "Unauthorized: invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		if r.Method == http.MethodPost && len(token.Tools) > 0 {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxAuthorizedBody))
+			if err != nil {
+				http.Error(w, fmt.Sprintf("failed to read request: %v", err), http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			for _, tool := range calledTools(body) {
+				if !token.Allows(tool) {
+					http.Error(w, fmt.Sprintf("Forbidden: token %q may not call tool %q", token.Name, tool), http.StatusForbidden)
+					return
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenKey{}, token)))
+	})
+}
+
+// lookupToken returns the token with the given secret, or nil. Every token
+// is compared in constant time, so the time taken reveals neither which
+// token matched nor how much of a secret was right.
+func (c *Config) lookupToken(secret string) *Token {
+	sum := sha256.Sum256([]byte(secret))
+	var found *Token
+	for i := range c.Tokens {
+		want := sha256.Sum256([]byte(c.Tokens[i].Secret))
+		if subtle.ConstantTimeCompare(sum[:], want[:]) == 1 {
+			found = &c.Tokens[i]
+		}
+	}
+	return found
+}
+
+// calledTools returns the tools called by a JSON-RPC message or batch.
+// Messages that do not parse are left for the server to reject.
+func calledTools(body []byte) []string {
+	type call struct {
+		Method string `json:"method"`
+		Params struct {
+			Name string `json:"name"`
+		} `json:"params"`
+	}
+
+	var calls []call
+	if err := json.Unmarshal(body, &calls); err != nil {
+		var single call
+		if err := json.Unmarshal(body, &single); err != nil {
+			return nil
+		}
+		calls = []call{single}
+	}
+
+	var tools []string
+	for _, c := range calls {
+		if c.Method == string(mcp.MethodToolsCall) {
+			tools = append(tools, c.Params.Name)
+		}
+	}
+	return tools
+}
+
+// filterTools hides the tools that the token of the request may not call
+func filterTools(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	token, ok := ctx.Value(tokenKey{}).(*Token)
+	if !ok || len(token.Tools) == 0 {
+		return tools
+	}
+
+	allowed := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if token.Allows(tool.Name) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
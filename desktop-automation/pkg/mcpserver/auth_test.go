@@ -0,0 +1,280 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+)
+
+func TestLoadTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     string
+		want    []Token
+		wantErr string
+	}{
+		{
+			name: "file",
+			file: `tokens:
+  - name: ci
+    token: one
+    tools: [screen_capture, "mouse_*"]
+  - token: two
+`,
+			want: []Token{
+				{Name: "ci", Secret: "one", Tools: []string{"screen_capture", "mouse_*"}},
+				{Name: "token 2", Secret: "two"},
+			},
+		},
+		{
+			name: "file and environment",
+			file: "tokens:\n  - {name: ci, token: one}\n",
+			env:  "two, three",
+			want: []Token{
+				{Name: "ci", Secret: "one"},
+				{Name: TokenEnv + "[0]", Secret: "two"},
+				{Name: TokenEnv + "[1]", Secret: "three"},
+			},
+		},
+		{
+			name: "environment",
+			env:  "two",
+			want: []Token{{Name: TokenEnv + "[0]", Secret: "two"}},
+		},
+		{name: "none"},
+		{name: "empty file", file: "tokens: []\n", wantErr: "no tokens"},
+		{name: "unknown field", file: "tokens:\n  - {name: ci, secret: one}\n", wantErr: "field secret not found"},
+		{name: "missing secret", file: "tokens:\n  - {name: ci}\n", wantErr: `token "ci" has no secret`},
+		{name: "duplicate secret", file: "tokens:\n  - {name: ci, token: one}\n", env: "one", wantErr: "same secret"},
+		{name: "bad pattern", file: "tokens:\n  - {token: one, tools: [\"mouse_[\"]}\n", wantErr: "invalid tool pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(TokenEnv, tt.env)
+			var path string
+			if tt.file != "" {
+				path = filepath.Join(t.TempDir(), "tokens.yaml")
+				if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := LoadTokens(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("LoadTokens() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadTokens() failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadTokens() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenAuthentication(t *testing.T) {
+	for _, transport := range []Transport{TransportSSE, TransportHTTP} {
+		t.Run(string(transport), func(t *testing.T) {
+			backend := automation.NewFakeBackend()
+			config := &Config{
+				Transport: transport,
+				Tokens: []Token{
+					{Name: "admin", Secret: "admin-secret"},
+					{Name: "viewer", Secret: "viewer-secret", Tools: []string{"mouse_*"}},
+				},
+			}
+			url := startLoopbackServer(t, config, backend)
+
+			for _, token := range []string{"", "wrong-secret"} {
+				if c, err := dialLoopback(transport, url, token, nil); err == nil {
+					c.Close()
+					t.Errorf("connecting with token %q succeeded, want 401", token)
+				} else if !strings.Contains(err.Error(), "401") {
+					t.Errorf("connecting with token %q failed with %v, want 401", token, err)
+				}
+			}
+
+			viewer, err := dialLoopback(transport, url, "viewer-secret", nil)
+			if err != nil {
+				t.Fatalf("connecting as viewer failed: %v", err)
+			}
+			defer viewer.Close()
+
+			// The viewer only sees and calls the mouse tools
+			tools, err := viewer.ListTools(context.Background(), mcp.ListToolsRequest{})
+			if err != nil {
+				t.Fatalf("ListTools() failed: %v", err)
+			}
+			if len(tools.Tools) == 0 {
+				t.Fatal("viewer lists no tools")
+			}
+			for _, tool := range tools.Tools {
+				if !strings.HasPrefix(tool.Name, "mouse_") {
+					t.Errorf("viewer lists tool %s", tool.Name)
+				}
+			}
+			if result := callTool(t, viewer, "mouse_move", map[string]any{"x": 1, "y": 2}); result.IsError {
+				t.Fatalf("mouse_move failed: %s", resultText(result))
+			}
+			req := mcp.CallToolRequest{}
+			req.Params.Name = "keyboard_type"
+			req.Params.Arguments = map[string]any{"text": "nope"}
+			if _, err := viewer.CallTool(context.Background(), req); err == nil || !strings.Contains(err.Error(), "403") {
+				t.Errorf("keyboard_type as viewer error = %v, want 403", err)
+			}
+
+			admin, err := dialLoopback(transport, url, "admin-secret", nil)
+			if err != nil {
+				t.Fatalf("connecting as admin failed: %v", err)
+			}
+			defer admin.Close()
+			if result := callTool(t, admin, "keyboard_type", map[string]any{"text": "ok"}); result.IsError {
+				t.Fatalf("keyboard_type as admin failed: %s", resultText(result))
+			}
+
+			want := []automation.Event{
+				{Kind: automation.EventMove, X: 1, Y: 2},
+				{Kind: automation.EventType, Text: "ok"},
+			}
+			if got := backend.Events(); !reflect.DeepEqual(got, want) {
+				t.Errorf("events = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// writePEM writes a PEM block of the given type to a file in dir
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// issue creates a key and a certificate for it from template, signed by
+// parent and parentKey, or self-signed if parent is nil
+func issue(t *testing.T, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := issue(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+	serverCert, serverKey := issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "server"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, caKey)
+	clientCert, clientKey := issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "agent"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+
+	serverKeyDER, err := x509.MarshalECPrivateKey(serverKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{
+		Transport:    TransportHTTP,
+		CertFile:     writePEM(t, dir, "server.pem", "CERTIFICATE", serverCert.Raw),
+		KeyFile:      writePEM(t, dir, "server-key.pem", "EC PRIVATE KEY", serverKeyDER),
+		ClientCAFile: writePEM(t, dir, "ca.pem", "CERTIFICATE", ca.Raw),
+	}
+	backend := automation.NewFakeBackend()
+	url := startLoopbackServer(t, config, backend)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	newHTTPClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+	}
+
+	if c, err := dialLoopback(TransportHTTP, url, "", newHTTPClient()); err == nil {
+		c.Close()
+		t.Error("connecting without a client certificate succeeded, want 401")
+	} else if !strings.Contains(err.Error(), "401") {
+		t.Errorf("connecting without a client certificate failed with %v, want 401", err)
+	}
+
+	c, err := dialLoopback(TransportHTTP, url, "", newHTTPClient(tls.Certificate{Certificate: [][]byte{clientCert.Raw}, PrivateKey: clientKey}))
+	if err != nil {
+		t.Fatalf("connecting with a client certificate failed: %v", err)
+	}
+	defer c.Close()
+	if result := callTool(t, c, "mouse_move", map[string]any{"x": 5, "y": 6}); result.IsError {
+		t.Fatalf("mouse_move failed: %s", resultText(result))
+	}
+	if want := []automation.Event{{Kind: automation.EventMove, X: 5, Y: 6}}; !reflect.DeepEqual(backend.Events(), want) {
+		t.Errorf("events = %+v, want %+v", backend.Events(), want)
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "key without certificate", config: Config{KeyFile: "key.pem"}},
+		{name: "client CA without certificate", config: Config{ClientCAFile: "ca.pem"}},
+		{name: "missing files", config: Config{CertFile: "missing.pem", KeyFile: "missing-key.pem"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.config.tlsConfig(); err == nil {
+				t.Error("tlsConfig() succeeded, want an error")
+			}
+		})
+	}
+}
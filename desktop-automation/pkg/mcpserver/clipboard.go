@@ -0,0 +1,49 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+)
+
+// addClipboardTools adds clipboard tools to the server
+func addClipboardTools(s *server.MCPServer, clipboard *automation.Clipboard) {
+	// Clipboard get tool
+	s.AddTool(
+		mcp.NewTool("clipboard_get",
+			mcp.WithDescription("Get the text on the clipboard"),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			text, err := clipboard.Get()
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to get clipboard: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(text), nil
+		},
+	)
+
+	// Clipboard set tool
+	s.AddTool(
+		mcp.NewTool("clipboard_set",
+			mcp.WithDescription("Put text on the clipboard"),
+			mcp.WithString("text", mcp.Required(), mcp.Description("Text to put on the clipboard")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			text, err := req.RequireString("text")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid text: %v", err)), nil
+			}
+
+			if err := clipboard.Set(text); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to set clipboard: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Put %d characters on the clipboard", len([]rune(text)))), nil
+		},
+	)
+}
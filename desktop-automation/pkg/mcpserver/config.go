@@ -0,0 +1,260 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/audit"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+)
+
+// Transport is how MCP clients connect to the server
+type Transport string
+
+// Transports
+const (
+	// TransportStdio serves a single client on standard input and output,
+	// usually the process that started the server
+	TransportStdio Transport = "stdio"
+	// TransportSSE serves any number of clients over HTTP with server-sent
+	// events on SSEPath and messages posted to MessagePath
+	TransportSSE Transport = "sse"
+	// TransportHTTP serves any number of clients with the streamable HTTP
+	// transport on HTTPPath
+	TransportHTTP Transport = "http"
+)
+
+// Endpoints of the network transports
+const (
+	SSEPath     = "/sse"
+	MessagePath = "/message"
+	HTTPPath    = "/mcp"
+)
+
+// DefaultAddr is where the network transports listen unless Config.Addr is
+// set. It only accepts local connections; listen on a public address such as
+// :8080 to reach the server from another machine.
+const DefaultAddr = "localhost:8080"
+
+// ShutdownTimeout bounds how long a network server waits for open requests
+// when it stops
+const ShutdownTimeout = 5 * time.Second
+
+// ParseTransport parses a transport name such as "stdio", "sse" or "http"
+func ParseTransport(name string) (Transport, error) {
+	switch t := Transport(strings.ToLower(strings.TrimSpace(name))); t {
+	case "", TransportStdio:
+		return TransportStdio, nil
+	case TransportSSE, TransportHTTP:
+		return t, nil
+	}
+
+	return "", fmt.Errorf("unknown transport %q (must be stdio, sse or http)", name)
+}
+
+// Config sets up a server. It is shared by the desktop-automation serve mcp
+// command and the standalone MCP server.
+type Config struct {
+	// Transport is how clients connect; TransportStdio if empty
+	Transport Transport
+	// Addr is the host:port the sse and http transports listen on;
+	// DefaultAddr if empty
+	Addr string
+
+	// Tokens are the bearer tokens the network transports accept. If empty,
+	// clients need no token.
+	Tokens []Token
+	// CertFile and KeyFile, if set, serve the network transports over TLS
+	CertFile string
+	KeyFile  string
+	// ClientCAFile, if set, requires clients of the network transports to
+	// present a certificate signed by one of its CAs (mutual TLS)
+	ClientCAFile string
+
+	// Audit, if set, records every tool call, including those the policy
+	// or an approver refuses. The caller closes it.
+	Audit *audit.Logger
+	// Policy, if set, limits which tools clients may call and what they may
+	// do with them, over every transport
+	Policy *Policy
+	// Approval, if set, holds calls of some tools until a human approves
+	// them. Calls the policy refuses are never put to a human, including
+	// moves, clicks, drags and scrolls at coordinates it does not allow.
+	// Clicks on images and text are checked once they are found on screen,
+	// after approval.
+	Approval *Approval
+}
+
+// Authenticated reports whether the network transports authenticate clients
+func (c *Config) Authenticated() bool {
+	return len(c.Tokens) > 0 || c.ClientCAFile != ""
+}
+
+// URL returns the address clients of the configured transport connect to
+func (c *Config) URL() string {
+	addr := c.Addr
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	if host, port, err := net.SplitHostPort(addr); err == nil && host == "" {
+		addr = net.JoinHostPort("localhost", port)
+	}
+
+	scheme := "http"
+	if c.CertFile != "" {
+		scheme = "https"
+	}
+	switch c.Transport {
+	case TransportSSE:
+		return scheme + "://" + addr + SSEPath
+	case TransportHTTP:
+		return scheme + "://" + addr + HTTPPath
+	}
+	return "stdio"
+}
+
+// Run serves the tools of backend over the configured transport until ctx
+// is done or, for stdio, the client disconnects. Keys still held by clients
+// are released when it returns.
+func (c *Config) Run(ctx context.Context, backend automation.Backend) error {
+	transport, err := ParseTransport(string(c.Transport))
+	if err != nil {
+		return err
+	}
+	if transport == TransportStdio {
+		return c.serve(ctx, backend, func(s *server.MCPServer, _ *automation.Keyboard) error {
+			return server.NewStdioServer(s).Listen(ctx, os.Stdin, os.Stdout)
+		})
+	}
+
+	addr := c.Addr
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	return c.Serve(ctx, backend, ln)
+}
+
+// Serve serves the tools of backend over the sse or http transport on ln
+// until ctx is done, and closes ln. Clients are authenticated as configured;
+// with TLS configured, ln carries TLS.
+func (c *Config) Serve(ctx context.Context, backend automation.Backend, ln net.Listener) error {
+	transport, err := ParseTransport(string(c.Transport))
+	if err != nil {
+		ln.Close()
+		return err
+	}
+	if err := validateTokens(c.Tokens); err != nil {
+		ln.Close()
+		return err
+	}
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		ln.Close()
+		return err
+	}
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+
+	return c.serve(ctx, backend, func(s *server.MCPServer, keyboard *automation.Keyboard) error {
+		// Clients only list the tools their token may call
+		server.WithToolFilter(filterTools)(s)
+
+		srv := &http.Server{}
+		var shutdown func(context.Context) error
+		switch transport {
+		case TransportSSE:
+			sse := server.NewSSEServer(s,
+				server.WithSSEEndpoint(SSEPath),
+				server.WithMessageEndpoint(MessagePath),
+				server.WithKeepAlive(true),
+				server.WithHTTPServer(srv),
+			)
+			srv.Handler = c.authenticate(sse)
+			shutdown = sse.Shutdown
+		case TransportHTTP:
+			mux := http.NewServeMux()
+			streamable := server.NewStreamableHTTPServer(s,
+				server.WithEndpointPath(HTTPPath),
+				server.WithStreamableHTTPServer(srv),
+			)
+			mux.Handle(HTTPPath, releaseOnDelete(streamable, keyboard))
+			srv.Handler = c.authenticate(mux)
+			shutdown = streamable.Shutdown
+		default:
+			ln.Close()
+			return fmt.Errorf("transport %s cannot be served on a listener", transport)
+		}
+
+		errc := make(chan error, 1)
+		go func() { errc <- srv.Serve(ln) }()
+
+		select {
+		case err := <-errc:
+			return err
+		case <-ctx.Done():
+		}
+
+		// Streams of connected clients only end when the server closes them
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		if err := shutdown(shutdownCtx); err != nil {
+			srv.Close()
+		}
+		if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+}
+
+// serve creates the tools of backend and runs listen on them, releasing the
+// keys still held by clients afterwards. listen gets the keyboard to release
+// the keys of sessions that end. Approvers run until ctx is done.
+func (c *Config) serve(ctx context.Context, backend automation.Backend, listen func(*server.MCPServer, *automation.Keyboard) error) (err error) {
+	mouse := automation.NewMouseWithBackend(backend)
+	keyboard := automation.NewKeyboardWithBackend(backend)
+	screen := automation.NewScreenWithBackend(backend)
+	windows := automation.NewWindowsWithBackend(backend)
+	clipboard := automation.NewClipboardWithBackend(backend)
+	defer func() {
+		if releaseErr := keyboard.ReleaseAll(); releaseErr != nil {
+			err = errors.Join(err, releaseErr)
+		}
+	}()
+
+	s := New(mouse, keyboard, screen, windows, clipboard)
+	if c.Audit != nil {
+		installAudit(s, c.Audit)
+	}
+	if c.Policy != nil {
+		if err := c.Policy.install(s, mouse, windows); err != nil {
+			return fmt.Errorf("invalid policy: %w", err)
+		}
+	}
+	if c.Approval != nil {
+		if err := c.Approval.install(ctx, s); err != nil {
+			return fmt.Errorf("invalid approval settings: %w", err)
+		}
+	}
+
+	if err := listen(s, keyboard); err != nil {
+		return fmt.Errorf("MCP server error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package mcpserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	mcptransport "github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+)
+
+func TestParseTransport(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Transport
+		wantErr bool
+	}{
+		{name: "", want: TransportStdio},
+		{name: "stdio", want: TransportStdio},
+		{name: "SSE", want: TransportSSE},
+		{name: " http ", want: TransportHTTP},
+		{name: "websocket", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTransport(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTransport(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseTransport(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// startLoopbackServer serves config on a loopback port until the test ends
+// and returns the base URL of the server
+func startLoopbackServer(t *testing.T, config *Config, backend automation.Backend) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on loopback: %v", err)
+	}
+	scheme := "http"
+	if config.CertFile != "" {
+		scheme = "https"
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- config.Serve(ctx, backend, ln) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve() failed: %v", err)
+		}
+	})
+
+	return scheme + "://" + ln.Addr().String()
+}
+
+// dialLoopback connects a client to url over transport, sending token as a
+// bearer token if set, and initializes it
+func dialLoopback(transport Transport, url, token string, httpClient *http.Client) (*client.Client, error) {
+	headers := map[string]string{}
+	if token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	var (
+		c   *client.Client
+		err error
+	)
+	switch transport {
+	case TransportSSE:
+		c, err = client.NewSSEMCPClient(url+SSEPath, mcptransport.WithHeaders(headers), mcptransport.WithHTTPClient(httpClient))
+	case TransportHTTP:
+		c, err = client.NewStreamableHttpClient(url+HTTPPath, mcptransport.WithHTTPHeaders(headers), mcptransport.WithHTTPBasicClient(httpClient))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		c.Close()
+		return nil, err
+	}
+	req := mcp.InitializeRequest{}
+	req.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	req.Params.ClientInfo = mcp.Implementation{Name: "test", Version: "1.0"}
+	if _, err := c.Initialize(ctx, req); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// newLoopbackClient connects an initialized client to url over transport
+func newLoopbackClient(t *testing.T, transport Transport, url string) *client.Client {
+	t.Helper()
+
+	c, err := dialLoopback(transport, url, "", nil)
+	if err != nil {
+		t.Fatalf("failed to connect %s client: %v", transport, err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	return c
+}
+
+func TestNetworkTransports(t *testing.T) {
+	for _, transport := range []Transport{TransportSSE, TransportHTTP} {
+		t.Run(string(transport), func(t *testing.T) {
+			backend := automation.NewFakeBackend()
+			config := Config{Transport: transport}
+			ctx, cancel := context.WithCancel(context.Background())
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("failed to listen on loopback: %v", err)
+			}
+			url := "http://" + ln.Addr().String()
+			done := make(chan error, 1)
+			go func() { done <- config.Serve(ctx, backend, ln) }()
+
+			// Two clients share the server
+			first := newLoopbackClient(t, transport, url)
+			second := newLoopbackClient(t, transport, url)
+			if result := callTool(t, first, "mouse_move", map[string]any{"x": 10, "y": 20}); result.IsError {
+				t.Fatalf("mouse_move failed: %s", resultText(result))
+			}
+			if result := callTool(t, second, "keyboard_key_down", map[string]any{"key": "shift"}); result.IsError {
+				t.Fatalf("keyboard_key_down failed: %s", resultText(result))
+			}
+
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatalf("Serve() failed: %v", err)
+			}
+
+			// Keys still held when the server stops are released
+			want := []automation.Event{
+				{Kind: automation.EventMove, X: 10, Y: 20},
+				{Kind: automation.EventKeyDown, Key: "shift"},
+				{Kind: automation.EventKeyUp, Key: "shift"},
+			}
+			if got := backend.Events(); !reflect.DeepEqual(got, want) {
+				t.Errorf("events = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestSessionKeys(t *testing.T) {
+	for _, transport := range []Transport{TransportSSE, TransportHTTP} {
+		t.Run(string(transport), func(t *testing.T) {
+			backend := automation.NewFakeBackend()
+			url := startLoopbackServer(t, &Config{Transport: transport}, backend)
+
+			first := newLoopbackClient(t, transport, url)
+			second := newLoopbackClient(t, transport, url)
+			callTool(t, first, "keyboard_key_down", map[string]any{"key": "shift"})
+			callTool(t, second, "keyboard_key_down", map[string]any{"key": "ctrl"})
+
+			// A client can neither see nor release the keys of another
+			if result := callTool(t, second, "keyboard_key_up", map[string]any{"key": "shift"}); !result.IsError {
+				t.Errorf("releasing another session's key succeeded: %s", resultText(result))
+			}
+			if result := callTool(t, second, "keyboard_release_all", nil); result.IsError {
+				t.Fatalf("keyboard_release_all failed: %s", resultText(result))
+			}
+
+			// Keys of a client that goes away are released
+			first.Close()
+			want := []automation.Event{
+				{Kind: automation.EventKeyDown, Key: "shift"},
+				{Kind: automation.EventKeyDown, Key: "ctrl"},
+				{Kind: automation.EventKeyUp, Key: "ctrl"},
+				{Kind: automation.EventKeyUp, Key: "shift"},
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for !reflect.DeepEqual(backend.Events(), want) && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if got := backend.Events(); !reflect.DeepEqual(got, want) {
+				t.Errorf("events = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
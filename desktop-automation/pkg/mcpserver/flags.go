@@ -0,0 +1,108 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+// Flags are the command line flags of the server. Both desktop-automation
+// serve mcp and the standalone mcp-server binary register them, so the two
+// accept the same options.
+type Flags struct {
+	transport   string
+	addr        string
+	tokenFile   string
+	certFile    string
+	keyFile     string
+	clientCA    string
+	policy      string
+	approval    Approval
+	approvalLog string
+}
+
+// Register adds the flags to fs
+func (f *Flags) Register(fs *pflag.FlagSet) {
+	// Add flags for the transport
+	fs.StringVar(&f.transport, "transport", string(TransportStdio), "Transport to serve: stdio, sse or http")
+	fs.StringVar(&f.addr, "addr", DefaultAddr, "Address to listen on for the sse and http transports")
+
+	// Add flags for the policy
+	fs.StringVar(&f.policy, "policy", "", "YAML policy file limiting the tools and what they may do")
+
+	// Add flags for approvals
+	fs.StringSliceVar(&f.approval.Tools, "approve", nil, "Tools that wait for a human approval, as names or patterns like keyboard_*")
+	fs.DurationVar(&f.approval.Timeout, "approval-timeout", DefaultApprovalTimeout, "How long a call waits for approval before it is denied")
+	fs.BoolVar(&f.approval.Terminal, "approval-terminal", false, "Ask for approvals on this terminal")
+	fs.StringVar(&f.approval.Addr, "approval-addr", "", "Serve the HTTP approval endpoint on this loopback address, like "+DefaultApprovalAddr)
+	fs.StringVar(&f.approvalLog, "approval-log", "", "File to append approval decisions to as JSON lines (default standard error)")
+
+	// Add flags for authentication
+	fs.StringVar(&f.tokenFile, "token-file", "", "YAML file of bearer tokens and their allowed tools (also see $"+TokenEnv+")")
+	fs.StringVar(&f.certFile, "tls-cert", "", "PEM certificate to serve HTTPS with")
+	fs.StringVar(&f.keyFile, "tls-key", "", "PEM private key of --tls-cert")
+	fs.StringVar(&f.clientCA, "client-ca", "", "PEM CA certificates that client certificates must be signed by")
+}
+
+// Config loads the files named by the flags into a configuration. The
+// returned function closes the files the configuration keeps open.
+func (f *Flags) Config() (*Config, func() error, error) {
+	noop := func() error { return nil }
+
+	t, err := ParseTransport(f.transport)
+	if err != nil {
+		return nil, noop, err
+	}
+	config := &Config{
+		Transport:    t,
+		Addr:         f.addr,
+		CertFile:     f.certFile,
+		KeyFile:      f.keyFile,
+		ClientCAFile: f.clientCA,
+	}
+	if config.Tokens, err = LoadTokens(f.tokenFile); err != nil {
+		return nil, noop, err
+	}
+	if f.policy != "" {
+		if config.Policy, err = LoadPolicy(f.policy); err != nil {
+			return nil, noop, err
+		}
+	}
+
+	if len(f.approval.Tools) == 0 {
+		return config, noop, nil
+	}
+	config.Approval = &f.approval
+	config.Approval.Log = os.Stderr
+	if f.approval.Addr != "" {
+		config.Approval.Token = NewApprovalToken()
+	}
+	if f.approvalLog == "" {
+		return config, noop, nil
+	}
+	log, err := os.OpenFile(f.approvalLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, noop, fmt.Errorf("failed to open approval log: %w", err)
+	}
+	config.Approval.Log = log
+	return config, log.Close, nil
+}
+
+// Announce tells where the network transports and the approval endpoint
+// listen, with the token of the endpoint, and warns when the transports do
+// not authenticate clients
+func (c *Config) Announce(w io.Writer) {
+	if c.Approval != nil && c.Approval.Addr != "" {
+		fmt.Fprintf(w, "Serving approvals at http://%s/approvals with the header \"%s: Bearer %s\"\n", c.Approval.Addr, ApprovalTokenHeader, c.Approval.Token)
+	}
+	if c.Transport == TransportStdio || c.Transport == "" {
+		return
+	}
+	fmt.Fprintf(w, "Serving MCP over %s at %s\n", c.Transport, c.URL())
+	if !c.Authenticated() {
+		fmt.Fprintf(w, "Warning: clients are not authenticated; use --token-file, $%s or --client-ca\n", TokenEnv)
+	}
+}
@@ -0,0 +1,158 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/imagematch"
+)
+
+// findImageOptions are the arguments shared by the image search tools
+var findImageOptions = []mcp.ToolOption{
+	mcp.WithString("image_path", mcp.Description("Path of a PNG or JPEG template on the server; give this or image_base64")),
+	mcp.WithString("image_base64", mcp.Description("Base64 encoded PNG or JPEG template; give this or image_path")),
+	mcp.WithNumber("threshold", mcp.DefaultNumber(imagematch.DefaultThreshold), mcp.Min(0), mcp.Max(1), mcp.Description("Confidence from 0 to 1 a match needs")),
+	mcp.WithNumber("x", mcp.Description("X coordinate of the region to search; requires y, width and height. Defaults to the full screen")),
+	mcp.WithNumber("y", mcp.Description("Y coordinate of the region to search")),
+	mcp.WithNumber("width", mcp.Description("Width of the region to search")),
+	mcp.WithNumber("height", mcp.Description("Height of the region to search")),
+}
+
+// addImageTools adds tools that locate template images on the screen
+func addImageTools(s *server.MCPServer, mouse *automation.Mouse, screen *automation.Screen) {
+	// Find image tool
+	s.AddTool(
+		mcp.NewTool("screen_find_image",
+			append([]mcp.ToolOption{
+				mcp.WithDescription("Find a template image on the screen and return the best match and every match above the threshold, in screen coordinates"),
+				mcp.WithNumber("max_results", mcp.DefaultNumber(10), mcp.Description("Largest number of matches to return; 0 for no limit")),
+			}, findImageOptions...)...,
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, errResult := findImage(req, screen, req.GetInt("max_results", 10))
+			if errResult != nil {
+				return errResult, nil
+			}
+
+			data := map[string]interface{}{
+				"found":   len(result.Matches) > 0,
+				"matches": matchesJSON(result.Matches),
+			}
+			if best, ok := result.Best(); ok {
+				data["best"] = matchJSON(best)
+			}
+			jsonData, err := json.Marshal(data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal match data: %w", err)
+			}
+			return mcp.NewToolResultText(string(jsonData)), nil
+		},
+	)
+
+	// Click image tool
+	s.AddTool(
+		mcp.NewTool("mouse_click_image",
+			append([]mcp.ToolOption{
+				mcp.WithDescription("Find a template image on the screen and click the center of the best match"),
+				mcp.WithString("button", mcp.DefaultString("left"), mcp.Enum("left", "right", "middle"), mcp.Description("Mouse button to click")),
+				mcp.WithNumber("count", mcp.DefaultNumber(1), mcp.Min(1), mcp.Max(automation.MaxClickCount), mcp.Description("Number of clicks: 2 for a double click, 3 for a triple click")),
+			}, findImageOptions...)...,
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			button, err := automation.ParseButton(req.GetString("button", "left"))
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid button: %v", err)), nil
+			}
+
+			result, errResult := findImage(req, screen, 1)
+			if errResult != nil {
+				return errResult, nil
+			}
+
+			best, ok := result.Best()
+			if !ok {
+				return mcp.NewToolResultError("Image not found on screen"), nil
+			}
+
+			x, y := best.Center()
+			if failed := checkPointer(ctx, x, y); failed != nil {
+				return failed, nil
+			}
+
+			count := req.GetInt("count", 1)
+			if err := mouse.ClickButton(x, y, button, count); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to click mouse: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Clicked %s button %d time(s) at (%d, %d), confidence %.3f", button, count, x, y, best.Confidence)), nil
+		},
+	)
+}
+
+// findImage loads the template of a request and searches the screen for it.
+// Failures are returned as a tool error result.
+func findImage(req mcp.CallToolRequest, screen *automation.Screen, maxResults int) (imagematch.Result, *mcp.CallToolResult) {
+	path := req.GetString("image_path", "")
+	data := req.GetString("image_base64", "")
+
+	var tmpl image.Image
+	var err error
+	switch {
+	case path != "" && data != "":
+		return imagematch.Result{}, mcp.NewToolResultError("Invalid template: give either image_path or image_base64, not both")
+	case path != "":
+		tmpl, err = imagematch.ReadFile(path)
+	case data != "":
+		tmpl, err = imagematch.DecodeBase64(data)
+	default:
+		return imagematch.Result{}, mcp.NewToolResultError("Invalid template: image_path or image_base64 is required")
+	}
+	if err != nil {
+		return imagematch.Result{}, mcp.NewToolResultError(fmt.Sprintf("Invalid template: %v", err))
+	}
+
+	region, err := optionalRegion(req)
+	if err != nil {
+		return imagematch.Result{}, mcp.NewToolResultError(fmt.Sprintf("Invalid region: %v", err))
+	}
+
+	opts := imagematch.Options{
+		Threshold:  req.GetFloat("threshold", imagematch.DefaultThreshold),
+		MaxResults: maxResults,
+	}
+	result, err := screen.FindImage(tmpl, region, opts)
+	if err != nil {
+		return imagematch.Result{}, mcp.NewToolResultError(fmt.Sprintf("Failed to find image: %v", err))
+	}
+
+	return result, nil
+}
+
+// matchJSON describes a match including its center
+func matchJSON(m imagematch.Match) map[string]interface{} {
+	x, y := m.Center()
+	return map[string]interface{}{
+		"x":          m.X,
+		"y":          m.Y,
+		"width":      m.Width,
+		"height":     m.Height,
+		"center_x":   x,
+		"center_y":   y,
+		"confidence": m.Confidence,
+	}
+}
+
+// matchesJSON describes every match
+func matchesJSON(matches []imagematch.Match) []map[string]interface{} {
+	data := make([]map[string]interface{}, len(matches))
+	for i, m := range matches {
+		data[i] = matchJSON(m)
+	}
+	return data
+}
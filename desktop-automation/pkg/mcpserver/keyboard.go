@@ -0,0 +1,219 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+)
+
+// addKeyboardTools adds keyboard automation tools to the server
+func addKeyboardTools(s *server.MCPServer, keyboard *automation.Keyboard) {
+	// Type text tool
+	s.AddTool(
+		mcp.NewTool("keyboard_type",
+			mcp.WithDescription("Type the specified text. The paste mode puts the text on the clipboard and pastes it, which is faster for long text and works for characters the keyboard layout cannot produce"),
+			mcp.WithString("text", mcp.Required(), mcp.Description("Text to type")),
+			mcp.WithString("mode", mcp.DefaultString("type"), mcp.Enum("type", "paste"), mcp.Description("Type key by key, or paste through the clipboard")),
+			mcp.WithBoolean("restore_clipboard", mcp.DefaultBool(false), mcp.Description("In paste mode, put the previous clipboard contents back afterwards")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			text, err := req.RequireString("text")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid text: %v", err)), nil
+			}
+
+			switch mode := req.GetString("mode", "type"); mode {
+			case "type":
+				if err := keyboard.TypeString(text); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Failed to type text: %v", err)), nil
+				}
+			case "paste":
+				if err := keyboard.Paste(text, req.GetBool("restore_clipboard", false)); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Failed to paste text: %v", err)), nil
+				}
+				return mcp.NewToolResultText(fmt.Sprintf("Pasted: %s", text)), nil
+			default:
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid mode: %q (must be type or paste)", mode)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Typed: %s", text)), nil
+		},
+	)
+
+	// Type text with delay tool
+	s.AddTool(
+		mcp.NewTool("keyboard_type_with_delay",
+			mcp.WithDescription("Type text with delay between keystrokes"),
+			mcp.WithString("text", mcp.Required(), mcp.Description("Text to type")),
+			mcp.WithNumber("delay_ms", mcp.DefaultNumber(100), mcp.Description("Delay between keystrokes in milliseconds")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			text, err := req.RequireString("text")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid text: %v", err)), nil
+			}
+
+			delayMs := req.GetInt("delay_ms", 100)
+
+			if err := keyboard.TypeStringWithDelay(text, delayMs); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to type text with delay: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Typed with %dms delay: %s", delayMs, text)), nil
+		},
+	)
+
+	// Hotkey tool
+	s.AddTool(
+		mcp.NewTool("keyboard_hotkey",
+			mcp.WithDescription("Press a key chord such as ctrl+c, ctrl+shift+t, cmd+space or alt+F4"),
+			mcp.WithString("keys", mcp.Required(), mcp.Description("Key chord: modifiers (ctrl, shift, alt, cmd) followed by a key, joined with '+'; the plus key is 'plus' or a trailing '+' as in ctrl++")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			keys, err := req.RequireString("keys")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid keys: %v", err)), nil
+			}
+
+			chord, err := automation.ParseChord(keys)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid keys: %v", err)), nil
+			}
+
+			if err := keyboard.PressChord(chord); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to press hotkey: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Pressed: %s", chord)), nil
+		},
+	)
+
+	// Key down tool
+	s.AddTool(
+		mcp.NewTool("keyboard_key_down",
+			mcp.WithDescription("Press a key and keep it held, e.g. hold shift while clicking. Release it with keyboard_key_up or keyboard_release_all; keys still held when the session ends are released"),
+			mcp.WithString("key", mcp.Required(), mcp.Description("Key to hold, e.g. shift, ctrl or a")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			key, err := req.RequireString("key")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid key: %v", err)), nil
+			}
+
+			owner := keyOwner(ctx)
+			if err := keyboard.KeyDownFor(owner, key); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to press key: %v", err)), nil
+			}
+			// A client that gave up on the call does not know the key is down
+			if ctx.Err() != nil {
+				keyboard.KeyUpFor(owner, key)
+				return nil, ctx.Err()
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Holding: %s", formatHeld(keyboard.HeldKeysFor(owner)))), nil
+		},
+	)
+
+	// Key up tool
+	s.AddTool(
+		mcp.NewTool("keyboard_key_up",
+			mcp.WithDescription("Release a key held with keyboard_key_down"),
+			mcp.WithString("key", mcp.Required(), mcp.Description("Key to release")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			key, err := req.RequireString("key")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid key: %v", err)), nil
+			}
+
+			owner := keyOwner(ctx)
+			if err := keyboard.KeyUpFor(owner, key); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to release key: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Holding: %s", formatHeld(keyboard.HeldKeysFor(owner)))), nil
+		},
+	)
+
+	// Release all tool
+	s.AddTool(
+		mcp.NewTool("keyboard_release_all",
+			mcp.WithDescription("Release every key this session holds with keyboard_key_down"),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if err := keyboard.ReleaseFor(keyOwner(ctx)); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to release keys: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText("Released all keys"), nil
+		},
+	)
+}
+
+// keyOwner returns who holds the keys pressed in a call: its MCP session, so
+// that clients cannot release each other's keys
+func keyOwner(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// releaseOnSessionEnd releases the keys of a session when it ends. Streamable
+// HTTP sessions without a listening stream are never registered, so they end
+// when the client deletes them, which releaseOnDelete catches.
+func releaseOnSessionEnd(keyboard *automation.Keyboard) server.ServerOption {
+	hooks := &server.Hooks{}
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		keyboard.ReleaseFor(session.SessionID())
+	})
+	return server.WithHooks(hooks)
+}
+
+// sessionIDHeader carries the session of streamable HTTP requests
+const sessionIDHeader = "Mcp-Session-Id"
+
+// releaseOnDelete releases the keys of a streamable HTTP session once the
+// client has deleted it
+func releaseOnDelete(next http.Handler, keyboard *automation.Keyboard) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := r.Header.Get(sessionIDHeader)
+		if r.Method != http.MethodDelete || session == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if rec.status == http.StatusOK {
+			keyboard.ReleaseFor(session)
+		}
+	})
+}
+
+// statusRecorder remembers the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code and writes it
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// formatHeld describes the held keys for a tool result
+func formatHeld(keys []string) string {
+	if len(keys) == 0 {
+		return "no keys"
+	}
+
+	return strings.Join(keys, ", ")
+}
@@ -0,0 +1,48 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+)
+
+const (
+	// Name is the server name reported to MCP clients
+	Name = "Desktop Automation MCP"
+
+	// Version is the server version reported to MCP clients
+	Version = "1.0.0"
+)
+
+// New creates an MCP server exposing the mouse, keyboard, screen, window and
+// clipboard tools
+func New(mouse *automation.Mouse, keyboard *automation.Keyboard, screen *automation.Screen, windows *automation.Windows, clipboard *automation.Clipboard) *server.MCPServer {
+	// Create MCP server with desktop automation capabilities
+	s := server.NewMCPServer(Name, Version,
+		server.WithToolCapabilities(true),
+		releaseOnSessionEnd(keyboard),
+	)
+
+	// Add mouse tools
+	addMouseTools(s, mouse)
+
+	// Add keyboard tools
+	addKeyboardTools(s, keyboard)
+
+	// Add screen tools
+	addScreenTools(s, screen)
+
+	// Add image search tools
+	addImageTools(s, mouse, screen)
+
+	// Add OCR tools
+	addTextTools(s, mouse, screen)
+
+	// Add window tools
+	addWindowTools(s, windows)
+
+	// Add clipboard tools
+	addClipboardTools(s, clipboard)
+
+	return s
+}
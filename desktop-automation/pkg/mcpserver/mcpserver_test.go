@@ -0,0 +1,680 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/display"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/ocr"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/window"
+)
+
+// newTestClient starts an in-process client of a server on the backend
+func newTestClient(t *testing.T, backend *automation.FakeBackend) *client.Client {
+	t.Helper()
+
+	return newTestClientWithOCR(t, backend, ocr.NewFakeEngine())
+}
+
+// newTestClientWithOCR starts an in-process client of a server on the
+// backend that reads text with engine
+func newTestClientWithOCR(t *testing.T, backend *automation.FakeBackend, engine ocr.Engine) *client.Client {
+	t.Helper()
+
+	s := New(automation.NewMouseWithBackend(backend), automation.NewKeyboardWithBackend(backend), automation.NewScreenWithOCR(backend, engine), automation.NewWindowsWithBackend(backend), automation.NewClipboardWithBackend(backend))
+
+	return newInProcessClient(t, s)
+}
+
+// newInProcessClient starts an initialized in-process client of s
+func newInProcessClient(t *testing.T, s *server.MCPServer) *client.Client {
+	t.Helper()
+
+	c, err := client.NewInProcessClient(s)
+	if err != nil {
+		t.Fatalf("NewInProcessClient() failed: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	ctx := context.Background()
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("Start() failed: %v", err)
+	}
+	req := mcp.InitializeRequest{}
+	req.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	req.Params.ClientInfo = mcp.Implementation{Name: "test", Version: "1.0"}
+	if _, err := c.Initialize(ctx, req); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+
+	return c
+}
+
+// callTool calls a tool and returns its result
+func callTool(t *testing.T, c *client.Client, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = name
+	req.Params.Arguments = args
+	result, err := c.CallTool(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CallTool(%s) failed: %v", name, err)
+	}
+
+	return result
+}
+
+// resultText returns the text content of a tool result
+func resultText(result *mcp.CallToolResult) string {
+	var text string
+	for _, content := range result.Content {
+		if c, ok := content.(mcp.TextContent); ok {
+			text += c.Text
+		}
+	}
+
+	return text
+}
+
+func TestToolsDriveBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		tool    string
+		args    map[string]any
+		want    []automation.Event
+		isError bool
+	}{
+		{
+			name: "move",
+			tool: "mouse_move",
+			args: map[string]any{"x": 100, "y": 200},
+			want: []automation.Event{{Kind: automation.EventMove, X: 100, Y: 200}},
+		},
+		{
+			name: "click",
+			tool: "mouse_click",
+			args: map[string]any{"x": 10, "y": 20},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 10, Y: 20},
+				{Kind: automation.EventClick, X: 10, Y: 20, Button: automation.ButtonLeft, Count: 1},
+			},
+		},
+		{
+			name: "middle triple click",
+			tool: "mouse_click",
+			args: map[string]any{"x": 10, "y": 20, "button": "middle", "count": 3},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 10, Y: 20},
+				{Kind: automation.EventClick, X: 10, Y: 20, Button: automation.ButtonMiddle, Count: 3},
+			},
+		},
+		{
+			name:    "too many clicks",
+			tool:    "mouse_click",
+			args:    map[string]any{"x": 10, "y": 20, "count": 4},
+			want:    []automation.Event{},
+			isError: true,
+		},
+		{
+			name: "drag",
+			tool: "mouse_drag",
+			args: map[string]any{"from_x": 10, "from_y": 20, "to_x": 110, "to_y": 220},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 10, Y: 20},
+				{Kind: automation.EventMouseDown, X: 10, Y: 20, Button: automation.ButtonLeft},
+				{Kind: automation.EventMove, X: 110, Y: 220},
+				{Kind: automation.EventMouseUp, X: 110, Y: 220, Button: automation.ButtonLeft},
+			},
+		},
+		{
+			name: "scroll at position",
+			tool: "mouse_scroll",
+			args: map[string]any{"direction": "up", "amount": 120, "unit": "pixels", "x": 30, "y": 40},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 30, Y: 40},
+				{Kind: automation.EventScroll, X: 30, Y: 40, DY: -3},
+			},
+		},
+		{
+			name:    "scroll with only x",
+			tool:    "mouse_scroll",
+			args:    map[string]any{"direction": "down", "amount": 1, "x": 30},
+			want:    []automation.Event{},
+			isError: true,
+		},
+		{
+			name: "type",
+			tool: "keyboard_type",
+			args: map[string]any{"text": "hello"},
+			want: []automation.Event{{Kind: automation.EventType, Text: "hello"}},
+		},
+		{
+			name: "paste",
+			tool: "keyboard_type",
+			args: map[string]any{"text": "grüße, 世界", "mode": "paste"},
+			want: []automation.Event{
+				{Kind: automation.EventClipboard, Text: "grüße, 世界"},
+				{Kind: automation.EventKeyTap, Key: "v", Modifiers: automation.PasteChord().Modifiers},
+			},
+		},
+		{
+			name: "paste and restore",
+			tool: "keyboard_type",
+			args: map[string]any{"text": "hello", "mode": "paste", "restore_clipboard": true},
+			want: []automation.Event{
+				{Kind: automation.EventClipboard, Text: "hello"},
+				{Kind: automation.EventKeyTap, Key: "v", Modifiers: automation.PasteChord().Modifiers},
+				{Kind: automation.EventClipboard, Text: ""},
+			},
+		},
+		{
+			name: "clipboard set",
+			tool: "clipboard_set",
+			args: map[string]any{"text": "copied"},
+			want: []automation.Event{{Kind: automation.EventClipboard, Text: "copied"}},
+		},
+		{
+			name: "hotkey",
+			tool: "keyboard_hotkey",
+			args: map[string]any{"keys": "cmd+space"},
+			want: []automation.Event{{Kind: automation.EventKeyTap, Key: "space", Modifiers: []string{"cmd"}}},
+		},
+		{
+			name:    "unknown hotkey",
+			tool:    "keyboard_hotkey",
+			args:    map[string]any{"keys": "ctrl+bogus"},
+			want:    []automation.Event{},
+			isError: true,
+		},
+		{
+			name:    "release unheld key",
+			tool:    "keyboard_key_up",
+			args:    map[string]any{"key": "shift"},
+			want:    []automation.Event{},
+			isError: true,
+		},
+		{
+			name:    "partial capture region",
+			tool:    "screen_capture",
+			args:    map[string]any{"x": 0, "y": 0, "width": 100},
+			want:    []automation.Event{},
+			isError: true,
+		},
+		{
+			name:    "capture region off screen",
+			tool:    "screen_capture",
+			args:    map[string]any{"x": 1900, "y": 0, "width": 100, "height": 100},
+			want:    []automation.Event{},
+			isError: true,
+		},
+		{
+			name:    "negative coordinate",
+			tool:    "mouse_move",
+			args:    map[string]any{"x": -1, "y": 200},
+			want:    []automation.Event{},
+			isError: true,
+		},
+		{
+			name:    "coordinate off screen",
+			tool:    "mouse_move",
+			args:    map[string]any{"x": automation.FakeScreenWidth, "y": 200},
+			want:    []automation.Event{},
+			isError: true,
+		},
+		{
+			name:    "missing argument",
+			tool:    "mouse_click",
+			args:    map[string]any{"x": 10},
+			want:    []automation.Event{},
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := automation.NewFakeBackend()
+			c := newTestClient(t, backend)
+
+			result := callTool(t, c, tt.tool, tt.args)
+			if result.IsError != tt.isError {
+				t.Fatalf("CallTool(%s) IsError = %v, want %v: %s", tt.tool, result.IsError, tt.isError, resultText(result))
+			}
+			if got := backend.Events(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CallTool(%s) events = %+v, want %+v", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHeldKeys(t *testing.T) {
+	backend := automation.NewFakeBackend()
+	c := newTestClient(t, backend)
+
+	calls := []struct {
+		tool string
+		args map[string]any
+	}{
+		{"keyboard_key_down", map[string]any{"key": "shift"}},
+		{"keyboard_key_down", map[string]any{"key": "ctrl"}},
+		{"mouse_click", map[string]any{"x": 10, "y": 20}},
+		{"keyboard_key_up", map[string]any{"key": "shift"}},
+		{"keyboard_key_down", map[string]any{"key": "alt"}},
+		{"keyboard_release_all", nil},
+	}
+	for _, call := range calls {
+		if result := callTool(t, c, call.tool, call.args); result.IsError {
+			t.Fatalf("CallTool(%s) failed: %s", call.tool, resultText(result))
+		}
+	}
+
+	want := []automation.Event{
+		{Kind: automation.EventKeyDown, Key: "shift"},
+		{Kind: automation.EventKeyDown, Key: "ctrl"},
+		{Kind: automation.EventMove, X: 10, Y: 20},
+		{Kind: automation.EventClick, X: 10, Y: 20, Button: automation.ButtonLeft, Count: 1},
+		{Kind: automation.EventKeyUp, Key: "shift"},
+		{Kind: automation.EventKeyDown, Key: "alt"},
+		{Kind: automation.EventKeyUp, Key: "alt"},
+		{Kind: automation.EventKeyUp, Key: "ctrl"},
+	}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
+
+func TestScreenCapture(t *testing.T) {
+	backend := automation.NewFakeBackend()
+	c := newTestClient(t, backend)
+
+	result := callTool(t, c, "screen_capture", map[string]any{"max_width": 480})
+	if result.IsError {
+		t.Fatalf("CallTool(screen_capture) failed: %s", resultText(result))
+	}
+
+	want := []automation.Event{{Kind: automation.EventCapture, Width: automation.FakeScreenWidth, Height: automation.FakeScreenHeight}}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+
+	if text := resultText(result); !strings.Contains(text, "scale 4") {
+		t.Errorf("result text = %q, want the scale factor 4", text)
+	}
+
+	var image *mcp.ImageContent
+	for _, content := range result.Content {
+		if c, ok := content.(mcp.ImageContent); ok {
+			image = &c
+		}
+	}
+	if image == nil {
+		t.Fatal("result has no image content")
+	}
+	if image.MIMEType != "image/png" {
+		t.Errorf("MIMEType = %q, want image/png", image.MIMEType)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(image.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode() failed: %v", err)
+	}
+	if got := img.Bounds().Size(); got.X != 480 || got.Y != 270 {
+		t.Errorf("image size = %v, want 480x270", got)
+	}
+}
+
+func TestMultipleDisplays(t *testing.T) {
+	backend := automation.NewFakeBackend()
+	backend.SetDisplays(display.Layout{
+		{ID: 0, Width: 1920, Height: 1080, Primary: true, ScaleFactor: 1},
+		{ID: 1, X: -1280, Y: 0, Width: 1280, Height: 720, ScaleFactor: 2},
+	})
+	c := newTestClient(t, backend)
+
+	result := callTool(t, c, "screen_info", nil)
+	if result.IsError {
+		t.Fatalf("CallTool(screen_info) failed: %s", resultText(result))
+	}
+	var info struct {
+		Displays []display.Display `json:"displays"`
+		Bounds   struct {
+			X, Y, Width, Height int
+		} `json:"bounds"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &info); err != nil {
+		t.Fatalf("screen_info returned invalid JSON: %v", err)
+	}
+	if len(info.Displays) != 2 || info.Displays[1].ScaleFactor != 2 {
+		t.Errorf("displays = %+v, want both displays", info.Displays)
+	}
+	if info.Bounds.X != -1280 || info.Bounds.Width != 3200 || info.Bounds.Height != 1080 {
+		t.Errorf("bounds = %+v, want 3200x1080 at (-1280, 0)", info.Bounds)
+	}
+
+	// The left display is reachable, the gap below it is not
+	if result := callTool(t, c, "mouse_move", map[string]any{"x": -100, "y": 700}); result.IsError {
+		t.Errorf("moving onto the left display failed: %s", resultText(result))
+	}
+	if result := callTool(t, c, "mouse_move", map[string]any{"x": -100, "y": 800}); !result.IsError {
+		t.Errorf("moving below the left display succeeded: %s", resultText(result))
+	}
+
+	want := []automation.Event{{Kind: automation.EventMove, X: -100, Y: 700}}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
+
+func TestWaitForPixel(t *testing.T) {
+	backend := automation.NewFakeBackend()
+	backend.SetScreenImage(image.NewUniform(color.Black))
+	c := newTestClient(t, backend)
+
+	// The screen turns white while the tool is polling
+	timer := time.AfterFunc(50*time.Millisecond, func() {
+		backend.SetScreenImage(image.NewUniform(color.White))
+	})
+	defer timer.Stop()
+
+	args := map[string]any{"x": 100, "y": 100, "width": 4, "height": 4, "color": "#fafafa", "tolerance": 5, "interval_ms": 10}
+	if result := callTool(t, c, "screen_wait_for_pixel", args); result.IsError {
+		t.Fatalf("CallTool(screen_wait_for_pixel) failed: %s", resultText(result))
+	}
+
+	// It never turns red
+	args = map[string]any{"x": 100, "y": 100, "color": "#ff0000", "timeout_ms": 30, "interval_ms": 10}
+	result := callTool(t, c, "screen_wait_for_pixel", args)
+	if !result.IsError {
+		t.Fatalf("CallTool(screen_wait_for_pixel) succeeded, want a timeout: %s", resultText(result))
+	}
+	if text := resultText(result); !strings.Contains(text, "last color #ffffff") {
+		t.Errorf("result text = %q, want the last color seen", text)
+	}
+}
+
+func TestClickImage(t *testing.T) {
+	// A noisy screen with the template pasted at (700, 400)
+	rng := rand.New(rand.NewSource(1))
+	screen := image.NewRGBA(image.Rect(0, 0, automation.FakeScreenWidth, automation.FakeScreenHeight))
+	for i := range screen.Pix {
+		screen.Pix[i] = uint8(rng.Intn(256))
+		if i%4 == 3 {
+			screen.Pix[i] = 255
+		}
+	}
+	tmpl := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	draw.Draw(tmpl, tmpl.Bounds(), screen, image.Pt(700, 400), draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, tmpl); err != nil {
+		t.Fatal(err)
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	backend := automation.NewFakeBackend()
+	backend.SetScreenImage(screen)
+	c := newTestClient(t, backend)
+
+	args := map[string]any{"image_base64": data, "x": 600, "y": 300, "width": 400, "height": 300, "button": "right"}
+	result := callTool(t, c, "mouse_click_image", args)
+	if result.IsError {
+		t.Fatalf("CallTool(mouse_click_image) failed: %s", resultText(result))
+	}
+
+	want := []automation.Event{
+		{Kind: automation.EventCapture, X: 600, Y: 300, Width: 400, Height: 300},
+		{Kind: automation.EventMove, X: 720, Y: 415},
+		{Kind: automation.EventClick, X: 720, Y: 415, Button: automation.ButtonRight, Count: 1},
+	}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+
+	// A template that is nowhere on the screen is reported as an error
+	draw.Draw(tmpl, tmpl.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	tmpl.Set(5, 5, color.Black)
+	buf.Reset()
+	if err := png.Encode(&buf, tmpl); err != nil {
+		t.Fatal(err)
+	}
+	args = map[string]any{"image_base64": base64.StdEncoding.EncodeToString(buf.Bytes())}
+	if result := callTool(t, c, "mouse_click_image", args); !result.IsError {
+		t.Errorf("CallTool(mouse_click_image) succeeded for a missing image: %s", resultText(result))
+	}
+}
+
+func TestClickText(t *testing.T) {
+	backend := automation.NewFakeBackend()
+	engine := ocr.NewFakeEngine(
+		ocr.Word{Text: "File", X: 10, Y: 5, Width: 30, Height: 12, Confidence: 0.95},
+		ocr.Word{Text: "Save", X: 50, Y: 5, Width: 34, Height: 12, Confidence: 0.9},
+		ocr.Word{Text: "Save", X: 400, Y: 300, Width: 40, Height: 20, Confidence: 0.97},
+		ocr.Word{Text: "As...", X: 444, Y: 300, Width: 36, Height: 20, Confidence: 0.8},
+	)
+	c := newTestClientWithOCR(t, backend, engine)
+
+	// The phrase is merged into one match
+	result := callTool(t, c, "screen_find_text", map[string]any{"text": "save as"})
+	if result.IsError {
+		t.Fatalf("CallTool(screen_find_text) failed: %s", resultText(result))
+	}
+	var found struct {
+		Found   bool `json:"found"`
+		Matches []struct {
+			Text  string `json:"text"`
+			Width int    `json:"width"`
+		} `json:"matches"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &found); err != nil {
+		t.Fatalf("screen_find_text returned invalid JSON: %v", err)
+	}
+	if !found.Found || len(found.Matches) != 1 || found.Matches[0].Text != "Save As..." || found.Matches[0].Width != 80 {
+		t.Errorf("screen_find_text = %+v, want the single match Save As...", found)
+	}
+
+	// Only the second Save lies in the region
+	args := map[string]any{"text": "Save", "exact": true, "x": 300, "y": 200, "width": 300, "height": 200}
+	if result := callTool(t, c, "mouse_click_text", args); result.IsError {
+		t.Fatalf("CallTool(mouse_click_text) failed: %s", resultText(result))
+	}
+	if result := callTool(t, c, "mouse_click_text", map[string]any{"text": "Save", "occurrence": 3}); !result.IsError {
+		t.Errorf("CallTool(mouse_click_text) clicked a missing occurrence: %s", resultText(result))
+	}
+
+	want := []automation.Event{
+		{Kind: automation.EventCapture, Width: automation.FakeScreenWidth, Height: automation.FakeScreenHeight},
+		{Kind: automation.EventCapture, X: 300, Y: 200, Width: 300, Height: 200},
+		{Kind: automation.EventMove, X: 420, Y: 310},
+		{Kind: automation.EventClick, X: 420, Y: 310, Button: automation.ButtonLeft, Count: 1},
+		{Kind: automation.EventCapture, Width: automation.FakeScreenWidth, Height: automation.FakeScreenHeight},
+	}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
+
+func TestWindows(t *testing.T) {
+	backend := automation.NewFakeBackend()
+	backend.SetWindows(
+		window.Window{ID: 0x100, Title: "Editor - notes.txt", PID: 5, Process: "editor", Width: 800, Height: 600, Focused: true},
+		window.Window{ID: 0x200, Title: "Terminal", PID: 6, Process: "xterm", X: 100, Y: 50, Width: 640, Height: 480},
+	)
+	c := newTestClient(t, backend)
+
+	result := callTool(t, c, "window_list", map[string]any{"title": "(?i)term"})
+	if result.IsError {
+		t.Fatalf("CallTool(window_list) failed: %s", resultText(result))
+	}
+	var list struct {
+		Windows []window.Window `json:"windows"`
+	}
+	if err := json.Unmarshal([]byte(resultText(result)), &list); err != nil {
+		t.Fatalf("window_list returned invalid JSON: %v", err)
+	}
+	if len(list.Windows) != 1 || list.Windows[0].ID != 0x200 || list.Windows[0].Process != "xterm" {
+		t.Errorf("windows = %+v, want only the terminal", list.Windows)
+	}
+
+	if result := callTool(t, c, "window_activate", nil); !result.IsError {
+		t.Errorf("activating without a selector succeeded: %s", resultText(result))
+	}
+	if result := callTool(t, c, "window_activate", map[string]any{"title": "["}); !result.IsError {
+		t.Errorf("activating with an invalid pattern succeeded: %s", resultText(result))
+	}
+	if result := callTool(t, c, "window_activate", map[string]any{"pid": 6}); result.IsError {
+		t.Fatalf("CallTool(window_activate) failed: %s", resultText(result))
+	}
+
+	result = callTool(t, c, "window_get_active", nil)
+	if result.IsError {
+		t.Fatalf("CallTool(window_get_active) failed: %s", resultText(result))
+	}
+	var active window.Window
+	if err := json.Unmarshal([]byte(resultText(result)), &active); err != nil {
+		t.Fatalf("window_get_active returned invalid JSON: %v", err)
+	}
+	if active.ID != 0x200 || !active.Focused {
+		t.Errorf("active window = %+v, want the terminal", active)
+	}
+
+	for _, args := range []map[string]any{
+		{"title": "Terminal", "x": 0, "y": 0},
+		{"title": "Terminal", "width": 800, "height": 600},
+		{"title": "Terminal", "x": 5, "y": 5, "width": 400, "height": 300},
+	} {
+		if result := callTool(t, c, "window_set_bounds", args); result.IsError {
+			t.Fatalf("CallTool(window_set_bounds, %v) failed: %s", args, resultText(result))
+		}
+	}
+	for _, args := range []map[string]any{
+		{"title": "Terminal"},
+		{"title": "Terminal", "x": 0},
+		{"title": "Terminal", "width": 0, "height": 10},
+	} {
+		if result := callTool(t, c, "window_set_bounds", args); !result.IsError {
+			t.Errorf("window_set_bounds with %v succeeded: %s", args, resultText(result))
+		}
+	}
+
+	if result := callTool(t, c, "window_set_state", map[string]any{"pid": 5, "state": "maximized"}); result.IsError {
+		t.Fatalf("CallTool(window_set_state) failed: %s", resultText(result))
+	}
+	if result := callTool(t, c, "window_close", map[string]any{"title": "Editor"}); result.IsError {
+		t.Fatalf("CallTool(window_close) failed: %s", resultText(result))
+	}
+	if result := callTool(t, c, "window_close", map[string]any{"title": "Editor"}); !result.IsError {
+		t.Errorf("closing a closed window succeeded: %s", resultText(result))
+	}
+
+	want := []automation.Event{
+		{Kind: automation.EventActivate, Window: 0x200},
+		{Kind: automation.EventSetBounds, Window: 0x200, X: 0, Y: 0, Width: 640, Height: 480},
+		{Kind: automation.EventSetBounds, Window: 0x200, X: 0, Y: 0, Width: 800, Height: 600},
+		{Kind: automation.EventSetBounds, Window: 0x200, X: 5, Y: 5, Width: 400, Height: 300},
+		{Kind: automation.EventSetState, Window: 0x100, State: window.StateMaximized},
+		{Kind: automation.EventClose, Window: 0x100},
+	}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
+
+func TestCoordinateFrames(t *testing.T) {
+	backend := automation.NewFakeBackend()
+	backend.SetDisplays(display.Layout{
+		{ID: 0, Width: 1920, Height: 1080, Primary: true, ScaleFactor: 1},
+		{ID: 1, X: -1280, Width: 1280, Height: 720, ScaleFactor: 1},
+	})
+	backend.SetWindows(window.Window{ID: 0x100, Title: "Editor", PID: 42, X: 100, Y: 200, Width: 640, Height: 480})
+	c := newTestClient(t, backend)
+
+	result := callTool(t, c, "mouse_click", map[string]any{"x": 10, "y": 20, "window_title": "^Edit"})
+	if result.IsError {
+		t.Fatalf("CallTool(mouse_click) failed: %s", resultText(result))
+	}
+	if got := resultText(result); !strings.Contains(got, "(110, 220)") {
+		t.Errorf("mouse_click result = %q, want the resolved coordinates (110, 220)", got)
+	}
+
+	result = callTool(t, c, "mouse_move", map[string]any{"x": 30, "y": 40, "display": 1})
+	if result.IsError {
+		t.Fatalf("CallTool(mouse_move) failed: %s", resultText(result))
+	}
+	if got := resultText(result); !strings.Contains(got, "(-1250, 40)") {
+		t.Errorf("mouse_move result = %q, want the resolved coordinates (-1250, 40)", got)
+	}
+
+	// The window moved since the last call
+	backend.SetWindows(window.Window{ID: 0x100, Title: "Editor", PID: 42, X: 300, Y: 250, Width: 640, Height: 480})
+	result = callTool(t, c, "mouse_drag", map[string]any{"from_x": 0, "from_y": 0, "to_x": 5, "to_y": 5, "frame": "window", "window_pid": 42})
+	if result.IsError {
+		t.Fatalf("CallTool(mouse_drag) failed: %s", resultText(result))
+	}
+
+	for name, args := range map[string]map[string]any{
+		"unknown window":   {"x": 1, "y": 1, "window_title": "Browser"},
+		"unknown display":  {"x": 1, "y": 1, "display": 5},
+		"window and frame": {"x": 1, "y": 1, "window_title": "Editor", "frame": "display"},
+		"unknown frame":    {"x": 1, "y": 1, "frame": "sideways"},
+	} {
+		if result := callTool(t, c, "mouse_move", args); !result.IsError {
+			t.Errorf("%s: mouse_move succeeded: %s", name, resultText(result))
+		}
+	}
+
+	want := []automation.Event{
+		{Kind: automation.EventMove, X: 110, Y: 220},
+		{Kind: automation.EventClick, X: 110, Y: 220, Button: automation.ButtonLeft, Count: 1},
+		{Kind: automation.EventMove, X: -1250, Y: 40},
+		{Kind: automation.EventMove, X: 300, Y: 250},
+		{Kind: automation.EventMouseDown, X: 300, Y: 250, Button: automation.ButtonLeft},
+		{Kind: automation.EventMove, X: 305, Y: 255},
+		{Kind: automation.EventMouseUp, X: 305, Y: 255, Button: automation.ButtonLeft},
+	}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
+
+func TestClipboard(t *testing.T) {
+	backend := automation.NewFakeBackend()
+	c := newTestClient(t, backend)
+
+	if result := callTool(t, c, "clipboard_set", map[string]any{"text": "before"}); result.IsError {
+		t.Fatalf("CallTool(clipboard_set) failed: %s", resultText(result))
+	}
+	if result := callTool(t, c, "keyboard_type", map[string]any{"text": "pasted", "mode": "paste", "restore_clipboard": true}); result.IsError {
+		t.Fatalf("CallTool(keyboard_type) failed: %s", resultText(result))
+	}
+
+	result := callTool(t, c, "clipboard_get", nil)
+	if result.IsError {
+		t.Fatalf("CallTool(clipboard_get) failed: %s", resultText(result))
+	}
+	if got := resultText(result); got != "before" {
+		t.Errorf("clipboard = %q, want the restored %q", got, "before")
+	}
+
+	if result := callTool(t, c, "keyboard_type", map[string]any{"text": "x", "mode": "shout"}); !result.IsError {
+		t.Errorf("typing in an unknown mode succeeded: %s", resultText(result))
+	}
+}
@@ -0,0 +1,408 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+)
+
+// addMouseTools adds mouse automation tools to the server
+func addMouseTools(s *server.MCPServer, mouse *automation.Mouse) {
+	// Mouse move tool
+	s.AddTool(
+		mcp.NewTool("mouse_move", append([]mcp.ToolOption{
+			mcp.WithDescription("Move mouse cursor to specified coordinates"),
+			mcp.WithNumber("x", mcp.Required(), mcp.Description("X coordinate")),
+			mcp.WithNumber("y", mcp.Required(), mcp.Description("Y coordinate")),
+		}, frameOptions()...)...),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			x, err := req.RequireInt("x")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid x coordinate: %v", err)), nil
+			}
+
+			y, err := req.RequireInt("y")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid y coordinate: %v", err)), nil
+			}
+
+			frame, err := requestFrame(req)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid frame: %v", err)), nil
+			}
+
+			sx, sy, failed := resolvePoint(ctx, mouse, frame, x, y)
+			if failed != nil {
+				return failed, nil
+			}
+
+			if err := mouse.Move(sx, sy); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to move mouse: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Mouse moved to %s", frame.FormatPoint(x, y, sx, sy))), nil
+		},
+	)
+
+	// Mouse smooth move tool
+	s.AddTool(
+		mcp.NewTool("mouse_smooth_move", append([]mcp.ToolOption{
+			mcp.WithDescription("Move mouse cursor smoothly to specified coordinates"),
+			mcp.WithNumber("x", mcp.Required(), mcp.Description("X coordinate")),
+			mcp.WithNumber("y", mcp.Required(), mcp.Description("Y coordinate")),
+			mcp.WithNumber("duration", mcp.DefaultNumber(1.0), mcp.Description("Duration in seconds")),
+		}, frameOptions()...)...),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			x, err := req.RequireInt("x")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid x coordinate: %v", err)), nil
+			}
+
+			y, err := req.RequireInt("y")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid y coordinate: %v", err)), nil
+			}
+
+			duration := 1.0
+			if d := req.GetFloat("duration", 1.0); d != 1.0 {
+				duration = d
+			}
+
+			frame, err := requestFrame(req)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid frame: %v", err)), nil
+			}
+
+			sx, sy, failed := resolvePoint(ctx, mouse, frame, x, y)
+			if failed != nil {
+				return failed, nil
+			}
+
+			if err := mouse.SmoothMove(sx, sy, duration); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to smooth move mouse: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Mouse smoothly moved to %s over %.1fs", frame.FormatPoint(x, y, sx, sy), duration)), nil
+		},
+	)
+
+	// Mouse click tool
+	s.AddTool(
+		mcp.NewTool("mouse_click", append([]mcp.ToolOption{
+			mcp.WithDescription("Click at specified coordinates"),
+			mcp.WithNumber("x", mcp.Required(), mcp.Description("X coordinate")),
+			mcp.WithNumber("y", mcp.Required(), mcp.Description("Y coordinate")),
+			mcp.WithString("button", mcp.DefaultString("left"), mcp.Enum("left", "right", "middle"), mcp.Description("Mouse button to click")),
+			mcp.WithNumber("count", mcp.DefaultNumber(1), mcp.Min(1), mcp.Max(automation.MaxClickCount), mcp.Description("Number of clicks: 2 for a double click, 3 for a triple click")),
+		}, frameOptions()...)...),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			x, err := req.RequireInt("x")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid x coordinate: %v", err)), nil
+			}
+
+			y, err := req.RequireInt("y")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid y coordinate: %v", err)), nil
+			}
+
+			button, err := automation.ParseButton(req.GetString("button", "left"))
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid button: %v", err)), nil
+			}
+
+			count := req.GetInt("count", 1)
+
+			frame, err := requestFrame(req)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid frame: %v", err)), nil
+			}
+
+			sx, sy, failed := resolvePoint(ctx, mouse, frame, x, y)
+			if failed != nil {
+				return failed, nil
+			}
+
+			if err := mouse.ClickButton(sx, sy, button, count); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to click mouse: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Clicked %s button %d time(s) at %s", button, count, frame.FormatPoint(x, y, sx, sy))), nil
+		},
+	)
+
+	// Mouse drag tool
+	s.AddTool(
+		mcp.NewTool("mouse_drag", append([]mcp.ToolOption{
+			mcp.WithDescription("Drag with a mouse button held from one coordinate to another, e.g. to move a file or select a range"),
+			mcp.WithNumber("from_x", mcp.Required(), mcp.Description("X coordinate to start the drag at")),
+			mcp.WithNumber("from_y", mcp.Required(), mcp.Description("Y coordinate to start the drag at")),
+			mcp.WithNumber("to_x", mcp.Required(), mcp.Description("X coordinate to drop at")),
+			mcp.WithNumber("to_y", mcp.Required(), mcp.Description("Y coordinate to drop at")),
+			mcp.WithString("button", mcp.DefaultString("left"), mcp.Enum("left", "right", "middle"), mcp.Description("Mouse button to hold")),
+			mcp.WithBoolean("smooth", mcp.DefaultBool(false), mcp.Description("Move through intermediate positions instead of jumping to the target")),
+			mcp.WithNumber("duration", mcp.DefaultNumber(1.0), mcp.Description("Duration of a smooth drag in seconds")),
+			mcp.WithNumber("steps", mcp.DefaultNumber(automation.DefaultDragSteps), mcp.Description("Number of intermediate moves of a smooth drag")),
+		}, frameOptions()...)...),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			fromX, err := req.RequireInt("from_x")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid from_x coordinate: %v", err)), nil
+			}
+
+			fromY, err := req.RequireInt("from_y")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid from_y coordinate: %v", err)), nil
+			}
+
+			toX, err := req.RequireInt("to_x")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid to_x coordinate: %v", err)), nil
+			}
+
+			toY, err := req.RequireInt("to_y")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid to_y coordinate: %v", err)), nil
+			}
+
+			button, err := automation.ParseButton(req.GetString("button", "left"))
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid button: %v", err)), nil
+			}
+
+			opts := automation.DragOptions{
+				Button:   button,
+				Smooth:   req.GetBool("smooth", false),
+				Duration: req.GetFloat("duration", 1.0),
+				Steps:    req.GetInt("steps", automation.DefaultDragSteps),
+			}
+
+			frame, err := requestFrame(req)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid frame: %v", err)), nil
+			}
+
+			sFromX, sFromY, failed := resolvePoint(ctx, mouse, frame, fromX, fromY)
+			if failed != nil {
+				return failed, nil
+			}
+
+			sToX, sToY, failed := resolvePoint(ctx, mouse, frame, toX, toY)
+			if failed != nil {
+				return failed, nil
+			}
+
+			// The button is held along the whole path, so every point of
+			// it must be allowed
+			if failed := checkPointerPath(ctx, automation.DragPath(sFromX, sFromY, sToX, sToY, opts)); failed != nil {
+				return failed, nil
+			}
+
+			if err := mouse.Drag(ctx, sFromX, sFromY, sToX, sToY, opts); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to drag mouse: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Dragged from %s to %s", frame.FormatPoint(fromX, fromY, sFromX, sFromY), frame.FormatPoint(toX, toY, sToX, sToY))), nil
+		},
+	)
+
+	// Mouse scroll tool
+	s.AddTool(
+		mcp.NewTool("mouse_scroll", append([]mcp.ToolOption{
+			mcp.WithDescription("Scroll the mouse wheel, optionally at the given coordinates"),
+			mcp.WithString("direction", mcp.Required(), mcp.Enum("up", "down", "left", "right"), mcp.Description("Direction to scroll")),
+			mcp.WithNumber("amount", mcp.Required(), mcp.Min(1), mcp.Description("How far to scroll, in the given unit")),
+			mcp.WithString("unit", mcp.DefaultString("notches"), mcp.Enum("notches", "pixels"), mcp.Description("Unit of amount: wheel notches or approximate pixels")),
+			mcp.WithNumber("x", mcp.Description("X coordinate to scroll at; requires y. Defaults to the current position")),
+			mcp.WithNumber("y", mcp.Description("Y coordinate to scroll at; requires x. Defaults to the current position")),
+			mcp.WithBoolean("smooth", mcp.DefaultBool(false), mcp.Description("Scroll one notch at a time spread over duration")),
+			mcp.WithNumber("duration", mcp.DefaultNumber(1.0), mcp.Max(automation.MaxScrollDuration), mcp.Description("Duration of a smooth scroll in seconds")),
+		}, frameOptions()...)...),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			direction, err := automation.ParseScrollDirection(req.GetString("direction", ""))
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid direction: %v", err)), nil
+			}
+
+			amount, err := req.RequireInt("amount")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid amount: %v", err)), nil
+			}
+			if amount <= 0 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid amount: %d (must be positive)", amount)), nil
+			}
+
+			opts := automation.ScrollOptions{
+				Unit:     automation.ScrollUnit(req.GetString("unit", string(automation.ScrollNotches))),
+				Smooth:   req.GetBool("smooth", false),
+				Duration: req.GetFloat("duration", 1.0),
+			}
+
+			dx, dy := direction.Delta(amount)
+			args := req.GetArguments()
+			_, hasX := args["x"]
+			_, hasY := args["y"]
+			if hasX != hasY {
+				return mcp.NewToolResultError("Invalid coordinates: x and y must be given together"), nil
+			}
+
+			frame, err := requestFrame(req)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid frame: %v", err)), nil
+			}
+			if !hasX && !frame.IsAbsolute() {
+				return mcp.NewToolResultError("Invalid frame: a window or display frame requires x and y"), nil
+			}
+
+			if hasX {
+				x, err := req.RequireInt("x")
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Invalid x coordinate: %v", err)), nil
+				}
+
+				y, err := req.RequireInt("y")
+				if err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Invalid y coordinate: %v", err)), nil
+				}
+
+				sx, sy, failed := resolvePoint(ctx, mouse, frame, x, y)
+				if failed != nil {
+					return failed, nil
+				}
+
+				if err := mouse.ScrollAt(ctx, sx, sy, dx, dy, opts); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Failed to scroll mouse: %v", err)), nil
+				}
+
+				return mcp.NewToolResultText(fmt.Sprintf("Scrolled %s by %d %s at %s", direction, amount, opts.Unit, frame.FormatPoint(x, y, sx, sy))), nil
+			}
+
+			x, y := mouse.GetPosition()
+			if failed := checkPointer(ctx, x, y); failed != nil {
+				return failed, nil
+			}
+
+			if err := mouse.Scroll(ctx, dx, dy, opts); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to scroll mouse: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Scrolled %s by %d %s", direction, amount, opts.Unit)), nil
+		},
+	)
+
+	// Get mouse position tool
+	s.AddTool(
+		mcp.NewTool("mouse_get_position",
+			mcp.WithDescription("Get current mouse cursor position"),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			x, y := mouse.GetPosition()
+			result := map[string]interface{}{
+				"x": x,
+				"y": y,
+			}
+			jsonData, err := json.Marshal(result)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal position data: %w", err)
+			}
+			return mcp.NewToolResultText(string(jsonData)), nil
+		},
+	)
+}
+
+// frameOptions returns the arguments choosing the coordinate frame of a tool
+func frameOptions() []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithString("frame", mcp.Enum(string(automation.FrameAbsolute), string(automation.FrameWindow), string(automation.FrameDisplay)), mcp.Description("What the coordinates are relative to: the screen, a window or a display. Inferred from window_title, window_pid and display when omitted")),
+		mcp.WithString("window_title", mcp.Description("Regular expression matching the title of the window the coordinates are relative to")),
+		mcp.WithNumber("window_pid", mcp.Description("ID of the process owning the window the coordinates are relative to")),
+		mcp.WithNumber("display", mcp.Description("ID of the display the coordinates are relative to; the primary display if omitted")),
+	}
+}
+
+// requestFrame returns the coordinate frame given by the frame arguments
+func requestFrame(req mcp.CallToolRequest) (automation.Frame, error) {
+	return automation.ParseFrame(req.GetString("frame", ""), req.GetString("window_title", ""), req.GetInt("window_pid", 0), req.GetInt("display", -1))
+}
+
+// requestPoints returns the screen points a call of a mouse tool with
+// coordinates puts pointer input on, so that they can be checked before the
+// call waits for approval. Calls with invalid arguments have no points; their
+// handlers report them. Points that cannot be resolved, e.g. because they lie
+// outside the window of a window frame, return a tool error.
+func requestPoints(mouse *automation.Mouse, req mcp.CallToolRequest) ([]image.Point, *mcp.CallToolResult) {
+	frame, err := requestFrame(req)
+	if err != nil {
+		return nil, nil
+	}
+	resolve := func(xArg, yArg string) (image.Point, bool, error) {
+		x, errX := req.RequireInt(xArg)
+		y, errY := req.RequireInt(yArg)
+		if errX != nil || errY != nil {
+			return image.Point{}, false, nil
+		}
+		sx, sy, err := mouse.Resolve(frame, x, y)
+		return image.Pt(sx, sy), err == nil, err
+	}
+
+	var points []image.Point
+	switch req.Params.Name {
+	case "mouse_move", "mouse_smooth_move", "mouse_click":
+		pt, ok, err := resolve("x", "y")
+		if err != nil {
+			return nil, mcp.NewToolResultError(fmt.Sprintf("Failed to resolve coordinates: %v", err))
+		}
+		if ok {
+			points = append(points, pt)
+		}
+	case "mouse_drag":
+		from, fromOK, err := resolve("from_x", "from_y")
+		if err != nil {
+			return nil, mcp.NewToolResultError(fmt.Sprintf("Failed to resolve coordinates: %v", err))
+		}
+		to, toOK, err := resolve("to_x", "to_y")
+		if err != nil {
+			return nil, mcp.NewToolResultError(fmt.Sprintf("Failed to resolve coordinates: %v", err))
+		}
+		if fromOK && toOK {
+			opts := automation.DragOptions{
+				Smooth: req.GetBool("smooth", false),
+				Steps:  req.GetInt("steps", automation.DefaultDragSteps),
+			}
+			points = automation.DragPath(from.X, from.Y, to.X, to.Y, opts)
+		}
+	case "mouse_scroll":
+		if _, hasX := req.GetArguments()["x"]; !hasX {
+			x, y := mouse.GetPosition()
+			return []image.Point{image.Pt(x, y)}, nil
+		}
+		pt, ok, err := resolve("x", "y")
+		if err != nil {
+			return nil, mcp.NewToolResultError(fmt.Sprintf("Failed to resolve coordinates: %v", err))
+		}
+		if ok {
+			points = append(points, pt)
+		}
+	}
+
+	return points, nil
+}
+
+// resolvePoint resolves (x, y) in frame to screen coordinates that the policy
+// of the call allows pointer input at, wrapping the failure as a tool error
+func resolvePoint(ctx context.Context, mouse *automation.Mouse, frame automation.Frame, x, y int) (int, int, *mcp.CallToolResult) {
+	sx, sy, err := mouse.Resolve(frame, x, y)
+	if err != nil {
+		return 0, 0, mcp.NewToolResultError(fmt.Sprintf("Failed to resolve coordinates: %v", err))
+	}
+	if failed := checkPointer(ctx, sx, sy); failed != nil {
+		return 0, 0, failed
+	}
+	return sx, sy, nil
+}
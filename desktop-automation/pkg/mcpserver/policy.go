@@ -0,0 +1,437 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/window"
+	"gopkg.in/yaml.v3"
+)
+
+// Policy rules, as reported in violations
+const (
+	RuleToolsAllow    = "tools.allow"
+	RuleToolsDeny     = "tools.deny"
+	RulePointer       = "pointer"
+	RuleMaxTextLength = "keyboard.max_text_length"
+	RuleDenyText      = "keyboard.deny_text"
+	RuleRequireFocus  = "keyboard.require_focus"
+)
+
+// textTools are the tools whose text argument reaches the keyboard, directly
+// or by pasting from the clipboard
+var textTools = map[string]bool{
+	"keyboard_type":            true,
+	"keyboard_type_with_delay": true,
+	"clipboard_set":            true,
+}
+
+// focusTools are the tools that send key presses to the focused window.
+// Releasing keys is always allowed.
+var focusTools = map[string]bool{
+	"keyboard_type":            true,
+	"keyboard_type_with_delay": true,
+	"keyboard_hotkey":          true,
+	"keyboard_key_down":        true,
+}
+
+// Policy limits what clients may do with the tools. The zero Policy allows
+// everything. A policy file looks like:
+//
+//	tools:
+//	  deny: [window_close, "clipboard_*"]
+//	pointer:
+//	  regions: ["0,0,1920,1080"]
+//	  windows: ["^Editor"]
+//	keyboard:
+//	  max_text_length: 200
+//	  deny_text: ['rm\s+-rf', '\bsudo\b']
+//	  require_focus: "^Editor"
+type Policy struct {
+	// Tools enables and disables individual tools
+	Tools ToolPolicy `yaml:"tools"`
+	// Pointer restricts where moves, clicks, drags and scrolls may land
+	Pointer PointerPolicy `yaml:"pointer"`
+	// Keyboard restricts typed text and where keys go
+	Keyboard KeyboardPolicy `yaml:"keyboard"`
+
+	compiled bool
+}
+
+// ToolPolicy enables and disables tools by name or path.Match pattern, like
+// "mouse_*"
+type ToolPolicy struct {
+	// Allow, if set, are the only tools that may be called
+	Allow []string `yaml:"allow,omitempty"`
+	// Deny are tools that may not be called, even if allowed
+	Deny []string `yaml:"deny,omitempty"`
+}
+
+// PointerPolicy restricts pointer input to screen rectangles and windows. A
+// point is allowed if it lies in any of the regions, or if the topmost window
+// at the point is one of the windows; with neither set, every point is
+// allowed. A part of an allowed window that another window covers is not
+// allowed. A smooth drag must keep every point it moves through within them,
+// not just its end points.
+type PointerPolicy struct {
+	// Regions are screen rectangles written as x,y,w,h
+	Regions []string `yaml:"regions,omitempty"`
+	// Windows are regular expressions matching the titles of windows whose
+	// visible parts are allowed, unless the window is minimized
+	Windows []string `yaml:"windows,omitempty"`
+
+	regions []automation.Rect
+	windows []*regexp.Regexp
+}
+
+// KeyboardPolicy restricts keyboard input. The text rules apply to text that
+// is typed, pasted or put on the clipboard, not to single keys sent with
+// keyboard_hotkey or keyboard_key_down.
+type KeyboardPolicy struct {
+	// MaxTextLength caps the characters of a single text; 0 means no limit
+	MaxTextLength int `yaml:"max_text_length,omitempty"`
+	// DenyText are regular expressions that text may not match. Prefix one
+	// with (?i) to ignore case.
+	DenyText []string `yaml:"deny_text,omitempty"`
+	// RequireFocus, if set, is a regular expression that the title of the
+	// focused window must match before any key is pressed
+	RequireFocus string `yaml:"require_focus,omitempty"`
+
+	denyText     []*regexp.Regexp
+	requireFocus *regexp.Regexp
+}
+
+// LoadPolicy reads a policy from a YAML file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+
+	p, err := ParsePolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", path, err)
+	}
+
+	return p, nil
+}
+
+// ParsePolicy decodes a policy written as YAML or JSON. Unknown fields are
+// rejected so that a typo does not silently lift a restriction.
+func ParsePolicy(data []byte) (*Policy, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var p Policy
+	if err := decoder.Decode(&p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := p.compile(); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// compile checks the rules and prepares their patterns
+func (p *Policy) compile() error {
+	if p.compiled {
+		return nil
+	}
+
+	for _, patterns := range [][]string{p.Tools.Allow, p.Tools.Deny} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+			}
+		}
+	}
+
+	p.Pointer.regions = nil
+	for _, s := range p.Pointer.Regions {
+		region, err := automation.ParseRect(s)
+		if err != nil {
+			return fmt.Errorf("invalid pointer region: %w", err)
+		}
+		if region.Width <= 0 || region.Height <= 0 {
+			return fmt.Errorf("invalid pointer region: %s (must have a positive size)", s)
+		}
+		p.Pointer.regions = append(p.Pointer.regions, region)
+	}
+	p.Pointer.windows = nil
+	for _, s := range p.Pointer.Windows {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return fmt.Errorf("invalid pointer window %q: %w", s, err)
+		}
+		p.Pointer.windows = append(p.Pointer.windows, re)
+	}
+
+	if p.Keyboard.MaxTextLength < 0 {
+		return fmt.Errorf("invalid max_text_length: %d (must not be negative)", p.Keyboard.MaxTextLength)
+	}
+	p.Keyboard.denyText = nil
+	for _, s := range p.Keyboard.DenyText {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return fmt.Errorf("invalid deny_text %q: %w", s, err)
+		}
+		p.Keyboard.denyText = append(p.Keyboard.denyText, re)
+	}
+	p.Keyboard.requireFocus = nil
+	if p.Keyboard.RequireFocus != "" {
+		re, err := regexp.Compile(p.Keyboard.RequireFocus)
+		if err != nil {
+			return fmt.Errorf("invalid require_focus %q: %w", p.Keyboard.RequireFocus, err)
+		}
+		p.Keyboard.requireFocus = re
+	}
+
+	p.compiled = true
+	return nil
+}
+
+// Allows reports whether the policy enables the named tool
+func (p *Policy) Allows(tool string) bool {
+	return p.toolViolation(tool) == nil
+}
+
+// Violation is a tool call refused by a policy
+type Violation struct {
+	// Tool is the tool that was called
+	Tool string `json:"tool"`
+	// Rule is the rule that refused it, such as RulePointer
+	Rule string `json:"rule"`
+	// Message explains the refusal
+	Message string `json:"message"`
+}
+
+// Error describes the violation
+func (v *Violation) Error() string {
+	return fmt.Sprintf("policy violation: %s refused by %s: %s", v.Tool, v.Rule, v.Message)
+}
+
+// result returns the violation as a tool error whose text is a JSON object
+// with the error category, tool, rule and message
+func (v *Violation) result() *mcp.CallToolResult {
+	data, err := json.Marshal(map[string]string{
+		"error":   "policy_violation",
+		"tool":    v.Tool,
+		"rule":    v.Rule,
+		"message": v.Message,
+	})
+	if err != nil {
+		return mcp.NewToolResultError(v.Error())
+	}
+	return mcp.NewToolResultError(string(data))
+}
+
+// install makes s enforce the policy. Tools the policy disables are hidden
+// from clients; calls that break a rule return a Violation as a tool error.
+// Calls are checked before they wait for approval, including where mouse
+// tools with coordinates would put the pointer; their handlers check again,
+// as windows may move while a call waits.
+func (p *Policy) install(s *server.MCPServer, mouse *automation.Mouse, windows *automation.Windows) error {
+	if err := p.compile(); err != nil {
+		return err
+	}
+
+	server.WithToolFilter(func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		allowed := make([]mcp.Tool, 0, len(tools))
+		for _, tool := range tools {
+			if p.Allows(tool.Name) {
+				allowed = append(allowed, tool)
+			}
+		}
+		return allowed
+	})(s)
+
+	server.WithToolHandlerMiddleware(func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			check := &policyCheck{policy: p, tool: req.Params.Name, windows: windows}
+			if v := check.request(req); v != nil {
+				return v.result(), nil
+			}
+			points, failed := requestPoints(mouse, req)
+			if failed != nil {
+				return failed, nil
+			}
+			if len(points) > 0 {
+				if v := check.pointer(points...); v != nil {
+					return v.result(), nil
+				}
+			}
+			return next(context.WithValue(ctx, policyKey{}, check), req)
+		}
+	})(s)
+
+	return nil
+}
+
+// toolViolation returns why the tool is disabled, or nil
+func (p *Policy) toolViolation(tool string) *Violation {
+	if len(p.Tools.Allow) > 0 && !matchesAny(p.Tools.Allow, tool) {
+		return &Violation{Tool: tool, Rule: RuleToolsAllow, Message: "tool is not in the allowed tools"}
+	}
+	if matchesAny(p.Tools.Deny, tool) {
+		return &Violation{Tool: tool, Rule: RuleToolsDeny, Message: "tool is disabled"}
+	}
+	return nil
+}
+
+// matchesAny reports whether name matches one of the path.Match patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// policyKey is the context key of the policy check of a tool call
+type policyKey struct{}
+
+// policyCheck enforces a policy on one tool call
+type policyCheck struct {
+	policy  *Policy
+	tool    string
+	windows *automation.Windows
+}
+
+// request checks the rules that depend only on the request: whether the tool
+// is enabled, its text and the focused window
+func (c *policyCheck) request(req mcp.CallToolRequest) *Violation {
+	if v := c.policy.toolViolation(c.tool); v != nil {
+		return v
+	}
+
+	kb := c.policy.Keyboard
+	if textTools[c.tool] {
+		text := req.GetString("text", "")
+		if n := utf8.RuneCountInString(text); kb.MaxTextLength > 0 && n > kb.MaxTextLength {
+			return &Violation{Tool: c.tool, Rule: RuleMaxTextLength, Message: fmt.Sprintf("text has %d characters, more than the limit of %d", n, kb.MaxTextLength)}
+		}
+		for _, re := range kb.denyText {
+			if re.MatchString(text) {
+				return &Violation{Tool: c.tool, Rule: RuleDenyText, Message: fmt.Sprintf("text matches denied pattern %q", re)}
+			}
+		}
+	}
+
+	if focusTools[c.tool] && kb.requireFocus != nil {
+		active, err := c.windows.Active()
+		if err != nil {
+			return &Violation{Tool: c.tool, Rule: RuleRequireFocus, Message: fmt.Sprintf("cannot tell which window is focused: %v", err)}
+		}
+		if !kb.requireFocus.MatchString(active.Title) {
+			return &Violation{Tool: c.tool, Rule: RuleRequireFocus, Message: fmt.Sprintf("focused window %q does not match %q", active.Title, kb.requireFocus)}
+		}
+	}
+
+	return nil
+}
+
+// pointer checks that pointer input may land on every one of the screen
+// points
+func (c *policyCheck) pointer(points ...image.Point) *Violation {
+	pp := c.policy.Pointer
+	if len(pp.regions) == 0 && len(pp.windows) == 0 {
+		return nil
+	}
+
+	var regions []image.Rectangle
+	for _, r := range pp.regions {
+		regions = append(regions, image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height))
+	}
+	// Windows are listed in stacking order, bottom to top
+	var stack []window.Window
+	if len(pp.windows) > 0 {
+		windows, err := c.windows.List(window.Selector{})
+		if err != nil {
+			return &Violation{Tool: c.tool, Rule: RulePointer, Message: fmt.Sprintf("cannot check the allowed windows: %v", err)}
+		}
+		for _, w := range windows {
+			if w.State != window.StateMinimized {
+				stack = append(stack, w)
+			}
+		}
+	}
+
+	for _, pt := range points {
+		if slices.ContainsFunc(regions, pt.In) {
+			continue
+		}
+		top, ok := topWindow(stack, pt)
+		if !ok {
+			return c.pointerViolation(pt, nil)
+		}
+		if !slices.ContainsFunc(pp.windows, func(re *regexp.Regexp) bool { return re.MatchString(top.Title) }) {
+			return c.pointerViolation(pt, &top)
+		}
+	}
+	return nil
+}
+
+// topWindow returns the topmost of the windows, listed bottom to top, that
+// covers pt
+func topWindow(stack []window.Window, pt image.Point) (window.Window, bool) {
+	for i := len(stack) - 1; i >= 0; i-- {
+		if pt.In(stack[i].Bounds()) {
+			return stack[i], true
+		}
+	}
+	return window.Window{}, false
+}
+
+// pointerViolation reports that pointer input may not land on pt, which top,
+// if known, is the topmost window at
+func (c *policyCheck) pointerViolation(pt image.Point, top *window.Window) *Violation {
+	pp := c.policy.Pointer
+	var allowed []string
+	if len(pp.Regions) > 0 {
+		allowed = append(allowed, "regions "+strings.Join(pp.Regions, "; "))
+	}
+	if len(pp.Windows) > 0 {
+		allowed = append(allowed, "windows matching "+strings.Join(pp.Windows, ", "))
+	}
+	where := fmt.Sprintf("(%d, %d)", pt.X, pt.Y)
+	if top != nil {
+		where += fmt.Sprintf(" on window %q", top.Title)
+	}
+	return &Violation{Tool: c.tool, Rule: RulePointer, Message: fmt.Sprintf("%s is outside the allowed %s", where, strings.Join(allowed, " and "))}
+}
+
+// checkPointer refuses pointer input at the screen point (x, y) when the
+// policy of the tool call in ctx does not allow it
+func checkPointer(ctx context.Context, x, y int) *mcp.CallToolResult {
+	return checkPointerPath(ctx, []image.Point{image.Pt(x, y)})
+}
+
+// checkPointerPath refuses pointer input along the screen points when the
+// policy of the tool call in ctx does not allow every one of them
+func checkPointerPath(ctx context.Context, path []image.Point) *mcp.CallToolResult {
+	check, ok := ctx.Value(policyKey{}).(*policyCheck)
+	if !ok {
+		return nil
+	}
+	if v := check.pointer(path...); v != nil {
+		return v.result()
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/window"
+)
+
+// newPolicyClient starts an in-process client of a server on the backend
+// that enforces the policy written as YAML
+func newPolicyClient(t *testing.T, backend *automation.FakeBackend, policy string) *client.Client {
+	t.Helper()
+
+	p, err := ParsePolicy([]byte(policy))
+	if err != nil {
+		t.Fatalf("ParsePolicy() failed: %v", err)
+	}
+	windows := automation.NewWindowsWithBackend(backend)
+	s := New(automation.NewMouseWithBackend(backend), automation.NewKeyboardWithBackend(backend), automation.NewScreenWithBackend(backend), windows, automation.NewClipboardWithBackend(backend))
+	if err := p.install(s, automation.NewMouseWithBackend(backend), windows); err != nil {
+		t.Fatalf("install() failed: %v", err)
+	}
+
+	return newInProcessClient(t, s)
+}
+
+func TestPolicy(t *testing.T) {
+	editor := window.Window{ID: 1, Title: "Editor - notes.txt", X: 100, Y: 100, Width: 200, Height: 200, Focused: true}
+	hidden := window.Window{ID: 2, Title: "Editor - hidden.txt", X: 400, Y: 400, Width: 100, Height: 100, State: window.StateMinimized}
+	terminal := window.Window{ID: 3, Title: "Terminal", X: 600, Y: 0, Width: 200, Height: 200}
+
+	tests := []struct {
+		name     string
+		policy   string
+		windows  []window.Window
+		tool     string
+		args     map[string]any
+		wantRule string
+		want     []automation.Event
+	}{
+		{
+			name:     "denied tool",
+			policy:   "tools: {deny: [window_close]}",
+			tool:     "window_close",
+			args:     map[string]any{"title": "Editor"},
+			wantRule: RuleToolsDeny,
+		},
+		{
+			name:     "tool outside the allowed ones",
+			policy:   `tools: {allow: ["mouse_*"]}`,
+			tool:     "keyboard_type",
+			args:     map[string]any{"text": "hi"},
+			wantRule: RuleToolsAllow,
+		},
+		{
+			name:   "allowed tool",
+			policy: `tools: {allow: ["mouse_*"], deny: [mouse_drag]}`,
+			tool:   "mouse_move",
+			args:   map[string]any{"x": 1, "y": 2},
+			want:   []automation.Event{{Kind: automation.EventMove, X: 1, Y: 2}},
+		},
+		{
+			name:   "click in region",
+			policy: `pointer: {regions: ["0,0,50,50"]}`,
+			tool:   "mouse_click",
+			args:   map[string]any{"x": 49, "y": 49},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 49, Y: 49},
+				{Kind: automation.EventClick, X: 49, Y: 49, Button: automation.ButtonLeft, Count: 1},
+			},
+		},
+		{
+			name:     "click outside region",
+			policy:   `pointer: {regions: ["0,0,50,50"]}`,
+			tool:     "mouse_click",
+			args:     map[string]any{"x": 50, "y": 10},
+			wantRule: RulePointer,
+		},
+		{
+			name:    "move into window",
+			policy:  `pointer: {windows: ["^Editor"]}`,
+			windows: []window.Window{editor, hidden, terminal},
+			tool:    "mouse_move",
+			args:    map[string]any{"x": 10, "y": 10, "window_title": "notes"},
+			want:    []automation.Event{{Kind: automation.EventMove, X: 110, Y: 110}},
+		},
+		{
+			name:     "move into other window",
+			policy:   `pointer: {windows: ["^Editor"]}`,
+			windows:  []window.Window{editor, hidden, terminal},
+			tool:     "mouse_move",
+			args:     map[string]any{"x": 700, "y": 100},
+			wantRule: RulePointer,
+		},
+		{
+			name:     "click on a window covering the allowed one",
+			policy:   `pointer: {windows: ["^Editor"]}`,
+			windows:  []window.Window{editor, {ID: 4, Title: "Browser", X: 250, Y: 250, Width: 200, Height: 200}},
+			tool:     "mouse_click",
+			args:     map[string]any{"x": 260, "y": 260},
+			wantRule: RulePointer,
+		},
+		{
+			name:    "click on the allowed window covering another",
+			policy:  `pointer: {windows: ["^Editor"]}`,
+			windows: []window.Window{{ID: 4, Title: "Browser", X: 250, Y: 250, Width: 200, Height: 200}, editor},
+			tool:    "mouse_click",
+			args:    map[string]any{"x": 260, "y": 260},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 260, Y: 260},
+				{Kind: automation.EventClick, X: 260, Y: 260, Button: automation.ButtonLeft, Count: 1},
+			},
+		},
+		{
+			name:     "move into minimized window",
+			policy:   `pointer: {windows: ["^Editor"]}`,
+			windows:  []window.Window{editor, hidden, terminal},
+			tool:     "mouse_move",
+			args:     map[string]any{"x": 450, "y": 450},
+			wantRule: RulePointer,
+		},
+		{
+			name:     "drag out of region",
+			policy:   `pointer: {regions: ["0,0,50,50"], windows: ["^Editor"]}`,
+			windows:  []window.Window{editor},
+			tool:     "mouse_drag",
+			args:     map[string]any{"from_x": 10, "from_y": 10, "to_x": 350, "to_y": 150},
+			wantRule: RulePointer,
+		},
+		{
+			name:     "smooth drag through a gap between regions",
+			policy:   `pointer: {regions: ["0,0,50,50", "250,0,50,50"]}`,
+			tool:     "mouse_drag",
+			args:     map[string]any{"from_x": 10, "from_y": 10, "to_x": 260, "to_y": 10, "smooth": true, "duration": 0.01, "steps": 5},
+			wantRule: RulePointer,
+		},
+		{
+			name:   "drag jumping between regions",
+			policy: `pointer: {regions: ["0,0,50,50", "250,0,50,50"]}`,
+			tool:   "mouse_drag",
+			args:   map[string]any{"from_x": 10, "from_y": 10, "to_x": 260, "to_y": 10},
+			want: []automation.Event{
+				{Kind: automation.EventMove, X: 10, Y: 10},
+				{Kind: automation.EventMouseDown, X: 10, Y: 10, Button: automation.ButtonLeft},
+				{Kind: automation.EventMove, X: 260, Y: 10},
+				{Kind: automation.EventMouseUp, X: 260, Y: 10, Button: automation.ButtonLeft},
+			},
+		},
+		{
+			name:     "scroll at current position",
+			policy:   `pointer: {regions: ["10,10,50,50"]}`,
+			tool:     "mouse_scroll",
+			args:     map[string]any{"direction": "down", "amount": 1},
+			wantRule: RulePointer,
+		},
+		{
+			name:     "text too long",
+			policy:   "keyboard: {max_text_length: 5}",
+			tool:     "keyboard_type",
+			args:     map[string]any{"text": "hello!"},
+			wantRule: RuleMaxTextLength,
+		},
+		{
+			name:   "text at the limit",
+			policy: "keyboard: {max_text_length: 5}",
+			tool:   "keyboard_type",
+			args:   map[string]any{"text": "héllo"},
+			want:   []automation.Event{{Kind: automation.EventType, Text: "héllo"}},
+		},
+		{
+			name:     "denied text",
+			policy:   `keyboard: {deny_text: ['rm\s+-rf', '(?i)\bsudo\b']}`,
+			tool:     "keyboard_type_with_delay",
+			args:     map[string]any{"text": "SUDO reboot", "delay_ms": 0},
+			wantRule: RuleDenyText,
+		},
+		{
+			name:     "denied pasted text",
+			policy:   `keyboard: {deny_text: ['rm\s+-rf']}`,
+			tool:     "keyboard_type",
+			args:     map[string]any{"text": "rm  -rf /", "mode": "paste"},
+			wantRule: RuleDenyText,
+		},
+		{
+			name:     "denied clipboard text",
+			policy:   `keyboard: {deny_text: ['rm\s+-rf']}`,
+			tool:     "clipboard_set",
+			args:     map[string]any{"text": "rm -rf /"},
+			wantRule: RuleDenyText,
+		},
+		{
+			name:    "focused window",
+			policy:  `keyboard: {require_focus: "^Editor"}`,
+			windows: []window.Window{editor, terminal},
+			tool:    "keyboard_hotkey",
+			args:    map[string]any{"keys": "ctrl+s"},
+			want:    []automation.Event{{Kind: automation.EventKeyTap, Key: "s", Modifiers: []string{"ctrl"}}},
+		},
+		{
+			name:     "unfocused window",
+			policy:   `keyboard: {require_focus: "^Terminal"}`,
+			windows:  []window.Window{editor, terminal},
+			tool:     "keyboard_key_down",
+			args:     map[string]any{"key": "shift"},
+			wantRule: RuleRequireFocus,
+		},
+		{
+			name:    "releasing keys needs no focus",
+			policy:  `keyboard: {require_focus: "^Terminal"}`,
+			windows: []window.Window{editor, terminal},
+			tool:    "keyboard_release_all",
+			want:    []automation.Event{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := automation.NewFakeBackend()
+			backend.SetWindows(tt.windows...)
+			c := newPolicyClient(t, backend, tt.policy)
+
+			result := callTool(t, c, tt.tool, tt.args)
+			if tt.wantRule == "" {
+				if result.IsError {
+					t.Fatalf("%s failed: %s", tt.tool, resultText(result))
+				}
+			} else {
+				if !result.IsError {
+					t.Fatalf("%s succeeded, want a %s violation", tt.tool, tt.wantRule)
+				}
+				var violation map[string]string
+				if err := json.Unmarshal([]byte(resultText(result)), &violation); err != nil {
+					t.Fatalf("violation %q is not JSON: %v", resultText(result), err)
+				}
+				if violation["error"] != "policy_violation" || violation["tool"] != tt.tool || violation["rule"] != tt.wantRule || violation["message"] == "" {
+					t.Errorf("violation = %v, want rule %s of tool %s", violation, tt.wantRule, tt.tool)
+				}
+				tt.want = []automation.Event{}
+			}
+
+			if got := backend.Events(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("events = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyHidesTools(t *testing.T) {
+	c := newPolicyClient(t, automation.NewFakeBackend(), `tools: {allow: ["mouse_*", "keyboard_*"], deny: ["keyboard_key_*"]}`)
+
+	result, err := c.ListTools(context.Background(), mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListTools() failed: %v", err)
+	}
+	for _, tool := range result.Tools {
+		allowed := strings.HasPrefix(tool.Name, "mouse_") || strings.HasPrefix(tool.Name, "keyboard_")
+		if !allowed || strings.HasPrefix(tool.Name, "keyboard_key_") {
+			t.Errorf("ListTools() includes disabled tool %s", tool.Name)
+		}
+	}
+	if len(result.Tools) == 0 {
+		t.Error("ListTools() hides every tool")
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		wantErr string
+	}{
+		{name: "empty"},
+		{name: "full", policy: "tools: {allow: [\"*\"], deny: [window_close]}\npointer: {regions: [\"0,0,10,10\"], windows: [Editor]}\nkeyboard: {max_text_length: 10, deny_text: [sudo], require_focus: Editor}\n"},
+		{name: "unknown field", policy: "keyboard: {max_length: 10}", wantErr: "field max_length not found"},
+		{name: "bad tool pattern", policy: `tools: {deny: ["mouse_["]}`, wantErr: "invalid tool pattern"},
+		{name: "bad region", policy: `pointer: {regions: ["0,0,10"]}`, wantErr: "invalid pointer region"},
+		{name: "empty region", policy: `pointer: {regions: ["0,0,0,10"]}`, wantErr: "positive size"},
+		{name: "bad window", policy: `pointer: {windows: ["("]}`, wantErr: "invalid pointer window"},
+		{name: "negative length", policy: "keyboard: {max_text_length: -1}", wantErr: "invalid max_text_length"},
+		{name: "bad deny text", policy: `keyboard: {deny_text: ["["]}`, wantErr: "invalid deny_text"},
+		{name: "bad focus", policy: `keyboard: {require_focus: "("}`, wantErr: "invalid require_focus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePolicy([]byte(tt.policy))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ParsePolicy() failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParsePolicy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+)
+
+// addScreenTools adds screen capture tools to the server
+func addScreenTools(s *server.MCPServer, screen *automation.Screen) {
+	// Screen capture tool
+	s.AddTool(
+		mcp.NewTool("screen_capture",
+			mcp.WithDescription("Capture the screen, or a rectangle of it, as an image. Multiply image coordinates by the reported scale and add the region origin to get screen coordinates"),
+			mcp.WithNumber("x", mcp.Description("X coordinate of the region to capture; requires y, width and height. Defaults to the full screen")),
+			mcp.WithNumber("y", mcp.Description("Y coordinate of the region to capture")),
+			mcp.WithNumber("width", mcp.Description("Width of the region to capture")),
+			mcp.WithNumber("height", mcp.Description("Height of the region to capture")),
+			mcp.WithNumber("max_width", mcp.DefaultNumber(0), mcp.Description("Downscale the image to at most this width; 0 for no limit")),
+			mcp.WithNumber("max_height", mcp.DefaultNumber(0), mcp.Description("Downscale the image to at most this height; 0 for no limit")),
+			mcp.WithString("format", mcp.DefaultString("png"), mcp.Enum("png", "jpeg"), mcp.Description("Image encoding")),
+			mcp.WithNumber("quality", mcp.DefaultNumber(automation.DefaultJPEGQuality), mcp.Description("JPEG quality from 1 to 100")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			format, err := automation.ParseImageFormat(req.GetString("format", "png"))
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid format: %v", err)), nil
+			}
+
+			opts := automation.CaptureOptions{
+				MaxWidth:  req.GetInt("max_width", 0),
+				MaxHeight: req.GetInt("max_height", 0),
+				Format:    format,
+				Quality:   req.GetInt("quality", automation.DefaultJPEGQuality),
+			}
+
+			region, err := optionalRegion(req)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid region: %v", err)), nil
+			}
+			opts.Region = region
+
+			shot, err := screen.CaptureEncoded(opts)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to capture screen: %v", err)), nil
+			}
+
+			text := fmt.Sprintf("Captured region %s as a %dx%d %s image (scale %.4g)", shot.Region, shot.Width, shot.Height, shot.Format, shot.Scale)
+			return mcp.NewToolResultImage(text, base64.StdEncoding.EncodeToString(shot.Data), shot.Format.MIMEType()), nil
+		},
+	)
+
+	// Screen info tool
+	s.AddTool(
+		mcp.NewTool("screen_info",
+			mcp.WithDescription("List the displays with their bounds, primary flag and scale factor, and the bounds covering all of them"),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			layout, err := screen.Displays()
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to list displays: %v", err)), nil
+			}
+
+			bounds := layout.Bounds()
+			result := map[string]interface{}{
+				"displays": layout,
+				"bounds": map[string]int{
+					"x":      bounds.Min.X,
+					"y":      bounds.Min.Y,
+					"width":  bounds.Dx(),
+					"height": bounds.Dy(),
+				},
+			}
+			jsonData, err := json.Marshal(result)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal display data: %w", err)
+			}
+			return mcp.NewToolResultText(string(jsonData)), nil
+		},
+	)
+
+	// Wait for pixel tool
+	s.AddTool(
+		mcp.NewTool("screen_wait_for_pixel",
+			mcp.WithDescription("Wait until a pixel, or the average of a region, matches a color (or stops matching it), e.g. to wait for a UI to render before clicking"),
+			mcp.WithNumber("x", mcp.Required(), mcp.Description("X coordinate of the pixel, or of the region's top left corner")),
+			mcp.WithNumber("y", mcp.Required(), mcp.Description("Y coordinate of the pixel, or of the region's top left corner")),
+			mcp.WithNumber("width", mcp.DefaultNumber(1), mcp.Min(1), mcp.Description("Width of the region to average")),
+			mcp.WithNumber("height", mcp.DefaultNumber(1), mcp.Min(1), mcp.Description("Height of the region to average")),
+			mcp.WithString("color", mcp.Required(), mcp.Description("Hex color such as #ff8800")),
+			mcp.WithNumber("tolerance", mcp.DefaultNumber(0), mcp.Min(0), mcp.Max(255), mcp.Description("Largest difference per RGB channel that still matches")),
+			mcp.WithString("until", mcp.DefaultString("match"), mcp.Enum("match", "mismatch"), mcp.Description("Wait until the color matches, or until it stops matching")),
+			mcp.WithNumber("timeout_ms", mcp.DefaultNumber(float64(automation.DefaultWaitTimeout.Milliseconds())), mcp.Description("How long to wait in milliseconds")),
+			mcp.WithNumber("interval_ms", mcp.DefaultNumber(float64(automation.DefaultPollInterval.Milliseconds())), mcp.Description("Time between checks in milliseconds")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			x, err := req.RequireInt("x")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid x coordinate: %v", err)), nil
+			}
+
+			y, err := req.RequireInt("y")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid y coordinate: %v", err)), nil
+			}
+
+			colorName, err := req.RequireString("color")
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid color: %v", err)), nil
+			}
+			color, err := automation.ParseColor(colorName)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid color: %v", err)), nil
+			}
+
+			until := req.GetString("until", "match")
+			if until != "match" && until != "mismatch" {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid until: %q (must be match or mismatch)", until)), nil
+			}
+
+			cond := automation.ColorCondition{
+				Region: automation.Rect{
+					X:      x,
+					Y:      y,
+					Width:  req.GetInt("width", 1),
+					Height: req.GetInt("height", 1),
+				},
+				Color:     color,
+				Tolerance: req.GetInt("tolerance", 0),
+				Mismatch:  until == "mismatch",
+				Timeout:   time.Duration(req.GetInt("timeout_ms", int(automation.DefaultWaitTimeout.Milliseconds()))) * time.Millisecond,
+				Interval:  time.Duration(req.GetInt("interval_ms", int(automation.DefaultPollInterval.Milliseconds()))) * time.Millisecond,
+			}
+
+			got, err := screen.WaitForColor(ctx, cond)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to wait for pixel: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Region %s is %s (until %s %s)", cond.Region, got, until, color)), nil
+		},
+	)
+}
+
+// optionalRegion returns the region given by the x, y, width and height
+// arguments, or nil if none of them is given
+func optionalRegion(req mcp.CallToolRequest) (*automation.Rect, error) {
+	args := req.GetArguments()
+	given := 0
+	for _, name := range []string{"x", "y", "width", "height"} {
+		if _, ok := args[name]; ok {
+			given++
+		}
+	}
+
+	switch given {
+	case 0:
+		return nil, nil
+	case 4:
+		return &automation.Rect{
+			X:      req.GetInt("x", 0),
+			Y:      req.GetInt("y", 0),
+			Width:  req.GetInt("width", 0),
+			Height: req.GetInt("height", 0),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("x, y, width and height must be given together")
+}
@@ -0,0 +1,168 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/ocr"
+)
+
+// textRegionOptions are the region arguments shared by the OCR tools
+var textRegionOptions = []mcp.ToolOption{
+	mcp.WithNumber("x", mcp.Description("X coordinate of the region to read; requires y, width and height. Defaults to the full screen")),
+	mcp.WithNumber("y", mcp.Description("Y coordinate of the region to read")),
+	mcp.WithNumber("width", mcp.Description("Width of the region to read")),
+	mcp.WithNumber("height", mcp.Description("Height of the region to read")),
+}
+
+// findTextOptions are the arguments shared by the tools that search for text
+var findTextOptions = append([]mcp.ToolOption{
+	mcp.WithString("text", mcp.Required(), mcp.Description("Text to look for, e.g. a button label such as Save; case and punctuation are ignored")),
+	mcp.WithBoolean("exact", mcp.DefaultBool(false), mcp.Description("Only match whole words instead of any text containing it")),
+}, textRegionOptions...)
+
+// addTextTools adds OCR based tools to the server
+func addTextTools(s *server.MCPServer, mouse *automation.Mouse, screen *automation.Screen) {
+	// Read text tool
+	s.AddTool(
+		mcp.NewTool("screen_read_text",
+			append([]mcp.ToolOption{
+				mcp.WithDescription("Recognize the text on the screen, or a region of it, and return every word with its bounding box in screen coordinates"),
+			}, textRegionOptions...)...,
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			region, err := optionalRegion(req)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid region: %v", err)), nil
+			}
+
+			words, err := screen.ReadText(region)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to read text: %v", err)), nil
+			}
+
+			return wordsResult(map[string]interface{}{
+				"text":  ocr.Text(words),
+				"words": wordsJSON(words),
+			})
+		},
+	)
+
+	// Find text tool
+	s.AddTool(
+		mcp.NewTool("screen_find_text",
+			append([]mcp.ToolOption{
+				mcp.WithDescription("Find text on the screen with OCR and return every occurrence with its bounding box in screen coordinates, in reading order"),
+			}, findTextOptions...)...,
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			found, errResult := findText(req, screen)
+			if errResult != nil {
+				return errResult, nil
+			}
+
+			return wordsResult(map[string]interface{}{
+				"found":   len(found) > 0,
+				"matches": wordsJSON(found),
+			})
+		},
+	)
+
+	// Click text tool
+	s.AddTool(
+		mcp.NewTool("mouse_click_text",
+			append([]mcp.ToolOption{
+				mcp.WithDescription("Find text on the screen with OCR, e.g. a button label, and click its center"),
+				mcp.WithNumber("occurrence", mcp.DefaultNumber(1), mcp.Min(1), mcp.Description("Which occurrence to click, in reading order")),
+				mcp.WithString("button", mcp.DefaultString("left"), mcp.Enum("left", "right", "middle"), mcp.Description("Mouse button to click")),
+				mcp.WithNumber("count", mcp.DefaultNumber(1), mcp.Min(1), mcp.Max(automation.MaxClickCount), mcp.Description("Number of clicks: 2 for a double click, 3 for a triple click")),
+			}, findTextOptions...)...,
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			button, err := automation.ParseButton(req.GetString("button", "left"))
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid button: %v", err)), nil
+			}
+
+			occurrence := req.GetInt("occurrence", 1)
+			if occurrence < 1 {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid occurrence: %d (must be at least 1)", occurrence)), nil
+			}
+
+			found, errResult := findText(req, screen)
+			if errResult != nil {
+				return errResult, nil
+			}
+			if len(found) < occurrence {
+				return mcp.NewToolResultError(fmt.Sprintf("Text found %d time(s) on screen, wanted occurrence %d", len(found), occurrence)), nil
+			}
+
+			word := found[occurrence-1]
+			x, y := word.Center()
+			if failed := checkPointer(ctx, x, y); failed != nil {
+				return failed, nil
+			}
+
+			count := req.GetInt("count", 1)
+			if err := mouse.ClickButton(x, y, button, count); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to click mouse: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Clicked %q with the %s button %d time(s) at (%d, %d)", word.Text, button, count, x, y)), nil
+		},
+	)
+}
+
+// findText searches the screen for the text of a request. Failures are
+// returned as a tool error result.
+func findText(req mcp.CallToolRequest, screen *automation.Screen) ([]ocr.Word, *mcp.CallToolResult) {
+	text, err := req.RequireString("text")
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("Invalid text: %v", err))
+	}
+
+	region, err := optionalRegion(req)
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("Invalid region: %v", err))
+	}
+
+	found, err := screen.FindText(text, req.GetBool("exact", false), region)
+	if err != nil {
+		return nil, mcp.NewToolResultError(fmt.Sprintf("Failed to find text: %v", err))
+	}
+
+	return found, nil
+}
+
+// wordsResult returns data as a JSON text result
+func wordsResult(data map[string]interface{}) (*mcp.CallToolResult, error) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal text data: %w", err)
+	}
+	return mcp.NewToolResultText(string(jsonData)), nil
+}
+
+// wordsJSON describes words including their centers
+func wordsJSON(words []ocr.Word) []map[string]interface{} {
+	data := make([]map[string]interface{}, len(words))
+	for i, w := range words {
+		x, y := w.Center()
+		data[i] = map[string]interface{}{
+			"text":       w.Text,
+			"x":          w.X,
+			"y":          w.Y,
+			"width":      w.Width,
+			"height":     w.Height,
+			"center_x":   x,
+			"center_y":   y,
+			"confidence": w.Confidence,
+		}
+	}
+	return data
+}
@@ -0,0 +1,199 @@
+// Package mcpserver exposes desktop automation as Model Context Protocol tools
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/window"
+)
+
+// addWindowTools adds window enumeration and focus tools to the server
+func addWindowTools(s *server.MCPServer, windows *automation.Windows) {
+	// Window list tool
+	s.AddTool(
+		mcp.NewTool("window_list",
+			mcp.WithDescription("List the top-level windows with their ID, title, owning process, bounds in screen coordinates and whether they have the focus"),
+			mcp.WithString("title", mcp.Description("Only list windows whose title matches this regular expression")),
+			mcp.WithNumber("pid", mcp.DefaultNumber(0), mcp.Description("Only list windows owned by this process; 0 for any")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			sel, err := window.NewSelector(req.GetString("title", ""), req.GetInt("pid", 0))
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid selector: %v", err)), nil
+			}
+
+			list, err := windows.List(sel)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to list windows: %v", err)), nil
+			}
+
+			if list == nil {
+				list = []window.Window{}
+			}
+			result := map[string]interface{}{
+				"windows": list,
+			}
+			jsonData, err := json.Marshal(result)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal window data: %w", err)
+			}
+			return mcp.NewToolResultText(string(jsonData)), nil
+		},
+	)
+
+	// Window activate tool
+	s.AddTool(
+		mcp.NewTool("window_activate",
+			mcp.WithDescription("Bring the first window matching the title and/or process to the front and give it the keyboard focus. At least one of title and pid is required"),
+			mcp.WithString("title", mcp.Description("Regular expression matching the window title")),
+			mcp.WithNumber("pid", mcp.DefaultNumber(0), mcp.Description("ID of the process owning the window; 0 for any")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			sel, err := requiredSelector(req)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid selector: %v", err)), nil
+			}
+
+			w, err := windows.Activate(sel)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to activate window: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Activated window %s", w)), nil
+		},
+	)
+
+	// Active window tool
+	s.AddTool(
+		mcp.NewTool("window_get_active",
+			mcp.WithDescription("Get the window that currently has the keyboard focus"),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			active, err := windows.Active()
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to get active window: %v", err)), nil
+			}
+
+			jsonData, err := json.Marshal(active)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal window data: %w", err)
+			}
+			return mcp.NewToolResultText(string(jsonData)), nil
+		},
+	)
+
+	// Window bounds tool
+	s.AddTool(
+		mcp.NewTool("window_set_bounds",
+			mcp.WithDescription("Move and/or resize the first window matching the title and/or process. Give x and y to move it, width and height to resize it, or all four"),
+			mcp.WithString("title", mcp.Description("Regular expression matching the window title")),
+			mcp.WithNumber("pid", mcp.DefaultNumber(0), mcp.Description("ID of the process owning the window; 0 for any")),
+			mcp.WithNumber("x", mcp.Description("X coordinate of the new top left corner; requires y")),
+			mcp.WithNumber("y", mcp.Description("Y coordinate of the new top left corner; requires x")),
+			mcp.WithNumber("width", mcp.Min(1), mcp.Description("New width in pixels; requires height")),
+			mcp.WithNumber("height", mcp.Min(1), mcp.Description("New height in pixels; requires width")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			sel, err := requiredSelector(req)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid selector: %v", err)), nil
+			}
+
+			args := req.GetArguments()
+			_, hasX := args["x"]
+			_, hasY := args["y"]
+			_, hasWidth := args["width"]
+			_, hasHeight := args["height"]
+			if hasX != hasY {
+				return mcp.NewToolResultError("Invalid bounds: x and y must be given together"), nil
+			}
+			if hasWidth != hasHeight {
+				return mcp.NewToolResultError("Invalid bounds: width and height must be given together"), nil
+			}
+
+			var w window.Window
+			switch {
+			case hasX && hasWidth:
+				w, err = windows.SetBounds(sel, automation.Rect{X: req.GetInt("x", 0), Y: req.GetInt("y", 0), Width: req.GetInt("width", 0), Height: req.GetInt("height", 0)})
+			case hasX:
+				w, err = windows.Move(sel, req.GetInt("x", 0), req.GetInt("y", 0))
+			case hasWidth:
+				w, err = windows.Resize(sel, req.GetInt("width", 0), req.GetInt("height", 0))
+			default:
+				return mcp.NewToolResultError("Invalid bounds: give x and y, width and height, or all four"), nil
+			}
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to set window bounds: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Window is now %s", w)), nil
+		},
+	)
+
+	// Window state tool
+	s.AddTool(
+		mcp.NewTool("window_set_state",
+			mcp.WithDescription("Minimize, maximize or restore the first window matching the title and/or process"),
+			mcp.WithString("title", mcp.Description("Regular expression matching the window title")),
+			mcp.WithNumber("pid", mcp.DefaultNumber(0), mcp.Description("ID of the process owning the window; 0 for any")),
+			mcp.WithString("state", mcp.Required(), mcp.Enum(string(window.StateNormal), string(window.StateMinimized), string(window.StateMaximized)), mcp.Description("New state; normal restores a minimized or maximized window")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			sel, err := requiredSelector(req)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid selector: %v", err)), nil
+			}
+
+			state, err := window.ParseState(req.GetString("state", ""))
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid state: %v", err)), nil
+			}
+
+			w, err := windows.SetState(sel, state)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to set window state: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Window %#x is now %s", w.ID, state)), nil
+		},
+	)
+
+	// Window close tool
+	s.AddTool(
+		mcp.NewTool("window_close",
+			mcp.WithDescription("Ask the first window matching the title and/or process to close, as if its close button was clicked. The application may ask to save changes first"),
+			mcp.WithString("title", mcp.Description("Regular expression matching the window title")),
+			mcp.WithNumber("pid", mcp.DefaultNumber(0), mcp.Description("ID of the process owning the window; 0 for any")),
+		),
+		func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			sel, err := requiredSelector(req)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Invalid selector: %v", err)), nil
+			}
+
+			w, err := windows.Close(sel)
+			if err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Failed to close window: %v", err)), nil
+			}
+
+			return mcp.NewToolResultText(fmt.Sprintf("Asked window %s to close", w)), nil
+		},
+	)
+}
+
+// requiredSelector returns the window selector given by the title and pid
+// arguments, at least one of which is required
+func requiredSelector(req mcp.CallToolRequest) (window.Selector, error) {
+	title := req.GetString("title", "")
+	pid := req.GetInt("pid", 0)
+	if title == "" && pid == 0 {
+		return window.Selector{}, fmt.Errorf("either title or pid is required")
+	}
+
+	return window.NewSelector(title, pid)
+}
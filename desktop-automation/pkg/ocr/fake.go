@@ -0,0 +1,44 @@
+// Package ocr recognizes words and their positions in screen captures
+package ocr
+
+import (
+	"image"
+	"slices"
+	"sync"
+)
+
+// FakeEngine is an Engine that "recognizes" a fixed set of words instead of
+// running OCR. Only the words lying wholly inside the image are returned, so
+// that captures of a region see the words of that region. It is safe for
+// concurrent use.
+type FakeEngine struct {
+	mu    sync.Mutex
+	words []Word
+}
+
+// NewFakeEngine creates a fake engine showing words, given in screen coordinates
+func NewFakeEngine(words ...Word) *FakeEngine {
+	return &FakeEngine{words: slices.Clone(words)}
+}
+
+// SetWords replaces the words on the simulated screen
+func (e *FakeEngine) SetWords(words ...Word) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.words = slices.Clone(words)
+}
+
+// Recognize returns the words inside img.Bounds()
+func (e *FakeEngine) Recognize(img image.Image) ([]Word, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var words []Word
+	for _, w := range e.words {
+		if w.Bounds().In(img.Bounds()) {
+			words = append(words, w)
+		}
+	}
+	return words, nil
+}
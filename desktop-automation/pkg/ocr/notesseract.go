@@ -0,0 +1,24 @@
+//go:build !ocr
+
+// Package ocr recognizes words and their positions in screen captures
+package ocr
+
+import "image"
+
+// TesseractEngine stands in for the tesseract engine, which needs the ocr
+// build tag and libtesseract. Recognize fails with ErrUnsupported; use
+// FakeEngine to run without tesseract.
+type TesseractEngine struct {
+	// Languages are the tesseract languages to recognize; English if empty
+	Languages []string
+}
+
+// NewTesseractEngine creates an engine that reports ErrUnsupported
+func NewTesseractEngine() *TesseractEngine {
+	return &TesseractEngine{}
+}
+
+// Recognize returns ErrUnsupported
+func (e *TesseractEngine) Recognize(img image.Image) ([]Word, error) {
+	return nil, ErrUnsupported
+}
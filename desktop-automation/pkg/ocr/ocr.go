@@ -0,0 +1,98 @@
+// Package ocr recognizes words and their positions in screen captures
+package ocr
+
+import (
+	"errors"
+	"image"
+	"strings"
+	"unicode"
+)
+
+// ErrUnsupported is returned by the tesseract engine in builds without the ocr tag
+var ErrUnsupported = errors.New("OCR requires tesseract (build with -tags ocr)")
+
+// Engine recognizes the words in an image
+type Engine interface {
+	// Recognize returns the words of img in reading order. Their positions
+	// are in the coordinate space of img.Bounds().
+	Recognize(img image.Image) ([]Word, error)
+}
+
+// Word is a recognized word, or a phrase of consecutive words
+type Word struct {
+	// Text is the recognized text
+	Text string
+	// X and Y are the top left corner of the bounding box
+	X int
+	Y int
+	// Width and Height are the size of the bounding box
+	Width  int
+	Height int
+	// Confidence is how sure the engine is of the text, from 0 to 1
+	Confidence float64
+}
+
+// Bounds returns the bounding box of the word
+func (w Word) Bounds() image.Rectangle {
+	return image.Rect(w.X, w.Y, w.X+w.Width, w.Y+w.Height)
+}
+
+// Center returns the center point of the bounding box
+func (w Word) Center() (int, int) {
+	return w.X + w.Width/2, w.Y + w.Height/2
+}
+
+// Text joins the text of words with spaces
+func Text(words []Word) string {
+	texts := make([]string, len(words))
+	for i, w := range words {
+		texts[i] = w.Text
+	}
+	return strings.Join(texts, " ")
+}
+
+// Find returns every run of consecutive words that reads as query, ignoring
+// case and surrounding punctuation. Unless exact is set, runs that merely
+// contain query match as well, so "save" finds "Save" and "Save As...".
+// Each run is merged into a single Word covering all of its words.
+func Find(words []Word, query string, exact bool) []Word {
+	want := normalize(query)
+	if want == "" {
+		return nil
+	}
+	n := len(strings.Fields(want))
+
+	var found []Word
+	for i := 0; i+n <= len(words); i++ {
+		run := words[i : i+n]
+		got := normalize(Text(run))
+		if got == want || (!exact && strings.Contains(got, want)) {
+			found = append(found, merge(run))
+		}
+	}
+	return found
+}
+
+// normalize lowercases s, trims punctuation from every word and collapses spaces
+func normalize(s string) string {
+	fields := strings.Fields(strings.ToLower(s))
+	words := fields[:0]
+	for _, f := range fields {
+		if f = strings.TrimFunc(f, unicode.IsPunct); f != "" {
+			words = append(words, f)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// merge combines words into one covering all of their bounding boxes, with
+// the confidence of the least certain word
+func merge(words []Word) Word {
+	r := words[0].Bounds()
+	confidence := words[0].Confidence
+	for _, w := range words[1:] {
+		r = r.Union(w.Bounds())
+		confidence = min(confidence, w.Confidence)
+	}
+	return Word{Text: Text(words), X: r.Min.X, Y: r.Min.Y, Width: r.Dx(), Height: r.Dy(), Confidence: confidence}
+}
@@ -0,0 +1,59 @@
+package ocr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	words := []Word{
+		{Text: "Save", X: 0, Y: 0, Width: 30, Height: 10, Confidence: 0.9},
+		{Text: "As...", X: 34, Y: 0, Width: 30, Height: 10, Confidence: 0.7},
+		{Text: "Autosave", X: 0, Y: 20, Width: 60, Height: 10, Confidence: 0.8},
+		{Text: "OK", X: 100, Y: 100, Width: 20, Height: 12, Confidence: 1},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		exact bool
+		want  []Word
+	}{
+		{
+			name:  "contained word",
+			query: "save",
+			want: []Word{
+				{Text: "Save", X: 0, Y: 0, Width: 30, Height: 10, Confidence: 0.9},
+				{Text: "Autosave", X: 0, Y: 20, Width: 60, Height: 10, Confidence: 0.8},
+			},
+		},
+		{
+			name:  "exact word",
+			query: "SAVE",
+			exact: true,
+			want:  []Word{{Text: "Save", X: 0, Y: 0, Width: 30, Height: 10, Confidence: 0.9}},
+		},
+		{
+			name:  "phrase ignoring punctuation",
+			query: "Save as",
+			exact: true,
+			want:  []Word{{Text: "Save As...", X: 0, Y: 0, Width: 64, Height: 10, Confidence: 0.7}},
+		},
+		{
+			name:  "missing",
+			query: "Cancel",
+		},
+		{
+			name:  "empty query",
+			query: " ... ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Find(words, tt.query, tt.exact); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find(%q, %v) = %+v, want %+v", tt.query, tt.exact, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+//go:build ocr
+
+// Package ocr recognizes words and their positions in screen captures
+package ocr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+
+	"github.com/otiai10/gosseract"
+)
+
+// TesseractEngine is the default Engine, running tesseract through gosseract
+type TesseractEngine struct {
+	// Languages are the tesseract languages to recognize; English if empty
+	Languages []string
+}
+
+// NewTesseractEngine creates an engine recognizing English text
+func NewTesseractEngine() *TesseractEngine {
+	return &TesseractEngine{}
+}
+
+// Recognize runs tesseract on img and returns its words
+func (e *TesseractEngine) Recognize(img image.Image) ([]Word, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image for OCR: %w", err)
+	}
+
+	client := gosseract.NewClient()
+	defer client.Close()
+
+	if len(e.Languages) > 0 {
+		if err := client.SetLanguage(e.Languages...); err != nil {
+			return nil, fmt.Errorf("failed to set OCR languages: %w", err)
+		}
+	}
+	if err := client.SetImageFromBytes(buf.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to load image for OCR: %w", err)
+	}
+
+	boxes, err := client.GetBoundingBoxes(gosseract.RIL_WORD)
+	if err != nil {
+		return nil, fmt.Errorf("OCR failed: %w", err)
+	}
+
+	// Tesseract sees the encoded image, whose origin is always (0, 0)
+	origin := img.Bounds().Min
+	words := make([]Word, 0, len(boxes))
+	for _, b := range boxes {
+		text := strings.TrimSpace(b.Word)
+		if text == "" {
+			continue
+		}
+		r := b.Box.Add(origin)
+		words = append(words, Word{
+			Text:       text,
+			X:          r.Min.X,
+			Y:          r.Min.Y,
+			Width:      r.Dx(),
+			Height:     r.Dy(),
+			Confidence: b.Confidence / 100,
+		})
+	}
+
+	return words, nil
+}
@@ -0,0 +1,294 @@
+// Package script runs declarative automation flows: a list of named steps
+// such as move, click, type, hotkey, wait and screenshot, written as YAML or
+// JSON
+package script
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/input"
+)
+
+// Defaults of RecordOptions
+const (
+	DefaultDragThreshold       = 5
+	DefaultDoubleClickInterval = 500 * time.Millisecond
+	DefaultMinPause            = 100 * time.Millisecond
+)
+
+// RecordOptions controls how Coalesce turns input events into steps
+type RecordOptions struct {
+	// DragThreshold is how far in pixels the cursor may move while a button
+	// is held for the press to still count as a click;
+	// DefaultDragThreshold if zero
+	DragThreshold int
+	// DoubleClickInterval is the longest time between two clicks at the
+	// same spot that makes them a double click; DefaultDoubleClickInterval
+	// if zero
+	DoubleClickInterval time.Duration
+	// MinPause is the shortest idle time between two steps that is kept as
+	// a pause after the first one; DefaultMinPause if zero
+	MinPause time.Duration
+	// NoPauses drops all pauses, so that the script replays as fast as
+	// possible
+	NoPauses bool
+}
+
+// modifierOrder is the order of modifiers in recorded chords
+var modifierOrder = []string{automation.ModCtrl, automation.ModShift, automation.ModAlt, automation.ModCmd}
+
+// Capture reads events from source until the stop chord is pressed, the
+// source ends or ctx is done, and returns them. The stop chord itself is not
+// part of the result.
+func Capture(ctx context.Context, source input.Source, stop automation.Chord) ([]input.Event, error) {
+	var events []input.Event
+	held := make(map[string]bool)
+	for {
+		e, err := source.Next(ctx)
+		if errors.Is(err, io.EOF) || ctx.Err() != nil {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+
+		switch {
+		case e.Kind == input.KindKeyDown && isModifier(e.Key):
+			held[e.Key] = true
+		case e.Kind == input.KindKeyUp && isModifier(e.Key):
+			delete(held, e.Key)
+		case e.Kind == input.KindKeyDown && e.Key == stop.Key && sameModifiers(held, stop.Modifiers):
+			// Drop the presses of the stop chord's modifiers
+			for len(events) > 0 && events[len(events)-1].Kind == input.KindKeyDown && isModifier(events[len(events)-1].Key) {
+				events = events[:len(events)-1]
+			}
+			return events, nil
+		}
+
+		events = append(events, e)
+	}
+}
+
+// isModifier reports whether key is a modifier key
+func isModifier(key string) bool {
+	return slices.Contains(modifierOrder, key)
+}
+
+// sameModifiers reports whether exactly the given modifiers are held
+func sameModifiers(held map[string]bool, modifiers []string) bool {
+	if len(held) != len(modifiers) {
+		return false
+	}
+	for _, mod := range modifiers {
+		if !held[mod] {
+			return false
+		}
+	}
+	return true
+}
+
+// Coalesce turns recorded input events into high-level steps: clicks and
+// double clicks, drags, scrolls, typed text and hotkeys. Plain moves and
+// modifiers pressed on their own produce no steps. Idle time between steps
+// becomes the pause of the earlier step.
+func Coalesce(events []input.Event, opts RecordOptions) []Step {
+	c := &coalescer{opts: opts, held: make(map[string]bool), pressed: make(map[string]input.Event), lastClick: -1, lastScroll: -1}
+	if c.opts.DragThreshold == 0 {
+		c.opts.DragThreshold = DefaultDragThreshold
+	}
+	if c.opts.DoubleClickInterval == 0 {
+		c.opts.DoubleClickInterval = DefaultDoubleClickInterval
+	}
+	if c.opts.MinPause == 0 {
+		c.opts.MinPause = DefaultMinPause
+	}
+
+	for _, e := range events {
+		c.add(e)
+	}
+	c.flushText()
+
+	return c.steps
+}
+
+// coalescer holds the state of Coalesce
+type coalescer struct {
+	opts  RecordOptions
+	steps []Step
+	// last is when the latest step ended
+	last time.Time
+
+	// held are the modifiers that are down
+	held map[string]bool
+	// text is typed text that has not become a step yet
+	text                   []rune
+	textStart, textEnd     time.Time
+	pressed                map[string]input.Event
+	lastClick              int
+	lastClickAt            time.Time
+	lastClickX, lastClickY int
+	lastScroll             int
+}
+
+// add feeds the next event to the coalescer
+func (c *coalescer) add(e input.Event) {
+	switch e.Kind {
+	case input.KindKeyDown:
+		c.keyDown(e)
+	case input.KindKeyUp:
+		if isModifier(e.Key) {
+			delete(c.held, e.Key)
+		}
+	case input.KindButtonDown:
+		c.flushText()
+		c.pressed[e.Button] = e
+	case input.KindButtonUp:
+		c.buttonUp(e)
+	case input.KindScroll:
+		c.scroll(e)
+	}
+}
+
+// scroll turns a wheel notch into a scroll step, or adds it to the previous
+// step if that scrolled the same way at the same place without a pause
+func (c *coalescer) scroll(e input.Event) {
+	c.flushText()
+
+	if c.lastScroll >= 0 && c.lastScroll == len(c.steps)-1 {
+		prev := &c.steps[c.lastScroll]
+		if prev.Direction == e.Direction &&
+			abs(e.X-*prev.X) <= c.opts.DragThreshold && abs(e.Y-*prev.Y) <= c.opts.DragThreshold &&
+			e.Time.Sub(c.last) < c.opts.MinPause {
+			prev.Amount++
+			c.last = e.Time
+			return
+		}
+	}
+
+	c.emit(Step{Action: ActionScroll, Direction: e.Direction, Amount: 1, X: intPtr(e.X), Y: intPtr(e.Y)}, e.Time, e.Time)
+	c.lastScroll = len(c.steps) - 1
+}
+
+// keyDown turns a key press into typed text or a hotkey
+func (c *coalescer) keyDown(e input.Event) {
+	if isModifier(e.Key) {
+		c.held[e.Key] = true
+		return
+	}
+
+	command := c.held[automation.ModCtrl] || c.held[automation.ModAlt] || c.held[automation.ModCmd]
+	switch {
+	case !command && e.Text != "":
+		if len(c.text) == 0 {
+			c.textStart = e.Time
+		}
+		c.text = append(c.text, []rune(e.Text)...)
+		c.textEnd = e.Time
+		return
+	case !command && e.Key == "backspace" && len(c.text) > 0:
+		c.text = c.text[:len(c.text)-1]
+		c.textEnd = e.Time
+		return
+	}
+
+	if _, err := automation.LookupKey(e.Key); err != nil {
+		// Keys without a chord name cannot be replayed as a hotkey
+		return
+	}
+
+	c.flushText()
+	var parts []string
+	for _, mod := range modifierOrder {
+		if c.held[mod] {
+			parts = append(parts, mod)
+		}
+	}
+	c.emit(Step{Action: ActionHotkey, Keys: strings.Join(append(parts, e.Key), "+")}, e.Time, e.Time)
+}
+
+// buttonUp turns a button release into a click, a double click or a drag
+func (c *coalescer) buttonUp(e input.Event) {
+	down, ok := c.pressed[e.Button]
+	if !ok {
+		return
+	}
+	delete(c.pressed, e.Button)
+
+	button := e.Button
+	if button == string(automation.ButtonLeft) {
+		button = ""
+	}
+
+	if abs(e.X-down.X) > c.opts.DragThreshold || abs(e.Y-down.Y) > c.opts.DragThreshold {
+		step := Step{Action: ActionDrag, X: intPtr(down.X), Y: intPtr(down.Y), ToX: intPtr(e.X), ToY: intPtr(e.Y), Button: button}
+		if d := e.Time.Sub(down.Time).Round(10 * time.Millisecond); d > 0 {
+			step.Smooth, step.Duration = true, d
+		}
+		c.emit(step, down.Time, e.Time)
+		return
+	}
+
+	if c.lastClick == len(c.steps)-1 && c.lastClick >= 0 {
+		prev := &c.steps[c.lastClick]
+		if prev.Button == button && !prev.Double &&
+			down.Time.Sub(c.lastClickAt) <= c.opts.DoubleClickInterval &&
+			abs(down.X-c.lastClickX) <= c.opts.DragThreshold && abs(down.Y-c.lastClickY) <= c.opts.DragThreshold {
+			prev.Double = true
+			c.last = e.Time
+			return
+		}
+	}
+
+	c.emit(Step{Action: ActionClick, X: intPtr(down.X), Y: intPtr(down.Y), Button: button}, down.Time, e.Time)
+	c.lastClick = len(c.steps) - 1
+	c.lastClickAt = e.Time
+	c.lastClickX, c.lastClickY = down.X, down.Y
+}
+
+// flushText turns pending typed text into a step
+func (c *coalescer) flushText() {
+	if len(c.text) == 0 {
+		return
+	}
+
+	// The typing time between the first and last keystroke lets the
+	// replay type at the recorded pace
+	step := Step{Action: ActionType, Text: string(c.text)}
+	if len(c.text) > 1 {
+		step.Duration = c.textEnd.Sub(c.textStart).Round(10 * time.Millisecond)
+	}
+	c.text = nil
+	c.emit(step, c.textStart, c.textEnd)
+}
+
+// emit appends a step that ran from start to end, keeping the idle time
+// before it as the pause of the previous step
+func (c *coalescer) emit(st Step, start, end time.Time) {
+	if n := len(c.steps); n > 0 && !c.opts.NoPauses {
+		if idle := start.Sub(c.last); idle >= c.opts.MinPause {
+			c.steps[n-1].Pause = idle.Round(10 * time.Millisecond)
+		}
+	}
+
+	c.steps = append(c.steps, st)
+	c.last = end
+}
+
+// intPtr returns a pointer to a copy of v
+func intPtr(v int) *int {
+	return &v
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
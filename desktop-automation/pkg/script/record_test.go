@@ -0,0 +1,221 @@
+package script
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/input"
+)
+
+// events builds input events spaced by the given offsets from a fixed start
+type events struct {
+	start time.Time
+	list  []input.Event
+}
+
+func (b *events) at(ms int, e input.Event) *events {
+	e.Time = b.start.Add(time.Duration(ms) * time.Millisecond)
+	b.list = append(b.list, e)
+	return b
+}
+
+func (b *events) key(ms int, key, text string) *events {
+	b.at(ms, input.Event{Kind: input.KindKeyDown, Key: key, Text: text})
+	return b.at(ms+20, input.Event{Kind: input.KindKeyUp, Key: key})
+}
+
+func (b *events) button(ms int, button string, x, y, toX, toY int) *events {
+	b.at(ms, input.Event{Kind: input.KindButtonDown, Button: button, X: x, Y: y})
+	return b.at(ms+50, input.Event{Kind: input.KindButtonUp, Button: button, X: toX, Y: toY})
+}
+
+func (b *events) scroll(ms int, direction string, x, y int) *events {
+	return b.at(ms, input.Event{Kind: input.KindScroll, Direction: direction, X: x, Y: y})
+}
+
+func newEvents() *events {
+	return &events{start: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestCoalesce(t *testing.T) {
+	p := func(v int) *int { return &v }
+
+	tests := []struct {
+		name   string
+		events *events
+		opts   RecordOptions
+		want   []Step
+	}{
+		{
+			name:   "click",
+			events: newEvents().at(0, input.Event{Kind: input.KindMove, X: 3, Y: 4}).button(10, "left", 10, 20, 12, 21),
+			want:   []Step{{Action: ActionClick, X: p(10), Y: p(20)}},
+		},
+		{
+			name:   "double click",
+			events: newEvents().button(0, "left", 10, 20, 10, 20).button(200, "left", 11, 20, 11, 20),
+			want:   []Step{{Action: ActionClick, X: p(10), Y: p(20), Double: true}},
+		},
+		{
+			name:   "slow second click",
+			events: newEvents().button(0, "right", 10, 20, 10, 20).button(900, "right", 10, 20, 10, 20),
+			want: []Step{
+				{Action: ActionClick, X: p(10), Y: p(20), Button: "right", Pause: 850 * time.Millisecond},
+				{Action: ActionClick, X: p(10), Y: p(20), Button: "right"},
+			},
+		},
+		{
+			name:   "drag",
+			events: newEvents().button(0, "left", 10, 20, 200, 20),
+			want:   []Step{{Action: ActionDrag, X: p(10), Y: p(20), ToX: p(200), ToY: p(20), Smooth: true, Duration: 50 * time.Millisecond}},
+		},
+		{
+			name:   "scroll",
+			events: newEvents().scroll(0, "down", 50, 60).scroll(30, "down", 51, 60).scroll(60, "down", 50, 61),
+			want:   []Step{{Action: ActionScroll, Direction: "down", Amount: 3, X: p(50), Y: p(60)}},
+		},
+		{
+			name: "scroll changing direction, place or after a pause",
+			events: newEvents().
+				scroll(0, "down", 50, 60).
+				scroll(30, "up", 50, 60).
+				scroll(60, "up", 300, 60).
+				scroll(1060, "up", 300, 60),
+			want: []Step{
+				{Action: ActionScroll, Direction: "down", Amount: 1, X: p(50), Y: p(60)},
+				{Action: ActionScroll, Direction: "up", Amount: 1, X: p(50), Y: p(60)},
+				{Action: ActionScroll, Direction: "up", Amount: 1, X: p(300), Y: p(60), Pause: time.Second},
+				{Action: ActionScroll, Direction: "up", Amount: 1, X: p(300), Y: p(60)},
+			},
+		},
+		{
+			name: "typed text",
+			events: newEvents().
+				key(0, "h", "h").
+				at(50, input.Event{Kind: input.KindKeyDown, Key: "shift"}).
+				key(60, "i", "I").
+				at(90, input.Event{Kind: input.KindKeyUp, Key: "shift"}).
+				key(100, "x", "x").
+				key(150, "backspace", "").
+				key(200, "1", "!"),
+			want: []Step{{Action: ActionType, Text: "hI!", Duration: 200 * time.Millisecond}},
+		},
+		{
+			name: "hotkeys end text",
+			events: newEvents().
+				key(0, "a", "a").
+				key(50, "enter", "").
+				at(100, input.Event{Kind: input.KindKeyDown, Key: "ctrl"}).
+				at(110, input.Event{Kind: input.KindKeyDown, Key: "shift"}).
+				key(120, "t", "T").
+				at(150, input.Event{Kind: input.KindKeyUp, Key: "shift"}).
+				at(160, input.Event{Kind: input.KindKeyUp, Key: "ctrl"}).
+				at(170, input.Event{Kind: input.KindKeyDown, Key: "shift"}).
+				at(180, input.Event{Kind: input.KindKeyUp, Key: "shift"}),
+			want: []Step{
+				{Action: ActionType, Text: "a"},
+				{Action: ActionHotkey, Keys: "enter"},
+				{Action: ActionHotkey, Keys: "ctrl+shift+t"},
+			},
+		},
+		{
+			name:   "pauses",
+			events: newEvents().key(0, "a", "a").button(1000, "left", 1, 2, 1, 2).key(1200, "b", "b"),
+			want: []Step{
+				{Action: ActionType, Text: "a", Pause: time.Second},
+				{Action: ActionClick, X: p(1), Y: p(2), Pause: 150 * time.Millisecond},
+				{Action: ActionType, Text: "b"},
+			},
+		},
+		{
+			name:   "no pauses",
+			events: newEvents().key(0, "a", "a").button(1000, "left", 1, 2, 1, 2),
+			opts:   RecordOptions{NoPauses: true},
+			want: []Step{
+				{Action: ActionType, Text: "a"},
+				{Action: ActionClick, X: p(1), Y: p(2)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Coalesce(tt.events.list, tt.opts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Coalesce() = %+v, want %+v", got, tt.want)
+			}
+			if err := (&Script{Steps: got}).Validate(); err != nil {
+				t.Errorf("recorded steps are invalid: %v", err)
+			}
+		})
+	}
+}
+
+func TestCapture(t *testing.T) {
+	stop, err := automation.ParseChord("ctrl+alt+s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorded := newEvents().
+		key(0, "a", "a").
+		at(100, input.Event{Kind: input.KindKeyDown, Key: "ctrl"}).
+		key(110, "s", "s").
+		at(150, input.Event{Kind: input.KindKeyDown, Key: "alt"}).
+		at(160, input.Event{Kind: input.KindKeyDown, Key: "s", Text: "s"}).
+		key(300, "b", "b")
+
+	events, err := Capture(context.Background(), input.NewReplay(recorded.list...), stop)
+	if err != nil {
+		t.Fatalf("Capture() failed: %v", err)
+	}
+	if want := recorded.list[:5]; !reflect.DeepEqual(events, want) {
+		t.Errorf("Capture() = %v, want the events before the stop chord %v", events, want)
+	}
+
+	steps := Coalesce(events, RecordOptions{})
+	want := []Step{{Action: ActionType, Text: "a", Pause: 110 * time.Millisecond}, {Action: ActionHotkey, Keys: "ctrl+s"}}
+	if !reflect.DeepEqual(steps, want) {
+		t.Errorf("Coalesce() = %+v, want %+v", steps, want)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	x, y := 10, 0
+	s := &Script{
+		Name: "recorded",
+		Steps: []Step{
+			{Action: ActionClick, X: &x, Y: &y, Double: true, Pause: 1500 * time.Millisecond},
+			{Action: ActionType, Text: "hello"},
+		},
+	}
+
+	data, err := s.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+	want := `name: recorded
+steps:
+  - action: click
+    x: 10
+    "y": 0
+    double: true
+    pause: 1.5s
+  - action: type
+    text: hello
+`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse(Marshal()) failed: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, s) {
+		t.Errorf("Parse(Marshal()) = %+v, want %+v", parsed, s)
+	}
+}
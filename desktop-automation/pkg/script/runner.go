@@ -0,0 +1,311 @@
+// Package script runs declarative automation flows: a list of named steps
+// such as move, click, type, hotkey, wait and screenshot, written as YAML or
+// JSON
+package script
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+)
+
+// DefaultSmoothDuration is the length of a smooth move, drag or scroll whose
+// step does not set a duration
+const DefaultSmoothDuration = time.Second
+
+// StepResult is the outcome of a step that ran
+type StepResult struct {
+	// Index is the 0-based position of the step
+	Index int
+	// Step is the step that ran
+	Step Step
+	// Detail describes what the step did
+	Detail string
+	// Elapsed is how long the action took, not counting the pause after it
+	Elapsed time.Duration
+	// Err is why the step failed, or nil
+	Err error
+}
+
+// Report is the outcome of running a script
+type Report struct {
+	// Script is the name of the script
+	Script string
+	// Total is the number of steps in the script
+	Total int
+	// Results are the steps that ran, in order. Only the last one may have
+	// failed.
+	Results []StepResult
+	// Elapsed is how long the whole run took
+	Elapsed time.Duration
+}
+
+// Failed returns the step that stopped the run, or nil if every step passed
+func (r *Report) Failed() *StepResult {
+	if n := len(r.Results); n > 0 && r.Results[n-1].Err != nil {
+		return &r.Results[n-1]
+	}
+	return nil
+}
+
+// Runner runs scripts against a backend
+type Runner struct {
+	mouse    *automation.Mouse
+	keyboard *automation.Keyboard
+	screen   *automation.Screen
+	random   func() float64
+
+	// Timing controls the pace of the replay
+	Timing Timing
+	// Progress, if set, is called after each step that ran
+	Progress func(StepResult)
+}
+
+// NewRunner creates a runner for the real desktop
+func NewRunner() *Runner {
+	return NewRunnerWithBackend(automation.NewRobotgoBackend())
+}
+
+// NewRunnerWithBackend creates a runner using the given backend
+func NewRunnerWithBackend(backend automation.Backend) *Runner {
+	return &Runner{
+		mouse:    automation.NewMouseWithBackend(backend),
+		keyboard: automation.NewKeyboardWithBackend(backend),
+		screen:   automation.NewScreenWithBackend(backend),
+		random:   rand.Float64,
+	}
+}
+
+// Run validates the script and then runs its steps in order, stopping at the
+// first failure or when ctx is done. The script must already be expanded. The
+// report covers the steps that ran; a failure is also returned as a
+// *StepError.
+func (r *Runner) Run(ctx context.Context, s *Script) (*Report, error) {
+	report := &Report{Script: s.Name, Total: len(s.Steps)}
+	if err := r.Timing.Validate(); err != nil {
+		return report, err
+	}
+	if err := s.Validate(); err != nil {
+		return report, err
+	}
+
+	start := time.Now()
+	defer func() { report.Elapsed = time.Since(start) }()
+
+	for i, st := range s.Steps {
+		if err := ctx.Err(); err != nil {
+			return report, &StepError{Index: i, Step: st, Err: err}
+		}
+
+		stepStart := time.Now()
+		detail, err := r.runStep(ctx, st)
+		result := StepResult{Index: i, Step: st, Detail: detail, Elapsed: time.Since(stepStart), Err: err}
+		report.Results = append(report.Results, result)
+		if r.Progress != nil {
+			r.Progress(result)
+		}
+		if err != nil {
+			return report, &StepError{Index: i, Step: st, Err: err}
+		}
+
+		if st.Pause > 0 && !r.Timing.NoDelays {
+			if err := sleep(ctx, r.Timing.scale(st.Pause, r.random)); err != nil {
+				return report, &StepError{Index: i, Step: st, Err: err}
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// runStep performs a validated step and describes what it did
+func (r *Runner) runStep(ctx context.Context, st Step) (string, error) {
+	frame := st.mustFrame()
+
+	switch st.Action {
+	case ActionMove:
+		sx, sy, err := r.mouse.Resolve(frame, *st.X, *st.Y)
+		if err != nil {
+			return "", err
+		}
+		if st.Smooth {
+			err = r.mouse.SmoothMove(sx, sy, r.smoothDuration(st))
+		} else {
+			err = r.mouse.Move(sx, sy)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to move mouse: %w", err)
+		}
+		return fmt.Sprintf("moved to %s", frame.FormatPoint(*st.X, *st.Y, sx, sy)), nil
+
+	case ActionClick:
+		button, _ := automation.ParseButton(st.Button)
+		count := 1
+		if st.Double {
+			count = 2
+		}
+		sx, sy, err := r.mouse.Resolve(frame, *st.X, *st.Y)
+		if err != nil {
+			return "", err
+		}
+		if err := r.mouse.ClickButton(sx, sy, button, count); err != nil {
+			return "", fmt.Errorf("failed to click: %w", err)
+		}
+		return fmt.Sprintf("clicked %s button at %s", button, frame.FormatPoint(*st.X, *st.Y, sx, sy)), nil
+
+	case ActionDrag:
+		fromX, fromY, err := r.mouse.Resolve(frame, *st.X, *st.Y)
+		if err != nil {
+			return "", err
+		}
+		toX, toY, err := r.mouse.Resolve(frame, *st.ToX, *st.ToY)
+		if err != nil {
+			return "", err
+		}
+		opts := automation.DragOptions{Button: automation.Button(st.Button), Smooth: st.Smooth, Duration: r.smoothDuration(st)}
+		if err := r.mouse.Drag(ctx, fromX, fromY, toX, toY, opts); err != nil {
+			return "", fmt.Errorf("failed to drag: %w", err)
+		}
+		return fmt.Sprintf("dragged from %s to %s", frame.FormatPoint(*st.X, *st.Y, fromX, fromY), frame.FormatPoint(*st.ToX, *st.ToY, toX, toY)), nil
+
+	case ActionScroll:
+		direction, _ := automation.ParseScrollDirection(st.Direction)
+		opts := automation.ScrollOptions{Unit: automation.ScrollNotches, Smooth: st.Smooth, Duration: r.smoothDuration(st)}
+		if st.Pixels {
+			opts.Unit = automation.ScrollPixels
+		}
+		dx, dy := direction.Delta(st.Amount)
+		if st.X == nil {
+			if err := r.mouse.Scroll(ctx, dx, dy, opts); err != nil {
+				return "", fmt.Errorf("failed to scroll: %w", err)
+			}
+			return fmt.Sprintf("scrolled %s by %d %s", direction, st.Amount, opts.Unit), nil
+		}
+		sx, sy, err := r.mouse.Resolve(frame, *st.X, *st.Y)
+		if err != nil {
+			return "", err
+		}
+		if err := r.mouse.ScrollAt(ctx, sx, sy, dx, dy, opts); err != nil {
+			return "", fmt.Errorf("failed to scroll: %w", err)
+		}
+		return fmt.Sprintf("scrolled %s by %d %s at %s", direction, st.Amount, opts.Unit, frame.FormatPoint(*st.X, *st.Y, sx, sy)), nil
+
+	case ActionType:
+		if st.Paste {
+			if err := r.keyboard.Paste(st.Text, st.RestoreClipboard); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("pasted %d characters", utf8.RuneCountInString(st.Text)), nil
+		}
+		if err := r.typeText(ctx, st); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("typed %d characters", utf8.RuneCountInString(st.Text)), nil
+
+	case ActionHotkey:
+		chords, _ := st.chords()
+		for _, chord := range chords {
+			if err := r.keyboard.PressChord(chord); err != nil {
+				return "", err
+			}
+		}
+		return fmt.Sprintf("pressed %s", st.Keys), nil
+
+	case ActionWait:
+		if st.Color == "" {
+			if r.Timing.NoDelays {
+				return fmt.Sprintf("skipped wait of %s", st.Duration), nil
+			}
+			d := r.Timing.scale(st.Duration, r.random)
+			if err := sleep(ctx, d); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("waited %s", d.Round(time.Millisecond)), nil
+		}
+		color, _ := automation.ParseColor(st.Color)
+		sx, sy, err := r.mouse.Resolve(frame, *st.X, *st.Y)
+		if err != nil {
+			return "", err
+		}
+		cond := automation.ColorCondition{
+			Region:    automation.Rect{X: sx, Y: sy, Width: 1, Height: 1},
+			Color:     color,
+			Tolerance: st.Tolerance,
+			Timeout:   st.Timeout,
+		}
+		got, err := r.screen.WaitForColor(ctx, cond)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("pixel at %s is %s", frame.FormatPoint(*st.X, *st.Y, sx, sy), got), nil
+
+	case ActionScreenshot:
+		format, _ := st.imageFormat()
+		opts := automation.CaptureOptions{Format: format, Quality: automation.DefaultJPEGQuality}
+		if st.Region != "" {
+			region, _ := automation.ParseRect(st.Region)
+			opts.Region = &region
+		}
+		shot, err := r.screen.CaptureEncoded(opts)
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(st.Output, shot.Data, 0o644); err != nil {
+			return "", fmt.Errorf("failed to write screenshot: %w", err)
+		}
+		return fmt.Sprintf("saved %dx%d screenshot of region %s to %s", shot.Width, shot.Height, shot.Region, st.Output), nil
+	}
+
+	return "", fmt.Errorf("unknown action %q", st.Action)
+}
+
+// typeText types the text of a type step, spreading the keystrokes over the
+// step's duration at the replay timing. Without a duration, or without
+// delays, the text is typed at once.
+func (r *Runner) typeText(ctx context.Context, st Step) error {
+	chars := []rune(st.Text)
+	if st.Duration <= 0 || r.Timing.NoDelays || len(chars) < 2 {
+		return r.keyboard.TypeString(st.Text)
+	}
+
+	delay := st.Duration / time.Duration(len(chars)-1)
+	for i, char := range chars {
+		if i > 0 {
+			if err := sleep(ctx, r.Timing.scale(delay, r.random)); err != nil {
+				return err
+			}
+		}
+		if err := r.keyboard.TypeString(string(char)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// smoothDuration returns the length of a smooth move, drag or scroll in
+// seconds at the replay timing
+func (r *Runner) smoothDuration(st Step) float64 {
+	d := st.Duration
+	if d <= 0 {
+		d = DefaultSmoothDuration
+	}
+	return r.Timing.scale(d, r.random).Seconds()
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
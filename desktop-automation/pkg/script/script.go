@@ -0,0 +1,400 @@
+// Package script runs declarative automation flows: a list of named steps
+// such as move, click, type, hotkey, wait and screenshot, written as YAML or
+// JSON
+package script
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+	"gopkg.in/yaml.v3"
+)
+
+// Action is what a step does
+type Action string
+
+// Step actions
+const (
+	ActionMove       Action = "move"
+	ActionClick      Action = "click"
+	ActionDrag       Action = "drag"
+	ActionScroll     Action = "scroll"
+	ActionType       Action = "type"
+	ActionHotkey     Action = "hotkey"
+	ActionWait       Action = "wait"
+	ActionScreenshot Action = "screenshot"
+)
+
+// Script is an automation flow. Its steps run in order and the first failing
+// step stops it.
+type Script struct {
+	// Name describes the flow in reports
+	Name string `yaml:"name,omitempty"`
+	// Vars are default values of variables, used when neither --var nor the
+	// environment sets them
+	Vars map[string]string `yaml:"vars,omitempty"`
+	// Steps are the actions of the flow
+	Steps []Step `yaml:"steps,omitempty"`
+}
+
+// Step is a single action of a script. Which fields apply depends on Action.
+type Step struct {
+	// Name describes the step in reports
+	Name string `yaml:"name,omitempty"`
+	// Action is what the step does
+	Action Action `yaml:"action,omitempty"`
+
+	// X and Y are the target of move, click and drag, the pixel of a color
+	// wait and the optional scroll position
+	X *int `yaml:"x,omitempty"`
+	Y *int `yaml:"y,omitempty"`
+	// ToX and ToY are where a drag ends
+	ToX *int `yaml:"to_x,omitempty"`
+	ToY *int `yaml:"to_y,omitempty"`
+	// Frame, WindowTitle, WindowPID and Display choose what the coordinates
+	// are relative to, see automation.ParseFrame
+	Frame       string `yaml:"frame,omitempty"`
+	WindowTitle string `yaml:"window_title,omitempty"`
+	WindowPID   int    `yaml:"window_pid,omitempty"`
+	Display     *int   `yaml:"display,omitempty"`
+
+	// Button is the mouse button of click and drag; left by default
+	Button string `yaml:"button,omitempty"`
+	// Double makes a click a double click
+	Double bool `yaml:"double,omitempty"`
+	// Smooth spreads a move, drag or scroll over Duration
+	Smooth bool `yaml:"smooth,omitempty"`
+	// Duration is the length of a smooth move, drag or scroll, of a plain
+	// wait, or of typing the text of a type step, whose keystrokes are then
+	// spread evenly over it; written like 500ms or 2s
+	Duration time.Duration `yaml:"duration,omitempty"`
+
+	// Direction and Amount are the scroll direction and distance
+	Direction string `yaml:"direction,omitempty"`
+	Amount    int    `yaml:"amount,omitempty"`
+	// Pixels measures the scroll amount in pixels instead of notches
+	Pixels bool `yaml:"pixels,omitempty"`
+
+	// Text is what a type step enters
+	Text string `yaml:"text,omitempty"`
+	// Paste enters the text through the clipboard instead of typing it
+	Paste bool `yaml:"paste,omitempty"`
+	// RestoreClipboard puts the previous clipboard contents back after pasting
+	RestoreClipboard bool `yaml:"restore_clipboard,omitempty"`
+
+	// Keys are the chords of a hotkey step separated by spaces, like
+	// "ctrl+a ctrl+c"
+	Keys string `yaml:"keys,omitempty"`
+
+	// Color makes a wait poll the pixel at (X, Y) until it has this color
+	// instead of sleeping for Duration
+	Color string `yaml:"color,omitempty"`
+	// Tolerance is the largest difference per RGB channel that still matches
+	Tolerance int `yaml:"tolerance,omitempty"`
+	// Timeout bounds a color wait; automation.DefaultWaitTimeout if zero
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+
+	// Output is the file a screenshot is saved to (.png, .jpg or .jpeg)
+	Output string `yaml:"output,omitempty"`
+	// Region limits a screenshot to x,y,w,h
+	Region string `yaml:"region,omitempty"`
+
+	// Pause is how long to wait after the step before running the next one
+	Pause time.Duration `yaml:"pause,omitempty"`
+}
+
+// Load reads a script from a YAML or JSON file
+func Load(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+
+	s, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid script %s: %w", path, err)
+	}
+	if s.Name == "" {
+		s.Name = filepath.Base(path)
+	}
+
+	return s, nil
+}
+
+// Parse decodes a script written as YAML or JSON. Unknown fields are
+// rejected so that typos do not silently skip a setting.
+func Parse(data []byte) (*Script, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var s Script
+	if err := decoder.Decode(&s); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("script is empty")
+		}
+		return nil, err
+	}
+	if len(s.Steps) == 0 {
+		return nil, fmt.Errorf("script has no steps")
+	}
+
+	return &s, nil
+}
+
+// Marshal encodes the script as YAML in the format Parse reads
+func (s *Script) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(s); err != nil {
+		return nil, fmt.Errorf("failed to encode script: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode script: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Label names the step for reports, like `step 2 "Log in" (click)`. The
+// index is 0-based and shown 1-based.
+func (st Step) Label(index int) string {
+	if st.Name == "" {
+		return fmt.Sprintf("step %d (%s)", index+1, st.Action)
+	}
+	return fmt.Sprintf("step %d %q (%s)", index+1, st.Name, st.Action)
+}
+
+// StepError reports which step of a script failed and why
+type StepError struct {
+	// Index is the 0-based position of the step
+	Index int
+	// Step is the failing step
+	Step Step
+	// Err is the reason
+	Err error
+}
+
+func (e *StepError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Step.Label(e.Index), e.Err)
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// variablePattern matches a ${NAME} reference
+var variablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Lookup returns the value of a variable and whether it is set
+type Lookup func(name string) (string, bool)
+
+// Variables looks variables up in overrides first, then in the environment
+// and finally in the defaults of the script
+func (s *Script) Variables(overrides map[string]string) Lookup {
+	return func(name string) (string, bool) {
+		if v, ok := overrides[name]; ok {
+			return v, true
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			return v, true
+		}
+		v, ok := s.Vars[name]
+		return v, ok
+	}
+}
+
+// Expand returns a copy of the script with every ${NAME} in the text fields
+// of its steps replaced by the value from lookup. Numbers and flags cannot
+// hold variables. Referencing an unset variable is an error.
+func (s *Script) Expand(lookup Lookup) (*Script, error) {
+	expanded := &Script{Name: s.Name, Vars: s.Vars, Steps: make([]Step, len(s.Steps))}
+	for i, st := range s.Steps {
+		for _, field := range []*string{
+			&st.Name, &st.WindowTitle, &st.Button, &st.Direction,
+			&st.Text, &st.Keys, &st.Color, &st.Output, &st.Region,
+		} {
+			value, err := expand(*field, lookup)
+			if err != nil {
+				return nil, &StepError{Index: i, Step: s.Steps[i], Err: err}
+			}
+			*field = value
+		}
+		expanded.Steps[i] = st
+	}
+
+	return expanded, nil
+}
+
+// expand replaces the variable references in text
+func expand(text string, lookup Lookup) (string, error) {
+	var missing []string
+	result := variablePattern.ReplaceAllStringFunc(text, func(ref string) string {
+		name := variablePattern.FindStringSubmatch(ref)[1]
+		value, ok := lookup(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined variable %s (set it with --var %s=... or in the environment)", strings.Join(missing, ", "), missing[0])
+	}
+
+	return result, nil
+}
+
+// Validate checks every step before anything runs, so that a typo in the
+// last step does not leave a flow half done
+func (s *Script) Validate() error {
+	for i, st := range s.Steps {
+		if err := st.validate(); err != nil {
+			return &StepError{Index: i, Step: st, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// validate checks that the step has the fields its action needs
+func (st Step) validate() error {
+	if _, err := st.frame(); err != nil {
+		return err
+	}
+	if st.Duration < 0 || st.Pause < 0 || st.Timeout < 0 {
+		return fmt.Errorf("durations must be non-negative")
+	}
+
+	switch st.Action {
+	case ActionMove:
+		return requirePoint("x and y", st.X, st.Y)
+	case ActionClick:
+		if _, err := automation.ParseButton(st.Button); err != nil {
+			return err
+		}
+		return requirePoint("x and y", st.X, st.Y)
+	case ActionDrag:
+		if _, err := automation.ParseButton(st.Button); err != nil {
+			return err
+		}
+		if err := requirePoint("x and y", st.X, st.Y); err != nil {
+			return err
+		}
+		return requirePoint("to_x and to_y", st.ToX, st.ToY)
+	case ActionScroll:
+		if _, err := automation.ParseScrollDirection(st.Direction); err != nil {
+			return err
+		}
+		if st.Amount <= 0 {
+			return fmt.Errorf("amount must be positive, got: %d", st.Amount)
+		}
+		if (st.X == nil) != (st.Y == nil) {
+			return fmt.Errorf("x and y must be given together")
+		}
+		if st.X == nil && !st.mustFrame().IsAbsolute() {
+			return fmt.Errorf("a window or display frame requires x and y")
+		}
+	case ActionType:
+		if st.Text == "" {
+			return fmt.Errorf("text is required")
+		}
+		if st.RestoreClipboard && !st.Paste {
+			return fmt.Errorf("restore_clipboard requires paste")
+		}
+	case ActionHotkey:
+		chords, err := st.chords()
+		if err != nil {
+			return err
+		}
+		if len(chords) == 0 {
+			return fmt.Errorf("keys are required")
+		}
+	case ActionWait:
+		if st.Color == "" {
+			if st.Duration <= 0 {
+				return fmt.Errorf("duration or color is required")
+			}
+			return nil
+		}
+		if _, err := automation.ParseColor(st.Color); err != nil {
+			return err
+		}
+		if st.Tolerance < 0 || st.Tolerance > 255 {
+			return fmt.Errorf("invalid tolerance: %d (must be between 0 and 255)", st.Tolerance)
+		}
+		return requirePoint("x and y", st.X, st.Y)
+	case ActionScreenshot:
+		if st.Output == "" {
+			return fmt.Errorf("output is required")
+		}
+		if _, err := st.imageFormat(); err != nil {
+			return err
+		}
+		if st.Region != "" {
+			if _, err := automation.ParseRect(st.Region); err != nil {
+				return err
+			}
+		}
+	case "":
+		return fmt.Errorf("action is required")
+	default:
+		return fmt.Errorf("unknown action %q (must be move, click, drag, scroll, type, hotkey, wait or screenshot)", st.Action)
+	}
+
+	return nil
+}
+
+// requirePoint checks that both coordinates of a point are given
+func requirePoint(names string, x, y *int) error {
+	if x == nil || y == nil {
+		return fmt.Errorf("%s are required", names)
+	}
+	return nil
+}
+
+// frame returns the coordinate frame of the step
+func (st Step) frame() (automation.Frame, error) {
+	display := -1
+	if st.Display != nil {
+		display = *st.Display
+	}
+
+	return automation.ParseFrame(st.Frame, st.WindowTitle, st.WindowPID, display)
+}
+
+// mustFrame returns the coordinate frame of a validated step
+func (st Step) mustFrame() automation.Frame {
+	f, _ := st.frame()
+	return f
+}
+
+// chords parses the keys of a hotkey step
+func (st Step) chords() ([]automation.Chord, error) {
+	var chords []automation.Chord
+	for _, key := range strings.Fields(st.Keys) {
+		chord, err := automation.ParseChord(key)
+		if err != nil {
+			return nil, err
+		}
+		chords = append(chords, chord)
+	}
+
+	return chords, nil
+}
+
+// imageFormat returns the format of the screenshot file
+func (st Step) imageFormat() (automation.ImageFormat, error) {
+	format, err := automation.ParseImageFormat(strings.TrimPrefix(filepath.Ext(st.Output), "."))
+	if err != nil {
+		return "", fmt.Errorf("invalid output file %s: %w", st.Output, err)
+	}
+	return format, nil
+}
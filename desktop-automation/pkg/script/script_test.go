@@ -0,0 +1,219 @@
+package script
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+)
+
+func TestParse(t *testing.T) {
+	yamlScript := `
+name: Log in
+vars:
+  USERNAME: demo
+steps:
+  - name: Focus
+    action: click
+    x: 10
+    y: 20
+  - action: wait
+    duration: 500ms
+`
+	jsonScript := `{"name": "Log in", "vars": {"USERNAME": "demo"}, "steps": [
+		{"name": "Focus", "action": "click", "x": 10, "y": 20},
+		{"action": "wait", "duration": "500ms"}
+	]}`
+
+	for name, data := range map[string]string{"yaml": yamlScript, "json": jsonScript} {
+		t.Run(name, func(t *testing.T) {
+			s, err := Parse([]byte(data))
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+
+			x, y := 10, 20
+			want := &Script{
+				Name: "Log in",
+				Vars: map[string]string{"USERNAME": "demo"},
+				Steps: []Step{
+					{Name: "Focus", Action: ActionClick, X: &x, Y: &y},
+					{Action: ActionWait, Duration: 500 * time.Millisecond},
+				},
+			}
+			if !reflect.DeepEqual(s, want) {
+				t.Errorf("Parse() = %+v, want %+v", s, want)
+			}
+		})
+	}
+
+	for name, data := range map[string]string{
+		"empty":         "",
+		"no steps":      "name: nothing\n",
+		"unknown field": "steps:\n  - action: click\n    x: 1\n    y: 2\n    buton: right\n",
+		"bad duration":  "steps:\n  - action: wait\n    duration: soon\n",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%s) succeeded, want error", name)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	s := &Script{
+		Vars: map[string]string{"USERNAME": "demo", "DIR": "/tmp"},
+		Steps: []Step{
+			{Name: "Type ${USERNAME}", Action: ActionType, Text: "${USERNAME}:${PASSWORD}"},
+			{Action: ActionScreenshot, Output: "${DIR}/shot.png"},
+		},
+	}
+
+	expanded, err := s.Expand(s.Variables(map[string]string{"PASSWORD": "secret", "DIR": "/out"}))
+	if err != nil {
+		t.Fatalf("Expand() failed: %v", err)
+	}
+	if got, want := expanded.Steps[0].Name, "Type demo"; got != want {
+		t.Errorf("name = %q, want %q", got, want)
+	}
+	if got, want := expanded.Steps[0].Text, "demo:secret"; got != want {
+		t.Errorf("text = %q, want %q", got, want)
+	}
+	if got, want := expanded.Steps[1].Output, "/out/shot.png"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if got, want := s.Steps[0].Text, "${USERNAME}:${PASSWORD}"; got != want {
+		t.Errorf("original text = %q, want %q", got, want)
+	}
+
+	_, err = s.Expand(s.Variables(nil))
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Index != 0 || !strings.Contains(err.Error(), "PASSWORD") {
+		t.Errorf("Expand() without PASSWORD error = %v, want undefined PASSWORD in step 1", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	one, two := 1, 2
+
+	tests := []struct {
+		name string
+		step Step
+		ok   bool
+	}{
+		{name: "move", step: Step{Action: ActionMove, X: &one, Y: &two}, ok: true},
+		{name: "move without y", step: Step{Action: ActionMove, X: &one}},
+		{name: "click with bad button", step: Step{Action: ActionClick, X: &one, Y: &two, Button: "fourth"}},
+		{name: "drag without end", step: Step{Action: ActionDrag, X: &one, Y: &two}},
+		{name: "scroll", step: Step{Action: ActionScroll, Direction: "down", Amount: 3}, ok: true},
+		{name: "scroll in window without position", step: Step{Action: ActionScroll, Direction: "down", Amount: 3, WindowTitle: "Editor"}},
+		{name: "type without text", step: Step{Action: ActionType}},
+		{name: "restore without paste", step: Step{Action: ActionType, Text: "hi", RestoreClipboard: true}},
+		{name: "hotkey", step: Step{Action: ActionHotkey, Keys: "ctrl+a ctrl+c"}, ok: true},
+		{name: "unknown key", step: Step{Action: ActionHotkey, Keys: "ctrl+nope"}},
+		{name: "wait without duration", step: Step{Action: ActionWait}},
+		{name: "color wait", step: Step{Action: ActionWait, Color: "#ffffff", X: &one, Y: &two}, ok: true},
+		{name: "bad color", step: Step{Action: ActionWait, Color: "white", X: &one, Y: &two}},
+		{name: "screenshot as gif", step: Step{Action: ActionScreenshot, Output: "shot.gif"}},
+		{name: "no action", step: Step{Name: "nothing"}},
+		{name: "unknown action", step: Step{Action: "dance"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Script{Steps: []Step{{Action: ActionWait, Duration: time.Millisecond}, tt.step}}
+			err := s.Validate()
+			if (err == nil) != tt.ok {
+				t.Fatalf("Validate() error = %v, want ok %v", err, tt.ok)
+			}
+
+			var stepErr *StepError
+			if err != nil && (!errors.As(err, &stepErr) || stepErr.Index != 1) {
+				t.Errorf("Validate() error = %v, want a *StepError for step 2", err)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	x, y, toX, toY := 10, 20, 30, 40
+	s := &Script{
+		Name: "flow",
+		Steps: []Step{
+			{Name: "Focus", Action: ActionClick, X: &x, Y: &y},
+			{Action: ActionType, Text: "héllo"},
+			{Action: ActionHotkey, Keys: "ctrl+a"},
+			{Action: ActionWait, Duration: time.Millisecond},
+			{Action: ActionDrag, X: &x, Y: &y, ToX: &toX, ToY: &toY},
+		},
+	}
+
+	backend := automation.NewFakeBackend()
+	runner := NewRunnerWithBackend(backend)
+	var progress []int
+	runner.Progress = func(result StepResult) { progress = append(progress, result.Index) }
+
+	report, err := runner.Run(context.Background(), s)
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if report.Failed() != nil || len(report.Results) != 5 || report.Total != 5 {
+		t.Errorf("report = %+v, want 5 passed steps", report)
+	}
+	if !reflect.DeepEqual(progress, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("progress = %v, want every step", progress)
+	}
+	if got, want := report.Results[1].Detail, "typed 5 characters"; got != want {
+		t.Errorf("type detail = %q, want %q", got, want)
+	}
+	if got, want := report.Results[3].Elapsed, time.Millisecond; got < want {
+		t.Errorf("wait took %s, want at least %s", got, want)
+	}
+
+	want := []automation.Event{
+		{Kind: automation.EventMove, X: 10, Y: 20},
+		{Kind: automation.EventClick, X: 10, Y: 20, Button: automation.ButtonLeft, Count: 1},
+		{Kind: automation.EventType, Text: "héllo"},
+		{Kind: automation.EventKeyTap, Key: "a", Modifiers: []string{"ctrl"}},
+		{Kind: automation.EventMove, X: 10, Y: 20},
+		{Kind: automation.EventMouseDown, X: 10, Y: 20, Button: automation.ButtonLeft},
+		{Kind: automation.EventMove, X: 30, Y: 40},
+		{Kind: automation.EventMouseUp, X: 30, Y: 40, Button: automation.ButtonLeft},
+	}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
+
+func TestRunStopsAtFailure(t *testing.T) {
+	x, y := 10, 20
+	s := &Script{
+		Steps: []Step{
+			{Action: ActionType, Text: "before"},
+			{Name: "Click the editor", Action: ActionClick, X: &x, Y: &y, WindowTitle: "Editor"},
+			{Action: ActionType, Text: "after"},
+		},
+	}
+
+	backend := automation.NewFakeBackend()
+	report, err := NewRunnerWithBackend(backend).Run(context.Background(), s)
+
+	var stepErr *StepError
+	if !errors.As(err, &stepErr) || stepErr.Index != 1 {
+		t.Fatalf("Run() error = %v, want a *StepError for step 2", err)
+	}
+	if !strings.HasPrefix(err.Error(), `step 2 "Click the editor" (click): `) {
+		t.Errorf("Run() error = %q, want it to name the step", err)
+	}
+	if failed := report.Failed(); failed == nil || failed.Index != 1 || len(report.Results) != 2 {
+		t.Errorf("report = %+v, want step 2 to fail after step 1 passed", report)
+	}
+
+	want := []automation.Event{{Kind: automation.EventType, Text: "before"}}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
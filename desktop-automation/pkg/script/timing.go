@@ -0,0 +1,72 @@
+// Package script runs declarative automation flows: a list of named steps
+// such as move, click, type, hotkey, wait and screenshot, written as YAML or
+// JSON
+package script
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// MaxJitter bounds Timing.Jitter, so that a jittered delay never collapses
+// to nothing
+const MaxJitter = 0.9
+
+// Timing controls how fast a Runner replays a script. The zero Timing keeps
+// the pace written in the script.
+type Timing struct {
+	// Speed divides every delay: 2 replays twice as fast, 0.5 at half speed.
+	// Zero means 1.
+	Speed float64
+	// NoDelays skips pauses, fixed waits and the delays between typed keys
+	// altogether. Smooth moves, drags and scrolls keep their scaled
+	// duration, and color waits still wait for the screen.
+	NoDelays bool
+	// Jitter varies every delay and smooth motion randomly by up to this
+	// fraction, like 0.2 for ±20%, so that the replay looks less mechanical
+	Jitter float64
+}
+
+// Validate checks the timing settings
+func (t Timing) Validate() error {
+	if t.Speed < 0 || math.IsNaN(t.Speed) || math.IsInf(t.Speed, 0) {
+		return fmt.Errorf("invalid speed: %g (must be positive)", t.Speed)
+	}
+	if !(t.Jitter >= 0 && t.Jitter <= MaxJitter) {
+		return fmt.Errorf("invalid jitter: %g (must be between 0 and %g)", t.Jitter, MaxJitter)
+	}
+	return nil
+}
+
+// String describes the timing in reports
+func (t Timing) String() string {
+	s := "original speed"
+	if t.Speed != 0 && t.Speed != 1 {
+		s = fmt.Sprintf("%g× speed", t.Speed)
+	}
+	if t.NoDelays {
+		s += ", no delays"
+	}
+	if t.Jitter > 0 {
+		s += fmt.Sprintf(", ±%g%% jitter", t.Jitter*100)
+	}
+	return s
+}
+
+// scale returns the length of a delay of d at this timing. random returns
+// numbers in [0, 1) and drives the jitter.
+func (t Timing) scale(d time.Duration, random func() float64) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+
+	scaled := float64(d)
+	if t.Speed > 0 {
+		scaled /= t.Speed
+	}
+	if t.Jitter > 0 {
+		scaled *= 1 + t.Jitter*(2*random()-1)
+	}
+	return time.Duration(scaled)
+}
@@ -0,0 +1,106 @@
+package script
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pgbytes/gophercon25/desktop-automation/pkg/automation"
+)
+
+func TestTimingScale(t *testing.T) {
+	tests := []struct {
+		name   string
+		timing Timing
+		random float64
+		want   time.Duration
+	}{
+		{name: "original", timing: Timing{}, want: time.Second},
+		{name: "twice as fast", timing: Timing{Speed: 2}, want: 500 * time.Millisecond},
+		{name: "half speed", timing: Timing{Speed: 0.5}, want: 2 * time.Second},
+		{name: "jitter low", timing: Timing{Jitter: 0.2}, random: 0, want: 800 * time.Millisecond},
+		{name: "jitter high", timing: Timing{Speed: 2, Jitter: 0.2}, random: 1, want: 600 * time.Millisecond},
+		{name: "jitter middle", timing: Timing{Jitter: 0.5}, random: 0.5, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.timing.scale(time.Second, func() float64 { return tt.random })
+			if got != tt.want {
+				t.Errorf("scale(1s) = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	for _, timing := range []Timing{{Speed: -1}, {Speed: math.NaN()}, {Speed: math.Inf(1)}, {Jitter: -0.1}, {Jitter: 1}, {Jitter: math.NaN()}} {
+		if err := timing.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded, want error", timing)
+		}
+	}
+}
+
+func TestRunTiming(t *testing.T) {
+	x, y := 10, 20
+	s := &Script{
+		Steps: []Step{
+			{Action: ActionMove, X: &x, Y: &y, Smooth: true, Duration: time.Second, Pause: time.Hour},
+			{Action: ActionWait, Duration: time.Hour},
+			{Action: ActionType, Text: "done"},
+		},
+	}
+
+	backend := automation.NewFakeBackend()
+	runner := NewRunnerWithBackend(backend)
+	runner.Timing = Timing{Speed: 4, NoDelays: true}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	report, err := runner.Run(ctx, s)
+	if err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	if got, want := report.Results[1].Detail, "skipped wait of 1h0m0s"; got != want {
+		t.Errorf("wait detail = %q, want %q", got, want)
+	}
+
+	want := []automation.Event{
+		{Kind: automation.EventMoveSmooth, X: 10, Y: 20, Duration: 0.25},
+		{Kind: automation.EventType, Text: "done"},
+	}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+
+	runner.Timing = Timing{Jitter: 2}
+	if _, err := runner.Run(ctx, s); err == nil {
+		t.Error("Run() with invalid timing succeeded, want error")
+	}
+}
+
+func TestRunTypingPace(t *testing.T) {
+	s := &Script{Steps: []Step{{Action: ActionType, Text: "abc", Duration: 200 * time.Millisecond}}}
+
+	backend := automation.NewFakeBackend()
+	runner := NewRunnerWithBackend(backend)
+	runner.Timing = Timing{Speed: 2}
+
+	start := time.Now()
+	if _, err := runner.Run(context.Background(), s); err != nil {
+		t.Fatalf("Run() failed: %v", err)
+	}
+	// Two gaps of 100ms at twice the speed
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("typing took %s, want about 100ms", elapsed)
+	}
+
+	want := []automation.Event{
+		{Kind: automation.EventType, Text: "a"},
+		{Kind: automation.EventType, Text: "b"},
+		{Kind: automation.EventType, Text: "c"},
+	}
+	if got := backend.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("events = %+v, want %+v", got, want)
+	}
+}
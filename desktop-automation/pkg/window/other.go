@@ -0,0 +1,35 @@
+//go:build !linux
+
+// Package window lists the top-level windows of the desktop, arranges them
+// and moves the input focus between them
+package window
+
+// List returns ErrUnsupported
+func List() ([]Window, error) {
+	return nil, ErrUnsupported
+}
+
+// Active returns ErrUnsupported
+func Active() (Window, error) {
+	return Window{}, ErrUnsupported
+}
+
+// Activate returns ErrUnsupported
+func Activate(id uint64) error {
+	return ErrUnsupported
+}
+
+// SetBounds returns ErrUnsupported
+func SetBounds(id uint64, x, y, width, height int) error {
+	return ErrUnsupported
+}
+
+// SetState returns ErrUnsupported
+func SetState(id uint64, state State) error {
+	return ErrUnsupported
+}
+
+// Close returns ErrUnsupported
+func Close(id uint64) error {
+	return ErrUnsupported
+}
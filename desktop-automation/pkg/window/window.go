@@ -0,0 +1,149 @@
+// Package window lists the top-level windows of the desktop, arranges them
+// and moves the input focus between them
+package window
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"regexp"
+	"strings"
+)
+
+// ErrUnsupported is returned on platforms without a window manager integration
+var ErrUnsupported = errors.New("window control is not supported on this platform")
+
+// Window is a top-level window. Its bounds are in virtual desktop coordinates.
+type Window struct {
+	// ID is the handle of the window as reported by the system
+	ID uint64 `json:"id"`
+	// Title is the window title
+	Title string `json:"title"`
+	// PID is the ID of the process owning the window, or 0 if unknown
+	PID int `json:"pid"`
+	// Process is the name of the owning process, or empty if unknown
+	Process string `json:"process"`
+	// X and Y are the top left corner of the window
+	X int `json:"x"`
+	Y int `json:"y"`
+	// Width and Height are the size of the window in pixels
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Focused is set for the active window
+	Focused bool `json:"focused"`
+	// State is whether the window is minimized, maximized or neither
+	State State `json:"state"`
+}
+
+// Bounds returns the rectangle covered by the window
+func (w Window) Bounds() image.Rectangle {
+	return image.Rect(w.X, w.Y, w.X+w.Width, w.Y+w.Height)
+}
+
+// String returns the window as "id: "title" (process pid) WxH at (x, y)"
+func (w Window) String() string {
+	s := fmt.Sprintf("%#x: %q", w.ID, w.Title)
+	switch {
+	case w.Process != "":
+		s += fmt.Sprintf(" (%s %d)", w.Process, w.PID)
+	case w.PID != 0:
+		s += fmt.Sprintf(" (pid %d)", w.PID)
+	}
+	s += fmt.Sprintf(" %dx%d at (%d, %d)", w.Width, w.Height, w.X, w.Y)
+	if w.State != "" && w.State != StateNormal {
+		s += " " + string(w.State)
+	}
+	if w.Focused {
+		s += " focused"
+	}
+	return s
+}
+
+// State is the display state of a window
+type State string
+
+// Window states
+const (
+	StateNormal    State = "normal"
+	StateMinimized State = "minimized"
+	StateMaximized State = "maximized"
+)
+
+// ParseState parses a state name. The verbs "restore", "minimize" and
+// "maximize" are accepted as well.
+func ParseState(name string) (State, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "normal", "restore", "restored":
+		return StateNormal, nil
+	case "minimized", "minimize", "iconic":
+		return StateMinimized, nil
+	case "maximized", "maximize":
+		return StateMaximized, nil
+	}
+
+	return "", fmt.Errorf("unknown window state %q (must be normal, minimized or maximized)", name)
+}
+
+// Selector picks windows by title and owning process. The zero Selector
+// matches every window.
+type Selector struct {
+	// Title matches the window title when set
+	Title *regexp.Regexp
+	// PID matches the owning process when non-zero
+	PID int
+}
+
+// NewSelector builds a selector from a title regular expression and a PID,
+// either of which may be left empty
+func NewSelector(title string, pid int) (Selector, error) {
+	if pid < 0 {
+		return Selector{}, fmt.Errorf("invalid pid: %d (must be positive)", pid)
+	}
+
+	sel := Selector{PID: pid}
+	if title != "" {
+		re, err := regexp.Compile(title)
+		if err != nil {
+			return Selector{}, fmt.Errorf("invalid title pattern %q: %w", title, err)
+		}
+		sel.Title = re
+	}
+	return sel, nil
+}
+
+// Matches reports whether w is picked by the selector
+func (s Selector) Matches(w Window) bool {
+	if s.PID != 0 && w.PID != s.PID {
+		return false
+	}
+	if s.Title != nil && !s.Title.MatchString(w.Title) {
+		return false
+	}
+	return true
+}
+
+// String describes the selector, e.g. `title ~ "Editor", pid 42`
+func (s Selector) String() string {
+	var parts []string
+	if s.Title != nil {
+		parts = append(parts, fmt.Sprintf("title ~ %q", s.Title.String()))
+	}
+	if s.PID != 0 {
+		parts = append(parts, fmt.Sprintf("pid %d", s.PID))
+	}
+	if len(parts) == 0 {
+		return "any window"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Filter returns the windows picked by sel, in their original order
+func Filter(windows []Window, sel Selector) []Window {
+	var matched []Window
+	for _, w := range windows {
+		if sel.Matches(w) {
+			matched = append(matched, w)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,73 @@
+package window
+
+import "testing"
+
+func TestSelector(t *testing.T) {
+	windows := []Window{
+		{ID: 1, Title: "Editor - notes.txt", PID: 5},
+		{ID: 2, Title: "Terminal", PID: 6},
+		{ID: 3, Title: "Editor - todo.txt", PID: 6},
+	}
+
+	tests := []struct {
+		name  string
+		title string
+		pid   int
+		want  []uint64
+	}{
+		{name: "any", want: []uint64{1, 2, 3}},
+		{name: "title", title: "^Editor", want: []uint64{1, 3}},
+		{name: "pid", pid: 6, want: []uint64{2, 3}},
+		{name: "title and pid", title: "todo", pid: 6, want: []uint64{3}},
+		{name: "no match", title: "Browser", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sel, err := NewSelector(tt.title, tt.pid)
+			if err != nil {
+				t.Fatalf("NewSelector(%q, %d) failed: %v", tt.title, tt.pid, err)
+			}
+
+			var got []uint64
+			for _, w := range Filter(windows, sel) {
+				got = append(got, w.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Filter(%s) = %v, want %v", sel, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Filter(%s) = %v, want %v", sel, got, tt.want)
+					break
+				}
+			}
+		})
+	}
+
+	if _, err := NewSelector("(", 0); err == nil {
+		t.Error("NewSelector(\"(\", 0) succeeded, want error")
+	}
+	if _, err := NewSelector("", -1); err == nil {
+		t.Error("NewSelector(\"\", -1) succeeded, want error")
+	}
+}
+
+func TestParseState(t *testing.T) {
+	tests := map[string]State{
+		"normal":    StateNormal,
+		"Restore":   StateNormal,
+		"minimize":  StateMinimized,
+		"minimized": StateMinimized,
+		"maximize":  StateMaximized,
+	}
+	for name, want := range tests {
+		if got, err := ParseState(name); err != nil || got != want {
+			t.Errorf("ParseState(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+
+	if _, err := ParseState("fullscreen"); err == nil {
+		t.Error("ParseState(\"fullscreen\") succeeded, want error")
+	}
+}
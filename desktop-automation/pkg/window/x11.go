@@ -0,0 +1,457 @@
+//go:build linux
+
+// Package window lists the top-level windows of the desktop, arranges them
+// and moves the input focus between them
+package window
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// List returns the top-level windows of the X display named by $DISPLAY in
+// stacking order, bottom to top. Windows managed by an EWMH window manager
+// that does not publish the stacking order are listed in the order they were
+// mapped instead; without a window manager the mapped children of the root
+// window, and the unmapped ones with a title, are returned.
+func List() ([]Window, error) {
+	x, err := openX11()
+	if err != nil {
+		return nil, err
+	}
+	defer x.conn.Close()
+
+	ids, err := x.clients()
+	if err != nil {
+		return nil, err
+	}
+	active := x.active()
+
+	windows := make([]Window, 0, len(ids))
+	for _, id := range ids {
+		w, err := x.describe(id)
+		if err != nil {
+			// The window may have been destroyed while listing
+			continue
+		}
+		w.Focused = id == active
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// Active returns the window holding the input focus
+func Active() (Window, error) {
+	x, err := openX11()
+	if err != nil {
+		return Window{}, err
+	}
+	defer x.conn.Close()
+
+	active := x.active()
+	if active == xproto.WindowNone {
+		return Window{}, fmt.Errorf("no window has the input focus")
+	}
+
+	w, err := x.describe(active)
+	if err != nil {
+		return Window{}, err
+	}
+	w.Focused = true
+	return w, nil
+}
+
+// Activate raises the window with the given ID and gives it the input focus.
+// With an EWMH window manager the request goes through _NET_ACTIVE_WINDOW so
+// that the manager can switch desktops and unminimize as needed.
+func Activate(id uint64) error {
+	x, win, err := openWindow(id)
+	if err != nil {
+		return err
+	}
+	defer x.conn.Close()
+
+	if x.hasWindowManager() {
+		if err := x.sendRootMessage(win, "_NET_ACTIVE_WINDOW", 2, xproto.TimeCurrentTime); err != nil {
+			return fmt.Errorf("failed to activate window %#x: %w", id, err)
+		}
+		return nil
+	}
+
+	if err := xproto.MapWindowChecked(x.conn, win).Check(); err != nil {
+		return fmt.Errorf("failed to map window %#x: %w", id, err)
+	}
+	if err := xproto.ConfigureWindowChecked(x.conn, win, xproto.ConfigWindowStackMode, []uint32{xproto.StackModeAbove}).Check(); err != nil {
+		return fmt.Errorf("failed to raise window %#x: %w", id, err)
+	}
+	if err := xproto.SetInputFocusChecked(x.conn, xproto.InputFocusParent, win, xproto.TimeCurrentTime).Check(); err != nil {
+		return fmt.Errorf("failed to focus window %#x: %w", id, err)
+	}
+	return nil
+}
+
+// SetBounds moves the window with the given ID so that its top left corner
+// is at (x, y) and resizes it to width by height
+func SetBounds(id uint64, x, y, width, height int) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("invalid window size: %dx%d (must be positive)", width, height)
+	}
+
+	xc, win, err := openWindow(id)
+	if err != nil {
+		return err
+	}
+	defer xc.conn.Close()
+
+	if xc.hasWindowManager() {
+		// Let the manager account for its frame. The flags mark x, y, width
+		// and height as set, with static gravity and a pager as the source.
+		flags := uint32(xproto.GravityStatic) | 0xf<<8 | 2<<12
+		if err := xc.sendRootMessage(win, "_NET_MOVERESIZE_WINDOW", flags, uint32(int32(x)), uint32(int32(y)), uint32(width), uint32(height)); err != nil {
+			return fmt.Errorf("failed to move window %#x: %w", id, err)
+		}
+		return nil
+	}
+
+	mask := uint16(xproto.ConfigWindowX | xproto.ConfigWindowY | xproto.ConfigWindowWidth | xproto.ConfigWindowHeight)
+	if err := xproto.ConfigureWindowChecked(xc.conn, win, mask, []uint32{uint32(int32(x)), uint32(int32(y)), uint32(width), uint32(height)}).Check(); err != nil {
+		return fmt.Errorf("failed to move window %#x: %w", id, err)
+	}
+	return nil
+}
+
+// SetState minimizes, maximizes or restores the window with the given ID.
+// Without a window manager minimizing unmaps the window, maximizing makes it
+// cover the screen and restoring maps it again.
+func SetState(id uint64, state State) error {
+	x, win, err := openWindow(id)
+	if err != nil {
+		return err
+	}
+	defer x.conn.Close()
+
+	if x.hasWindowManager() {
+		err = x.requestState(win, state)
+	} else {
+		err = x.applyState(win, state)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set window %#x %s: %w", id, state, err)
+	}
+	return nil
+}
+
+// Close asks the window with the given ID to close, giving its application
+// the chance to save or confirm first. Windows that do not support
+// WM_DELETE_WINDOW cannot be closed gracefully without a window manager.
+func Close(id uint64) error {
+	x, win, err := openWindow(id)
+	if err != nil {
+		return err
+	}
+	defer x.conn.Close()
+
+	if x.hasWindowManager() {
+		if err := x.sendRootMessage(win, "_NET_CLOSE_WINDOW", xproto.TimeCurrentTime, 2); err != nil {
+			return fmt.Errorf("failed to close window %#x: %w", id, err)
+		}
+		return nil
+	}
+
+	protocols := x.atom("WM_PROTOCOLS")
+	deleteWindow := x.atom("WM_DELETE_WINDOW")
+	supported := false
+	if reply := x.property(win, "WM_PROTOCOLS"); reply != nil && reply.Format == 32 {
+		for i := 0; i < int(reply.ValueLen); i++ {
+			if xproto.Atom(xgb.Get32(reply.Value[i*4:])) == deleteWindow {
+				supported = true
+			}
+		}
+	}
+	if !supported || deleteWindow == xproto.AtomNone {
+		return fmt.Errorf("window %#x does not support being closed gracefully (WM_DELETE_WINDOW)", id)
+	}
+
+	ev := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: win,
+		Type:   protocols,
+		Data:   xproto.ClientMessageDataUnionData32New([]uint32{uint32(deleteWindow), xproto.TimeCurrentTime, 0, 0, 0}),
+	}
+	if err := xproto.SendEventChecked(x.conn, false, win, xproto.EventMaskNoEvent, string(ev.Bytes())).Check(); err != nil {
+		return fmt.Errorf("failed to close window %#x: %w", id, err)
+	}
+	return nil
+}
+
+// x11 is an open connection to the X server
+type x11 struct {
+	conn *xgb.Conn
+	root xproto.Window
+}
+
+// openX11 connects to the display named by $DISPLAY
+func openX11() (*x11, error) {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the X server: %w", err)
+	}
+
+	root := xproto.Setup(conn).DefaultScreen(conn).Root
+	return &x11{conn: conn, root: root}, nil
+}
+
+// openWindow connects to the X server and checks that the window with the
+// given ID exists
+func openWindow(id uint64) (*x11, xproto.Window, error) {
+	win := xproto.Window(id)
+	if uint64(win) != id {
+		return nil, 0, fmt.Errorf("invalid window id: %#x", id)
+	}
+
+	x, err := openX11()
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := xproto.GetGeometry(x.conn, xproto.Drawable(win)).Reply(); err != nil {
+		x.conn.Close()
+		return nil, 0, fmt.Errorf("window %#x not found: %w", id, err)
+	}
+	return x, win, nil
+}
+
+// sendRootMessage sends the EWMH client message called name about win to the
+// window manager
+func (x *x11) sendRootMessage(win xproto.Window, name string, data ...uint32) error {
+	data = append(data, make([]uint32, 5-len(data))...)
+	ev := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: win,
+		Type:   x.atom(name),
+		Data:   xproto.ClientMessageDataUnionData32New(data),
+	}
+	mask := uint32(xproto.EventMaskSubstructureRedirect | xproto.EventMaskSubstructureNotify)
+	return xproto.SendEventChecked(x.conn, false, x.root, mask, string(ev.Bytes())).Check()
+}
+
+// requestState asks the window manager to put win in state
+func (x *x11) requestState(win xproto.Window, state State) error {
+	const (
+		stateRemove = 0
+		stateAdd    = 1
+		iconicState = 3
+	)
+	vert := uint32(x.atom("_NET_WM_STATE_MAXIMIZED_VERT"))
+	horz := uint32(x.atom("_NET_WM_STATE_MAXIMIZED_HORZ"))
+
+	switch state {
+	case StateMinimized:
+		return x.sendRootMessage(win, "WM_CHANGE_STATE", iconicState)
+	case StateMaximized:
+		return x.sendRootMessage(win, "_NET_WM_STATE", stateAdd, vert, horz, 2)
+	case StateNormal:
+		if err := x.sendRootMessage(win, "_NET_WM_STATE", stateRemove, vert, horz, 2); err != nil {
+			return err
+		}
+		// Activating a minimized window deiconifies it
+		return x.sendRootMessage(win, "_NET_ACTIVE_WINDOW", 2, xproto.TimeCurrentTime)
+	}
+	return fmt.Errorf("unknown window state %q", state)
+}
+
+// applyState puts win in state directly, for displays without a window manager
+func (x *x11) applyState(win xproto.Window, state State) error {
+	switch state {
+	case StateMinimized:
+		return xproto.UnmapWindowChecked(x.conn, win).Check()
+	case StateMaximized:
+		screen := xproto.Setup(x.conn).DefaultScreen(x.conn)
+		mask := uint16(xproto.ConfigWindowX | xproto.ConfigWindowY | xproto.ConfigWindowWidth | xproto.ConfigWindowHeight)
+		if err := xproto.ConfigureWindowChecked(x.conn, win, mask, []uint32{0, 0, uint32(screen.WidthInPixels), uint32(screen.HeightInPixels)}).Check(); err != nil {
+			return err
+		}
+		return xproto.MapWindowChecked(x.conn, win).Check()
+	case StateNormal:
+		return xproto.MapWindowChecked(x.conn, win).Check()
+	}
+	return fmt.Errorf("unknown window state %q", state)
+}
+
+// state reads whether win is minimized or maximized
+func (x *x11) state(win xproto.Window) State {
+	if reply := x.property(win, "_NET_WM_STATE"); reply != nil && reply.Format == 32 {
+		hidden := x.atom("_NET_WM_STATE_HIDDEN")
+		vert := x.atom("_NET_WM_STATE_MAXIMIZED_VERT")
+		horz := x.atom("_NET_WM_STATE_MAXIMIZED_HORZ")
+
+		var maxVert, maxHorz bool
+		for i := 0; i < int(reply.ValueLen); i++ {
+			switch xproto.Atom(xgb.Get32(reply.Value[i*4:])) {
+			case hidden:
+				return StateMinimized
+			case vert:
+				maxVert = true
+			case horz:
+				maxHorz = true
+			}
+		}
+		if maxVert && maxHorz {
+			return StateMaximized
+		}
+	}
+
+	attrs, err := xproto.GetWindowAttributes(x.conn, win).Reply()
+	if err == nil && attrs.MapState != xproto.MapStateViewable {
+		return StateMinimized
+	}
+	return StateNormal
+}
+
+// atom returns the atom called name, or AtomNone if it does not exist
+func (x *x11) atom(name string) xproto.Atom {
+	reply, err := xproto.InternAtom(x.conn, true, uint16(len(name)), name).Reply()
+	if err != nil {
+		return xproto.AtomNone
+	}
+	return reply.Atom
+}
+
+// property returns the raw value of the property called name on win, or nil
+// if it is not set
+func (x *x11) property(win xproto.Window, name string) *xproto.GetPropertyReply {
+	atom := x.atom(name)
+	if atom == xproto.AtomNone {
+		return nil
+	}
+
+	reply, err := xproto.GetProperty(x.conn, false, win, atom, xproto.GetPropertyTypeAny, 0, 1<<16).Reply()
+	if err != nil || reply.Format == 0 {
+		return nil
+	}
+	return reply
+}
+
+// windows decodes a property holding a list of windows
+func (x *x11) windows(win xproto.Window, name string) []xproto.Window {
+	reply := x.property(win, name)
+	if reply == nil || reply.Format != 32 {
+		return nil
+	}
+
+	ids := make([]xproto.Window, reply.ValueLen)
+	for i := range ids {
+		ids[i] = xproto.Window(xgb.Get32(reply.Value[i*4:]))
+	}
+	return ids
+}
+
+// hasWindowManager reports whether an EWMH compliant window manager is running
+func (x *x11) hasWindowManager() bool {
+	return len(x.windows(x.root, "_NET_SUPPORTING_WM_CHECK")) > 0
+}
+
+// clients returns the top-level windows, preferring the window manager's list
+func (x *x11) clients() ([]xproto.Window, error) {
+	if ids := x.windows(x.root, "_NET_CLIENT_LIST_STACKING"); len(ids) > 0 {
+		return ids, nil
+	}
+	if ids := x.windows(x.root, "_NET_CLIENT_LIST"); len(ids) > 0 {
+		return ids, nil
+	}
+
+	tree, err := xproto.QueryTree(x.conn, x.root).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list windows: %w", err)
+	}
+
+	var ids []xproto.Window
+	for _, child := range tree.Children {
+		attrs, err := xproto.GetWindowAttributes(x.conn, child).Reply()
+		if err != nil || attrs.OverrideRedirect {
+			continue
+		}
+		// Keep titled windows that were unmapped, which is how they are
+		// minimized without a window manager
+		if attrs.MapState != xproto.MapStateViewable && x.title(child) == "" {
+			continue
+		}
+		ids = append(ids, child)
+	}
+	return ids, nil
+}
+
+// active returns the top-level window holding the input focus, or WindowNone
+func (x *x11) active() xproto.Window {
+	if ids := x.windows(x.root, "_NET_ACTIVE_WINDOW"); len(ids) > 0 && ids[0] != xproto.WindowNone {
+		return ids[0]
+	}
+
+	focus, err := xproto.GetInputFocus(x.conn).Reply()
+	if err != nil || focus.Focus == xproto.WindowNone || focus.Focus == xproto.InputFocusPointerRoot || focus.Focus == x.root {
+		return xproto.WindowNone
+	}
+	return x.topLevel(focus.Focus)
+}
+
+// topLevel returns the ancestor of win that is a direct child of the root
+func (x *x11) topLevel(win xproto.Window) xproto.Window {
+	for {
+		tree, err := xproto.QueryTree(x.conn, win).Reply()
+		if err != nil || tree.Parent == x.root || tree.Parent == xproto.WindowNone {
+			return win
+		}
+		win = tree.Parent
+	}
+}
+
+// describe reads the title, owner and bounds of win
+func (x *x11) describe(win xproto.Window) (Window, error) {
+	geom, err := xproto.GetGeometry(x.conn, xproto.Drawable(win)).Reply()
+	if err != nil {
+		return Window{}, fmt.Errorf("failed to read geometry of window %#x: %w", uint32(win), err)
+	}
+	origin, err := xproto.TranslateCoordinates(x.conn, win, x.root, 0, 0).Reply()
+	if err != nil {
+		return Window{}, fmt.Errorf("failed to locate window %#x: %w", uint32(win), err)
+	}
+
+	w := Window{
+		ID:     uint64(win),
+		Title:  x.title(win),
+		X:      int(origin.DstX),
+		Y:      int(origin.DstY),
+		Width:  int(geom.Width),
+		Height: int(geom.Height),
+		State:  x.state(win),
+	}
+	if reply := x.property(win, "_NET_WM_PID"); reply != nil && reply.Format == 32 && reply.ValueLen > 0 {
+		w.PID = int(xgb.Get32(reply.Value))
+		w.Process = processName(w.PID)
+	}
+	return w, nil
+}
+
+// title returns the UTF-8 title of win, falling back to the legacy WM_NAME
+func (x *x11) title(win xproto.Window) string {
+	for _, name := range []string{"_NET_WM_NAME", "WM_NAME"} {
+		if reply := x.property(win, name); reply != nil && reply.Format == 8 && len(reply.Value) > 0 {
+			return string(reply.Value)
+		}
+	}
+	return ""
+}
+
+// processName returns the command name of the process pid, or empty if it
+// cannot be read
+func processName(pid int) string {
+	comm, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/comm")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(comm))
+}